@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ============ BUY IT NOW ============
+// An auction with a BuyNowPrice can be bought outright for that price
+// while the bidding is below it. Buying is recorded as the auction's last
+// bid, marked BuyNow, so it is saved, replayed and shown in the history
+// like any other; applying it ends the auction on the spot.
+
+// buyNow ends the auction in the bidder's favour at its buy-it-now price.
+// The reply is buy_now_confirmed or bid_rejected; everyone else gets
+// bid_accepted followed by auction_bought.
+func buyNow(requestID, auctionID, bidderID string) Message {
+	auctionMutex.Lock()
+	defer auctionMutex.Unlock()
+
+	auction, ok := listedAuction(auctionID)
+
+	reject := func(reason, format string, args ...interface{}) Message {
+		bidsRejected.Inc(reason)
+		slog.Info("buy it now rejected", "reason", reason, "bidder", bidderID, "auction", auctionID)
+		if ok && reason != "not_saved" && auction.BuyNowPrice > 0 {
+			bid := Bid{AuctionID: auctionID, BidderID: bidderID, Amount: auction.BuyNowPrice, Timestamp: time.Now(), BuyNow: true}
+			if err := store.RecordRejected(bid, reason); err != nil {
+				slog.Warn("failed to record rejected bid", "auction", auctionID, "err", err)
+			}
+		}
+		return Message{Type: "bid_rejected", RequestID: requestID, Error: fmt.Sprintf(format, args...), Reason: reason}
+	}
+
+	if !ok {
+		return reject("unknown_auction", "No auction with ID %q.", auctionID)
+	}
+	if reason, text := closedReason(auction); reason != "" {
+		return reject(reason, "%s", text)
+	}
+	if auction.BuyNowPrice <= 0 || !auction.ascending() {
+		return reject("buy_now_unavailable", "This auction has no buy-it-now price.")
+	}
+	if auction.CurrentBid >= auction.BuyNowPrice {
+		return reject("buy_now_unavailable", "Bidding has reached the buy-it-now price of $%s; place a bid instead.", formatMoney(auction.BuyNowPrice))
+	}
+
+	bid := Bid{AuctionID: auction.ID, BidderID: bidderID, Amount: auction.BuyNowPrice, Timestamp: time.Now(), BuyNow: true}
+	if err := acceptBid(auction, &bid); err != nil {
+		slog.Error("failed to save buy it now", "auction", auction.ID, "bidder", bidderID, "err", err)
+		return reject("not_saved", "Your purchase could not be saved. Please try again.")
+	}
+
+	snapshot := *auction
+	hub.Broadcast(Message{Type: "auction_bought", Auction: &snapshot, Bid: &bid})
+	slog.Info("auction bought", "auction", auction.ID, "buyer", bidderID, "price", bid.Amount)
+	return Message{Type: "buy_now_confirmed", RequestID: requestID, Auction: &snapshot, Bid: &bid}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBuyNow(t *testing.T) {
+	now := time.Now()
+	auction := func(id, format string, price, buyNow, reserve float64) *Auction {
+		return &Auction{
+			ID: id, Format: format, Status: auctionActive,
+			StartPrice: 100, CurrentBid: price, BuyNowPrice: buyNow,
+			StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour),
+			HasReserve: reserve > 0, reservePrice: reserve,
+		}
+	}
+	ended := auction("ended", "english", 100, 400, 0)
+	ended.Status = auctionEnded
+
+	tests := []struct {
+		name       string
+		auctionID  string
+		wantType   string
+		wantReason string
+		wantSent   []string
+	}{
+		{name: "bought", auctionID: "open", wantType: "buy_now_confirmed", wantSent: []string{"bid_accepted", "auction_bought"}},
+		{name: "bought past the reserve", auctionID: "reserve", wantType: "buy_now_confirmed", wantSent: []string{"bid_accepted", "reserve_met", "auction_bought"}},
+		{name: "bidding reached the price", auctionID: "overtaken", wantType: "bid_rejected", wantReason: "buy_now_unavailable"},
+		{name: "no buy-it-now price", auctionID: "plain", wantType: "bid_rejected", wantReason: "buy_now_unavailable"},
+		{name: "not an english auction", auctionID: "dutch", wantType: "bid_rejected", wantReason: "buy_now_unavailable"},
+		{name: "already ended", auctionID: "ended", wantType: "bid_rejected", wantReason: "auction_ended"},
+		{name: "no such auction", auctionID: "nope", wantType: "bid_rejected", wantReason: "unknown_auction"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := useAuctions(t,
+				auction("open", "english", 150, 400, 0),
+				auction("reserve", "english", 150, 400, 300),
+				auction("overtaken", "english", 400, 400, 0),
+				auction("plain", "english", 150, 0, 0),
+				auction("dutch", "dutch", 150, 400, 0),
+				ended,
+			)
+
+			reply := buyNow("req-1", tt.auctionID, "bidder_1")
+			if reply.Type != tt.wantType || reply.Reason != tt.wantReason || reply.RequestID != "req-1" {
+				t.Fatalf("reply %+v, want %s %q", reply, tt.wantType, tt.wantReason)
+			}
+			var sent []string
+			for _, msg := range broadcasts(t, h) {
+				sent = append(sent, msg.Type)
+			}
+			if !slices.Equal(sent, tt.wantSent) {
+				t.Fatalf("broadcast %v, want %v", sent, tt.wantSent)
+			}
+			if tt.wantType != "buy_now_confirmed" {
+				return
+			}
+
+			a := auctions[tt.auctionID]
+			if a.Status != auctionEnded || a.HighestBidder != "bidder_1" || a.CurrentBid != 400 || a.NextMinimumBid != 0 || a.EndTime.After(time.Now()) {
+				t.Fatalf("after buying: %s, %q at %v, next minimum %v, ends %s", a.Status, a.HighestBidder, a.CurrentBid, a.NextMinimumBid, a.EndTime)
+			}
+			if a.HasReserve && !a.ReserveMet {
+				t.Fatal("buying above the reserve did not meet it")
+			}
+			if reply := processBid("req-2", tt.auctionID, 500, "bidder_2"); reply.Reason != "auction_ended" {
+				t.Fatalf("bid after buying: %+v", reply)
+			}
+		})
+	}
+}
+
+func TestReserveNotMet(t *testing.T) {
+	now := time.Now()
+	a := &Auction{
+		ID: "a", Format: "english", Status: auctionActive,
+		StartPrice: 100, CurrentBid: 100,
+		StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour),
+		HasReserve: true, reservePrice: 250,
+	}
+	h := useAuctions(t, a)
+
+	for _, amount := range []float64{110, 240} {
+		if reply := processBid("", "a", amount, "bidder_1"); reply.Type != "bid_confirmed" {
+			t.Fatalf("bid of %v: %+v", amount, reply)
+		}
+	}
+	for _, msg := range broadcasts(t, h) {
+		if msg.Type == "reserve_met" || msg.Auction.ReserveMet {
+			t.Fatalf("reserve reported met below it: %+v", msg)
+		}
+	}
+
+	// The bidding stopped short, so the close leaves it unsold.
+	settled := *a
+	settled.format().settle(&settled)
+	if settled.Status != auctionUnsold {
+		t.Fatalf("settled %s below the reserve, want unsold", settled.Status)
+	}
+
+	// One more bid at the reserve meets it, once.
+	processBid("", "a", 250, "bidder_2")
+	var met int
+	for _, msg := range broadcasts(t, h) {
+		if msg.Type == "reserve_met" {
+			met++
+		}
+	}
+	if met != 1 || !a.ReserveMet {
+		t.Fatalf("%d reserve_met broadcasts, reserve met %v", met, a.ReserveMet)
+	}
+	a.format().settle(a)
+	if a.Status != auctionEnded || a.HighestBidder != "bidder_2" || a.CurrentBid != 250 {
+		t.Fatalf("settled %s, %q at %v; want ended, bidder_2 at 250", a.Status, a.HighestBidder, a.CurrentBid)
+	}
+}
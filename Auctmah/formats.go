@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ============ AUCTION FORMATS ============
+// An auction's Format decides what a valid bid is, what accepting one does
+// and who wins at the close. processBid and the timer handle everything the
+// formats share (locking, saving, broadcasting) and ask the format the rest.
+//
+//   - english: ascending and open; the highest bid leads and wins. Proxy
+//     bidding, buy-it-now and soft close only apply here.
+//   - dutch: the price starts at StartPrice and drops on the Dutch schedule
+//     as the timer runs; the first bid takes the item at the current price.
+//   - sealed: bids are hidden until the close, then the highest wins and
+//     pays what it bid. A bidder may bid again to replace their bid.
+//   - vickrey: sealed, but the winner pays the second-highest bid.
+
+type auctionFormat interface {
+	// checkBid says why amount is not a valid bid on the open auction, or
+	// returns the price the bid is recorded at.
+	checkBid(a *Auction, bidderID string, amount float64) (price float64, reason, text string)
+	// apply records an accepted bid, which already has its Seq, and reports
+	// whether soft close moved the end. It must depend only on the auction
+	// and the bid, so replaying a bid reproduces it.
+	apply(a *Auction, b Bid) (extended bool)
+	// tick runs every second while the auction is open, and once as it
+	// starts, and reports whether it changed the price.
+	tick(a *Auction, now time.Time) (changed bool)
+	// settle sets the winner, price and final status, ended or unsold, once
+	// the end time has passed.
+	settle(a *Auction)
+	// minimumBid is the least the next bid can be.
+	minimumBid(a *Auction) float64
+	// sealed formats keep bid amounts secret until the auction closes.
+	sealed() bool
+}
+
+const defaultFormat = "english"
+
+var auctionFormats = map[string]auctionFormat{
+	"english": englishFormat{},
+	"dutch":   dutchFormat{},
+	"sealed":  sealedFormat{},
+	"vickrey": sealedFormat{secondPrice: true},
+}
+
+// format returns the auction's format; auctions saved before formats
+// existed are English.
+func (a *Auction) format() auctionFormat {
+	if f, ok := auctionFormats[a.Format]; ok {
+		return f
+	}
+	return auctionFormats[defaultFormat]
+}
+
+// ascending reports whether the auction is English, the only format with
+// proxy bidding and buy-it-now.
+func (a *Auction) ascending() bool {
+	_, ok := a.format().(englishFormat)
+	return ok
+}
+
+// ============ ENGLISH ============
+type englishFormat struct{}
+
+func (f englishFormat) checkBid(a *Auction, bidderID string, amount float64) (float64, string, string) {
+	if minRequired := f.minimumBid(a); amount < minRequired {
+		return 0, "below_minimum", fmt.Sprintf("Bid must be at least $%.2f (current bid + $%.2f increment)", minRequired, a.increment())
+	}
+	return amount, "", ""
+}
+
+func (englishFormat) minimumBid(a *Auction) float64 {
+	return a.CurrentBid + a.increment()
+}
+
+func (englishFormat) apply(a *Auction, b Bid) (extended bool) {
+	a.CurrentBid = b.Amount
+	a.HighestBidder = b.BidderID
+	if a.HasReserve && b.Amount >= a.reservePrice {
+		a.ReserveMet = true
+	}
+	if b.BuyNow {
+		a.moveTo(auctionEnded)
+		a.EndTime = b.Timestamp
+		return false
+	}
+
+	sc := a.SoftClose
+	if sc == nil || sc.ExtendSeconds <= 0 {
+		return false
+	}
+	window := time.Duration(sc.WindowSeconds) * time.Second
+	if b.Timestamp.Before(a.EndTime.Add(-window)) {
+		return false
+	}
+	add := sc.ExtendSeconds
+	if sc.MaxExtensionSeconds > 0 {
+		add = min(add, sc.MaxExtensionSeconds-a.ExtendedSeconds)
+	}
+	if add <= 0 {
+		return false
+	}
+	a.EndTime = a.EndTime.Add(time.Duration(add) * time.Second)
+	a.ExtendedSeconds += add
+	a.moveTo(auctionExtended)
+	return true
+}
+
+func (englishFormat) tick(*Auction, time.Time) bool { return false }
+
+// settle leaves the leader as the winner. With no bids, or bidding that
+// stopped short of the reserve, nothing is sold.
+func (englishFormat) settle(a *Auction) {
+	if a.HighestBidder == "" || (a.HasReserve && !a.ReserveMet) {
+		a.moveTo(auctionUnsold)
+		return
+	}
+	a.moveTo(auctionEnded)
+}
+
+func (englishFormat) sealed() bool { return false }
+
+// ============ DUTCH ============
+// DutchSchedule lowers a Dutch auction's price by DropAmount every
+// DropEverySeconds from StartTime, down to FloorPrice.
+type DutchSchedule struct {
+	DropAmount       float64 `json:"drop_amount"`
+	DropEverySeconds int     `json:"drop_every_seconds"`
+	FloorPrice       float64 `json:"floor_price"`
+}
+
+type dutchFormat struct{}
+
+// dutchPrice is the asking price at now. It depends only on the clock, so
+// nothing needs saving as it drops.
+func dutchPrice(a *Auction, now time.Time) float64 {
+	d := a.Dutch
+	if d == nil || d.DropEverySeconds <= 0 {
+		return a.StartPrice
+	}
+	steps := max(int(now.Sub(a.StartTime)/(time.Duration(d.DropEverySeconds)*time.Second)), 0)
+	return max(d.FloorPrice, a.StartPrice-float64(steps)*d.DropAmount)
+}
+
+// checkBid takes any bid at or above the asking price at the asking price,
+// so a bid sent just before a drop pays the lower price.
+func (dutchFormat) checkBid(a *Auction, bidderID string, amount float64) (float64, string, string) {
+	price := dutchPrice(a, time.Now())
+	if amount < price {
+		return 0, "below_minimum", fmt.Sprintf("The price is $%.2f; bid at least that to buy.", price)
+	}
+	return price, "", ""
+}
+
+func (dutchFormat) minimumBid(a *Auction) float64 {
+	return a.CurrentBid
+}
+
+// apply ends the auction: the first bid wins.
+func (dutchFormat) apply(a *Auction, b Bid) bool {
+	a.CurrentBid = b.Amount
+	a.HighestBidder = b.BidderID
+	a.moveTo(auctionEnded)
+	a.EndTime = b.Timestamp
+	return false
+}
+
+func (dutchFormat) tick(a *Auction, now time.Time) bool {
+	price := dutchPrice(a, now)
+	if price == a.CurrentBid {
+		return false
+	}
+	a.CurrentBid = price
+	return true
+}
+
+// settle only runs if nobody bid before the end, so nothing was sold.
+func (dutchFormat) settle(a *Auction) {
+	a.moveTo(auctionUnsold)
+}
+
+func (dutchFormat) sealed() bool { return false }
+
+// ============ SEALED AND VICKREY ============
+// Sealed bids are kept on the auction, unexported like the proxies, and
+// only the count is public until settle picks the winner.
+type sealedFormat struct {
+	secondPrice bool
+}
+
+func (sealedFormat) checkBid(a *Auction, bidderID string, amount float64) (float64, string, string) {
+	if amount < a.StartPrice {
+		return 0, "below_minimum", fmt.Sprintf("Bid must be at least $%.2f", a.StartPrice)
+	}
+	return amount, "", ""
+}
+
+func (sealedFormat) minimumBid(a *Auction) float64 {
+	return a.StartPrice
+}
+
+// apply replaces the bidder's earlier sealed bid, if any. Like setProxy it
+// builds a new slice, since copies of the auction share the old one.
+func (sealedFormat) apply(a *Auction, b Bid) bool {
+	bids := make([]Bid, 0, len(a.sealedBids)+1)
+	for _, existing := range a.sealedBids {
+		if existing.BidderID != b.BidderID {
+			bids = append(bids, existing)
+		}
+	}
+	a.sealedBids = append(bids, b)
+	return false
+}
+
+func (sealedFormat) tick(*Auction, time.Time) bool { return false }
+
+// settle awards the highest bid, the earliest on a tie. In a Vickrey
+// auction the winner pays the second-highest bid, or the start price if
+// there is none, but never less than the reserve.
+func (f sealedFormat) settle(a *Auction) {
+	if len(a.sealedBids) == 0 {
+		a.moveTo(auctionUnsold)
+		return
+	}
+	bids := append([]Bid(nil), a.sealedBids...)
+	sort.SliceStable(bids, func(i, j int) bool {
+		if bids[i].Amount != bids[j].Amount {
+			return bids[i].Amount > bids[j].Amount
+		}
+		return bids[i].Timestamp.Before(bids[j].Timestamp)
+	})
+	winner := bids[0]
+	a.HighestBidder = winner.BidderID
+	a.CurrentBid = winner.Amount
+	if f.secondPrice {
+		a.CurrentBid = a.StartPrice
+		if len(bids) > 1 {
+			a.CurrentBid = bids[1].Amount
+		}
+		if a.HasReserve && winner.Amount >= a.reservePrice {
+			a.CurrentBid = max(a.CurrentBid, a.reservePrice)
+		}
+	}
+	if a.HasReserve {
+		a.ReserveMet = winner.Amount >= a.reservePrice
+		if !a.ReserveMet {
+			a.moveTo(auctionUnsold)
+			return
+		}
+	}
+	a.moveTo(auctionEnded)
+}
+
+func (sealedFormat) sealed() bool { return true }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSettle(t *testing.T) {
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	bid := func(bidder string, amount float64) Bid {
+		return Bid{BidderID: bidder, Amount: amount, Timestamp: start.Add(time.Minute)}
+	}
+
+	tests := []struct {
+		name       string
+		format     string
+		reserve    float64
+		bids       []Bid
+		wantStatus auctionStatus
+		wantWinner string
+		wantPrice  float64
+	}{
+		{name: "english, no bids", format: "english", wantStatus: auctionUnsold, wantPrice: 100},
+		{name: "english, no bids, reserve", format: "english", reserve: 150, wantStatus: auctionUnsold, wantPrice: 100},
+		{name: "english, sold", format: "english", bids: []Bid{bid("a", 120)}, wantStatus: auctionEnded, wantWinner: "a", wantPrice: 120},
+		{name: "english, below reserve", format: "english", reserve: 150, bids: []Bid{bid("a", 120)}, wantStatus: auctionUnsold, wantWinner: "a", wantPrice: 120},
+		{name: "dutch, no bids", format: "dutch", wantStatus: auctionUnsold, wantPrice: 100},
+		{name: "dutch, no bids, reserve", format: "dutch", reserve: 150, wantStatus: auctionUnsold, wantPrice: 100},
+		{name: "sealed, no bids", format: "sealed", wantStatus: auctionUnsold, wantPrice: 100},
+		{name: "sealed, no bids, reserve", format: "sealed", reserve: 150, wantStatus: auctionUnsold, wantPrice: 100},
+		{name: "sealed, sold", format: "sealed", bids: []Bid{bid("a", 120), bid("b", 180)}, wantStatus: auctionEnded, wantWinner: "b", wantPrice: 180},
+		{name: "sealed, below reserve", format: "sealed", reserve: 200, bids: []Bid{bid("a", 180)}, wantStatus: auctionUnsold, wantWinner: "a", wantPrice: 180},
+		{name: "vickrey, no bids", format: "vickrey", wantStatus: auctionUnsold, wantPrice: 100},
+		{name: "vickrey, no bids, reserve", format: "vickrey", reserve: 150, wantStatus: auctionUnsold, wantPrice: 100},
+		{name: "vickrey, second price", format: "vickrey", bids: []Bid{bid("a", 120), bid("b", 180)}, wantStatus: auctionEnded, wantWinner: "b", wantPrice: 120},
+		{name: "vickrey, price lifted to reserve", format: "vickrey", reserve: 150, bids: []Bid{bid("a", 120), bid("b", 180)}, wantStatus: auctionEnded, wantWinner: "b", wantPrice: 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Auction{
+				ID:           "auction-test",
+				Format:       tt.format,
+				Status:       auctionActive,
+				StartPrice:   100,
+				CurrentBid:   100,
+				StartTime:    start,
+				EndTime:      start.Add(time.Hour),
+				HasReserve:   tt.reserve > 0,
+				reservePrice: tt.reserve,
+			}
+			for _, b := range tt.bids {
+				b.AuctionID = a.ID
+				a.format().apply(a, b)
+			}
+			a.format().settle(a)
+
+			if a.Status != tt.wantStatus || a.HighestBidder != tt.wantWinner || a.CurrentBid != tt.wantPrice {
+				t.Fatalf("settled %s, winner %q at %v; want %s, winner %q at %v", a.Status, a.HighestBidder, a.CurrentBid, tt.wantStatus, tt.wantWinner, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestSoftClose(t *testing.T) {
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		softClose    *SoftClose
+		extended     int
+		before       time.Duration
+		buyNow       bool
+		wantExtended bool
+		wantEnd      time.Time
+	}{
+		{name: "no soft close", before: time.Second, wantEnd: end},
+		{name: "before the window", softClose: &SoftClose{WindowSeconds: 30, ExtendSeconds: 30, MaxExtensionSeconds: 300}, before: 31 * time.Second, wantEnd: end},
+		{name: "at the window's edge", softClose: &SoftClose{WindowSeconds: 30, ExtendSeconds: 30, MaxExtensionSeconds: 300}, before: 30 * time.Second, wantExtended: true, wantEnd: end.Add(30 * time.Second)},
+		{name: "last second", softClose: &SoftClose{WindowSeconds: 30, ExtendSeconds: 30, MaxExtensionSeconds: 300}, before: time.Second, wantExtended: true, wantEnd: end.Add(30 * time.Second)},
+		{name: "cut short by the cap", softClose: &SoftClose{WindowSeconds: 30, ExtendSeconds: 30, MaxExtensionSeconds: 300}, extended: 290, before: time.Second, wantExtended: true, wantEnd: end.Add(10 * time.Second)},
+		{name: "cap reached", softClose: &SoftClose{WindowSeconds: 30, ExtendSeconds: 30, MaxExtensionSeconds: 300}, extended: 300, before: time.Second, wantEnd: end},
+		{name: "no cap", softClose: &SoftClose{WindowSeconds: 30, ExtendSeconds: 30}, extended: 3000, before: time.Second, wantExtended: true, wantEnd: end.Add(30 * time.Second)},
+		{name: "no extension length", softClose: &SoftClose{WindowSeconds: 30, MaxExtensionSeconds: 300}, before: time.Second, wantEnd: end},
+		{name: "buy it now ends it", softClose: &SoftClose{WindowSeconds: 30, ExtendSeconds: 30, MaxExtensionSeconds: 300}, before: time.Second, buyNow: true, wantEnd: end.Add(-time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Auction{
+				ID:              "auction-test",
+				Format:          "english",
+				Status:          auctionActive,
+				StartPrice:      100,
+				CurrentBid:      100,
+				StartTime:       start,
+				EndTime:         end,
+				SoftClose:       tt.softClose,
+				ExtendedSeconds: tt.extended,
+			}
+			b := Bid{AuctionID: a.ID, BidderID: "a", Amount: 120, Timestamp: end.Add(-tt.before), BuyNow: tt.buyNow}
+			extended := a.format().apply(a, b)
+
+			if extended != tt.wantExtended || !a.EndTime.Equal(tt.wantEnd) {
+				t.Fatalf("extended %v, ends %s; want %v, %s", extended, a.EndTime, tt.wantExtended, tt.wantEnd)
+			}
+			wantStatus := auctionActive
+			switch {
+			case tt.buyNow:
+				wantStatus = auctionEnded
+			case tt.wantExtended:
+				wantStatus = auctionExtended
+			}
+			if a.Status != wantStatus {
+				t.Fatalf("status %s, want %s", a.Status, wantStatus)
+			}
+			if want := tt.extended + int(tt.wantEnd.Sub(end)/time.Second); tt.wantExtended && a.ExtendedSeconds != want {
+				t.Fatalf("extended_seconds %d, want %d", a.ExtendedSeconds, want)
+			}
+		})
+	}
+}
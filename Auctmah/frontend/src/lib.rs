@@ -27,6 +27,8 @@ pub struct Auction {
 
 #[derive(Serialize, Deserialize, Clone)]
 pub struct Bid {
+    #[serde(default)]
+    pub auction_id: String,
     pub bidder_id: String,
     pub amount: f64,
     pub timestamp: String,
@@ -36,6 +38,8 @@ pub struct Bid {
 pub struct Message {
     #[serde(rename = "type")]
     pub msg_type: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub request_id: Option<String>,
     pub auction: Option<Auction>,
     pub bid: Option<Bid>,
     pub error: Option<String>,
@@ -277,8 +281,8 @@ fn draw_auction_card(ctx: &CanvasRenderingContext2d, auction: &Auction, x: f64,
     
     // Border
     let border_color = match auction.status.as_str() {
-        "active" => "#00d4ff",
-        "ended" => "#ff6b6b",
+        "active" | "extended" => "#00d4ff",
+        "ended" | "unsold" | "settled" | "cancelled" => "#ff6b6b",
         _ => "#8b5cf6",
     };
     ctx.set_stroke_style(&wasm_bindgen::JsValue::from_str(border_color));
@@ -297,8 +301,8 @@ fn draw_auction_card(ctx: &CanvasRenderingContext2d, auction: &Auction, x: f64,
     
     // Status badge
     let status_bg = match auction.status.as_str() {
-        "active" => "#00d4ff",
-        "ended" => "#ff6b6b",
+        "active" | "extended" => "#00d4ff",
+        "ended" | "unsold" | "settled" | "cancelled" => "#ff6b6b",
         _ => "#8b5cf6",
     };
     ctx.set_fill_style(&wasm_bindgen::JsValue::from_str(status_bg));
@@ -362,6 +366,7 @@ pub fn place_bid(auction_id: String, amount: f64) {
     if let Some(_window) = window() {
         if let Some(ws) = get_websocket() {
             let bid = Bid {
+                auction_id: auction_id.clone(),
                 bidder_id: format!("bidder_{}", js_sys::Date::now() as u32),
                 amount,
                 timestamp: js_sys::Date::now().to_string(),
@@ -369,6 +374,7 @@ pub fn place_bid(auction_id: String, amount: f64) {
             
             let msg = Message {
                 msg_type: "place_bid".to_string(),
+                request_id: Some(format!("bid-{}", js_sys::Date::now() as u64)),
                 auction: None,
                 bid: Some(bid),
                 error: None,
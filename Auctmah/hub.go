@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ============ WEBSOCKET HUB ============
+// The hub goroutine owns the set of clients and is the only sender on (and
+// closer of) each client's queue. Each client's writePump is the only
+// writer on its connection, so a slow browser fills its own queue instead
+// of holding up everyone else, and is dropped once the queue is full.
+
+const sendQueueSize = 256
+
+type Client struct {
+	conn *websocket.Conn
+	id   string
+
+	// queue holds encoded messages for writePump. Closing it tells
+	// writePump to send a close frame with closeReason and stop.
+	queue       chan []byte
+	closeReason string
+}
+
+// direct is a message for one client only, named by pointer or, when
+// client is nil, by bidder ID.
+type direct struct {
+	client   *Client
+	bidderID string
+	data     []byte
+}
+
+type Hub struct {
+	register   chan *Client
+	unregister chan *Client
+	broadcast  chan []byte
+	direct     chan direct
+	shutdown   chan chan int
+
+	clients map[*Client]bool
+	count   atomic.Int64
+	pumps   sync.WaitGroup
+}
+
+var hub = newHub()
+
+func newHub() *Hub {
+	return &Hub{
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		broadcast:  make(chan []byte, 256),
+		direct:     make(chan direct, 256),
+		shutdown:   make(chan chan int),
+		clients:    make(map[*Client]bool),
+	}
+}
+
+func (h *Hub) run() {
+	for {
+		select {
+		case c := <-h.register:
+			h.clients[c] = true
+			h.count.Store(int64(len(h.clients)))
+			slog.Info("client connected", "client", c.id, "clients", len(h.clients))
+			if data, err := json.Marshal(Message{Type: "client_count_update"}); err == nil {
+				h.fanOut(data)
+			}
+
+		case c := <-h.unregister:
+			if h.clients[c] {
+				h.remove(c)
+				slog.Info("client disconnected", "client", c.id, "clients", len(h.clients))
+			}
+
+		case data := <-h.broadcast:
+			h.fanOut(data)
+
+		case d := <-h.direct:
+			if d.client == nil {
+				for c := range h.clients {
+					if c.id == d.bidderID {
+						h.enqueue(c, d.data)
+					}
+				}
+			} else if h.clients[d.client] {
+				h.enqueue(d.client, d.data)
+			}
+
+		case reply := <-h.shutdown:
+			n := len(h.clients)
+			for c := range h.clients {
+				c.closeReason = "server shutting down"
+				h.remove(c)
+			}
+			reply <- n
+		}
+	}
+}
+
+func (h *Hub) fanOut(data []byte) {
+	for c := range h.clients {
+		h.enqueue(c, data)
+	}
+}
+
+// enqueue never blocks: a client whose queue is full is too far behind to
+// catch up and is disconnected.
+func (h *Hub) enqueue(c *Client, data []byte) {
+	select {
+	case c.queue <- data:
+	default:
+		c.closeReason = "too far behind"
+		h.remove(c)
+		clientsEvicted.Inc()
+		slog.Warn("evicted slow websocket client", "client", c.id, "queued", sendQueueSize)
+	}
+}
+
+func (h *Hub) remove(c *Client) {
+	delete(h.clients, c)
+	close(c.queue)
+	h.count.Store(int64(len(h.clients)))
+}
+
+// Broadcast sends msg to every client. It is encoded here, in the caller,
+// so an Auction the caller has locked is read under that lock.
+func (h *Hub) Broadcast(msg Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("failed to encode broadcast", "type", msg.Type, "err", err)
+		return
+	}
+	h.broadcast <- data
+}
+
+// Send queues msg for one client.
+func (h *Hub) Send(c *Client, msg Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("failed to encode message", "type", msg.Type, "err", err)
+		return
+	}
+	h.direct <- direct{client: c, data: data}
+}
+
+// SendToBidder queues msg for the bidder's connection, if they are still
+// connected.
+func (h *Hub) SendToBidder(bidderID string, msg Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("failed to encode message", "type", msg.Type, "err", err)
+		return
+	}
+	h.direct <- direct{bidderID: bidderID, data: data}
+}
+
+// Count returns the number of connected clients.
+func (h *Hub) Count() int {
+	return int(h.count.Load())
+}
+
+// QueueDepth returns the broadcasts waiting for the hub.
+func (h *Hub) QueueDepth() int {
+	return len(h.broadcast)
+}
+
+// Close disconnects every client with a going-away close frame and waits,
+// up to writeWait, for the frames to be written, so browsers reconnect to
+// the next instance instead of seeing a dropped socket.
+func (h *Hub) Close() {
+	reply := make(chan int)
+	h.shutdown <- reply
+	n := <-reply
+
+	done := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(writeWait):
+	}
+	slog.Info("closed websocket clients for shutdown", "clients", n)
+}
+
+// ============ MESSAGE WRITER ============
+// writePump is the only goroutine that writes to the connection: queued
+// messages, pings, and the close frame once the hub drops the client.
+func writePump(client *Client) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		client.conn.Close()
+		hub.pumps.Done()
+	}()
+
+	for {
+		select {
+		case data, ok := <-client.queue:
+			client.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, client.closeReason)
+				client.conn.WriteMessage(websocket.CloseMessage, msg)
+				return
+			}
+			if err := client.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			client.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := client.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// next returns the next message queued for c, or fails if none arrives.
+func next(t *testing.T, c *Client) (data string, open bool) {
+	t.Helper()
+	select {
+	case b, ok := <-c.queue:
+		return string(b), ok
+	case <-time.After(time.Second):
+		t.Fatalf("nothing queued for %s", c.id)
+		return "", false
+	}
+}
+
+func TestHubEvictsSlowClients(t *testing.T) {
+	h := newHub()
+	go h.run()
+
+	// The slow client's queue fills with the connection count updates the
+	// two registrations send; the fast one has room to spare.
+	slow := &Client{id: "slow", queue: make(chan []byte, 2)}
+	fast := &Client{id: "fast", queue: make(chan []byte, 8)}
+	h.register <- slow
+	h.register <- fast
+	if data, _ := next(t, fast); !strings.Contains(data, "client_count_update") {
+		t.Fatalf("fast client first got %s", data)
+	}
+	if n := h.Count(); n != 2 {
+		t.Fatalf("%d clients connected, want 2", n)
+	}
+
+	h.Broadcast(Message{Type: "bid_accepted", Auction: &Auction{ID: "a"}})
+	if data, _ := next(t, fast); !strings.Contains(data, "bid_accepted") {
+		t.Fatalf("fast client got %s, want the broadcast", data)
+	}
+
+	// The slow client keeps what it had queued, then its queue is closed
+	// so writePump sends the close frame.
+	for range 2 {
+		if data, open := next(t, slow); !open || !strings.Contains(data, "client_count_update") {
+			t.Fatalf("slow client got %q (open %v), want its queued updates", data, open)
+		}
+	}
+	if data, open := next(t, slow); open {
+		t.Fatalf("slow client got %s after falling behind", data)
+	}
+	if slow.closeReason != "too far behind" {
+		t.Fatalf("close reason %q", slow.closeReason)
+	}
+	if n := h.Count(); n != 1 {
+		t.Fatalf("%d clients connected, want 1", n)
+	}
+
+	// Later messages skip the evicted client instead of panicking on its
+	// closed queue.
+	h.SendToBidder("slow", Message{Type: "max_bid_exceeded"})
+	h.Send(slow, Message{Type: "history"})
+	h.Broadcast(Message{Type: "price_dropped"})
+	if data, _ := next(t, fast); !strings.Contains(data, "price_dropped") {
+		t.Fatalf("fast client got %s, want the second broadcast", data)
+	}
+
+	h.Close()
+	if _, open := next(t, fast); open || fast.closeReason != "server shutting down" {
+		t.Fatalf("after Close the fast client is open %v with reason %q", open, fast.closeReason)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ============ AUCTION LIFECYCLE ============
+// An auction moves through its statuses only along auctionTransitions:
+//
+//	draft ──► scheduled ──► active ──► extended ──► ended ──► settled
+//	  │           │           │           │    └──► unsold
+//	  └───────────┴───────────┴───────────┴──► cancelled
+//
+// The timer starts scheduled auctions at StartTime and closes open ones at
+// EndTime; soft close moves an active auction to extended, and the format
+// decides at the close whether it ended with a sale or unsold. Drafts are
+// not shown to clients. Whoever runs the site settles an ended sale once
+// it is paid for and handed over, and can cancel an auction that has not
+// closed; both go through the admin routes and closeAuction.
+
+type auctionStatus string
+
+const (
+	auctionDraft     auctionStatus = "draft"
+	auctionScheduled auctionStatus = "scheduled"
+	auctionActive    auctionStatus = "active"
+	auctionExtended  auctionStatus = "extended" // active, with the end moved back by soft close
+	auctionEnded     auctionStatus = "ended"
+	auctionUnsold    auctionStatus = "unsold" // ended with no bids, or the bidding below the reserve
+	auctionSettled   auctionStatus = "settled"
+	auctionCancelled auctionStatus = "cancelled"
+)
+
+// auctionTransitions lists the statuses each status can move to. Closed
+// statuses have none, apart from an ended sale being settled.
+var auctionTransitions = map[auctionStatus][]auctionStatus{
+	auctionDraft:     {auctionScheduled, auctionActive, auctionCancelled},
+	auctionScheduled: {auctionActive, auctionCancelled},
+	auctionActive:    {auctionExtended, auctionEnded, auctionUnsold, auctionCancelled},
+	auctionExtended:  {auctionEnded, auctionUnsold, auctionCancelled},
+	auctionEnded:     {auctionSettled},
+	auctionUnsold:    nil,
+	auctionSettled:   nil,
+	auctionCancelled: nil,
+}
+
+// known reports whether s is one of the statuses above, for checking
+// auctions as they load.
+func (s auctionStatus) known() bool {
+	_, ok := auctionTransitions[s]
+	return ok
+}
+
+// open reports whether the auction is taking bids, extended or not.
+func (s auctionStatus) open() bool {
+	return s == auctionActive || s == auctionExtended
+}
+
+// closed reports whether bidding is over for good.
+func (s auctionStatus) closed() bool {
+	return s == auctionEnded || s == auctionUnsold || s == auctionSettled || s == auctionCancelled
+}
+
+// errStatusChange is wrapped by the error transition returns when a move
+// is not in auctionTransitions.
+var errStatusChange = errors.New("status change not allowed")
+
+// transition moves the auction to status to, or says why it cannot. Moving
+// to the status it already has is allowed, so a second extension leaves
+// an extended auction as it is.
+func (a *Auction) transition(to auctionStatus) error {
+	if a.Status == to {
+		return nil
+	}
+	for _, next := range auctionTransitions[a.Status] {
+		if next == to {
+			a.Status = to
+			return nil
+		}
+	}
+	return fmt.Errorf("auction %s cannot go from %s to %s: %w", a.ID, a.Status, to, errStatusChange)
+}
+
+// moveTo is transition for the formats, which only change the status of
+// open auctions and so have no error to return. A refused move is a bug;
+// it is logged and the status left alone.
+func (a *Auction) moveTo(to auctionStatus) {
+	if err := a.transition(to); err != nil {
+		slog.Error("invalid auction status change", "err", err)
+	}
+}
+
+// listedAuction returns the auction with the given ID unless it is a
+// draft, which clients are never told about. Call it with auctionMutex
+// held.
+func listedAuction(id string) (*Auction, bool) {
+	a, ok := auctions[id]
+	if !ok || a.Status == auctionDraft {
+		return nil, false
+	}
+	return a, true
+}
+
+// startAuction opens a scheduled auction whose start time has come, sets a
+// Dutch auction's opening price and announces it with auction_started.
+// Call it with auctionMutex held.
+func startAuction(a *Auction, now time.Time) {
+	snapshot := *a
+	if err := snapshot.transition(auctionActive); err != nil {
+		slog.Error("failed to start auction", "err", err)
+		return
+	}
+	snapshot.format().tick(&snapshot, now)
+	snapshot.refreshMinimum()
+	if err := store.SaveAuction(snapshot); err != nil {
+		// Still scheduled, so the next tick tries again.
+		slog.Error("failed to save started auction", "auction", a.ID, "err", err)
+		return
+	}
+	*a = snapshot
+	hub.Broadcast(Message{Type: "auction_started", Auction: a})
+	slog.Info("auction started", "auction", a.ID, "end_time", a.EndTime)
+}
+
+// closeAuction settles or cancels the auction with the given ID for whoever
+// runs the site. The change is saved before it takes effect and announced
+// with auction_settled or auction_cancelled, except for a draft, which
+// clients never saw. Settling or cancelling twice is refused like any other
+// move not in auctionTransitions.
+func closeAuction(id string, to auctionStatus) (Auction, error) {
+	auctionMutex.Lock()
+	defer auctionMutex.Unlock()
+
+	a, ok := auctions[id]
+	if !ok {
+		return Auction{}, errUnknownAuction
+	}
+	if a.Status == to {
+		return Auction{}, fmt.Errorf("auction %s is already %s: %w", a.ID, to, errStatusChange)
+	}
+	draft := a.Status == auctionDraft
+
+	snapshot := *a
+	if err := snapshot.transition(to); err != nil {
+		return Auction{}, err
+	}
+	snapshot.refreshMinimum()
+	if err := store.SaveAuction(snapshot); err != nil {
+		return Auction{}, err
+	}
+	*a = snapshot
+
+	if !draft {
+		hub.Broadcast(Message{Type: "auction_" + string(to), Auction: a})
+	}
+	slog.Info("auction "+string(to), "auction", a.ID, "winner", a.HighestBidder, "price", a.CurrentBid)
+	return snapshot, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/router"
+)
+
+func TestTransition(t *testing.T) {
+	all := []auctionStatus{auctionDraft, auctionScheduled, auctionActive, auctionExtended, auctionEnded, auctionUnsold, auctionSettled, auctionCancelled}
+	allowed := map[[2]auctionStatus]bool{}
+	for from, tos := range auctionTransitions {
+		allowed[[2]auctionStatus{from, from}] = true
+		for _, to := range tos {
+			allowed[[2]auctionStatus{from, to}] = true
+		}
+	}
+	for _, from := range all {
+		for _, to := range all {
+			a := &Auction{ID: "a", Status: from}
+			err := a.transition(to)
+			if want := allowed[[2]auctionStatus{from, to}]; (err == nil) != want {
+				t.Errorf("%s -> %s: err %v, want allowed %v", from, to, err, want)
+			}
+			if err != nil && a.Status != from {
+				t.Errorf("%s -> %s: refused, but status became %s", from, to, a.Status)
+			}
+		}
+	}
+}
+
+// newAdminServer mounts the API over a fresh store holding one auction per
+// status, and returns it with the hub its broadcasts go to.
+func newAdminServer(t *testing.T, token string) (*http.ServeMux, *Hub) {
+	t.Helper()
+	savedToken := adminToken
+	t.Cleanup(func() { adminToken = savedToken })
+	adminToken = token
+
+	now := time.Now()
+	var list []*Auction
+	for _, status := range []auctionStatus{auctionDraft, auctionScheduled, auctionActive, auctionEnded, auctionUnsold} {
+		id := string(status)
+		list = append(list, &Auction{ID: id, Title: id, Status: status, StartPrice: 10, CurrentBid: 10, StartTime: now, EndTime: now.Add(time.Hour)})
+	}
+	h := useAuctions(t, list...)
+
+	mux := http.NewServeMux()
+	router.Mount(mux, "/api/v1", routesV1, router.Options{})
+	return mux, h
+}
+
+func TestCloseAuction(t *testing.T) {
+	tests := []struct {
+		id, action    string
+		wantCode      int
+		wantStatus    auctionStatus
+		wantBroadcast string
+	}{
+		{id: "ended", action: "settle", wantCode: http.StatusOK, wantStatus: auctionSettled, wantBroadcast: "auction_settled"},
+		{id: "active", action: "settle", wantCode: http.StatusConflict, wantStatus: auctionActive},
+		{id: "unsold", action: "settle", wantCode: http.StatusConflict, wantStatus: auctionUnsold},
+		{id: "active", action: "cancel", wantCode: http.StatusOK, wantStatus: auctionCancelled, wantBroadcast: "auction_cancelled"},
+		{id: "scheduled", action: "cancel", wantCode: http.StatusOK, wantStatus: auctionCancelled, wantBroadcast: "auction_cancelled"},
+		{id: "draft", action: "cancel", wantCode: http.StatusOK, wantStatus: auctionCancelled},
+		{id: "ended", action: "cancel", wantCode: http.StatusConflict, wantStatus: auctionEnded},
+		{id: "missing", action: "cancel", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action+" "+tt.id, func(t *testing.T) {
+			mux, h := newAdminServer(t, "secret")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/auctions/"+tt.id+"/"+tt.action+"?token=secret", nil))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got %d %q, want %d", rec.Code, rec.Body.String(), tt.wantCode)
+			}
+			if a, ok := auctions[tt.id]; ok && a.Status != tt.wantStatus {
+				t.Fatalf("status %s, want %s", a.Status, tt.wantStatus)
+			}
+
+			select {
+			case data := <-h.broadcast:
+				var msg Message
+				if err := json.Unmarshal(data, &msg); err != nil || msg.Type != tt.wantBroadcast || msg.Auction.Status != tt.wantStatus {
+					t.Fatalf("broadcast %s, want %q", data, tt.wantBroadcast)
+				}
+			default:
+				if tt.wantBroadcast != "" {
+					t.Fatalf("no %s broadcast", tt.wantBroadcast)
+				}
+			}
+		})
+	}
+}
+
+func TestClosedAuctionsRefuseBidsAndCloseOnce(t *testing.T) {
+	mux, _ := newAdminServer(t, "secret")
+	for _, path := range []string{"/api/v1/auctions/active/cancel?token=secret", "/api/v1/auctions/active/cancel?token=secret"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+	}
+	if reply := processBid("r1", "active", 50, "bidder_1"); reply.Type != "bid_rejected" || reply.Reason != "auction_cancelled" {
+		t.Fatalf("bid on a cancelled auction: %+v", reply)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/auctions/active/cancel?token=secret", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("cancelling twice returned %d, want 409", rec.Code)
+	}
+}
+
+func TestCloseAuctionNeedsToken(t *testing.T) {
+	tests := []struct {
+		name, configured, sent string
+		wantCode               int
+	}{
+		{"no token configured", "", "", http.StatusForbidden},
+		{"no token configured, one sent", "", "anything", http.StatusForbidden},
+		{"missing", "secret", "", http.StatusUnauthorized},
+		{"wrong", "secret", "guess", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		mux, _ := newAdminServer(t, tt.configured)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/auctions/ended/settle?token="+tt.sent, nil))
+		if rec.Code != tt.wantCode || auctions["ended"].Status != auctionEnded {
+			t.Errorf("%s: got %d and status %s, want %d and the auction untouched", tt.name, rec.Code, auctions["ended"].Status, tt.wantCode)
+		}
+	}
+}
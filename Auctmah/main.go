@@ -1,48 +1,148 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
+	"embed"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/assets"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/cors"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/logging"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/metrics"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/router"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/serve"
 	"github.com/gorilla/websocket"
 )
 
 // ============ DATA MODELS ============
 type Auction struct {
-	ID            string    `json:"id"`
-	Title         string    `json:"title"`
-	Description   string    `json:"description"`
-	StartPrice    float64   `json:"start_price"`
-	CurrentBid    float64   `json:"current_bid"`
-	HighestBidder string    `json:"highest_bidder"`
-	BidCount      int       `json:"bid_count"`
-	Status        string    `json:"status"`
-	StartTime     time.Time `json:"start_time"`
-	EndTime       time.Time `json:"end_time"`
+	ID            string        `json:"id"`
+	Title         string        `json:"title"`
+	Description   string        `json:"description"`
+	StartPrice    float64       `json:"start_price"`
+	CurrentBid    float64       `json:"current_bid"`
+	HighestBidder string        `json:"highest_bidder"`
+	BidCount      int           `json:"bid_count"`
+	Status        auctionStatus `json:"status"` // see lifecycle.go
+	StartTime     time.Time     `json:"start_time"`
+	EndTime       time.Time     `json:"end_time"`
+
+	Format string         `json:"format"` // english, dutch, sealed or vickrey; see formats.go
+	Dutch  *DutchSchedule `json:"dutch,omitempty"`
+
+	SoftClose       *SoftClose `json:"soft_close,omitempty"`
+	ExtendedSeconds int        `json:"extended_seconds,omitempty"` // total added by soft close so far
+
+	// The reserve is the seller's hidden minimum: an auction whose bidding
+	// stops short of it ends unsold. Clients only see that there is one and
+	// whether it has been met.
+	HasReserve   bool `json:"has_reserve,omitempty"`
+	ReserveMet   bool `json:"reserve_met,omitempty"`
+	reservePrice float64
+
+	// BuyNowPrice ends the auction at once for whoever takes it. It is
+	// offered until the bidding reaches it.
+	BuyNowPrice float64 `json:"buy_now_price,omitempty"`
+
+	// Increments replaces defaultIncrements and MaxBidAmount lowers
+	// MAX_BID_AMOUNT for this auction. NextMinimumBid is the least the
+	// next bid can be, kept current by refreshMinimum; 0 once it closes.
+	Increments     []IncrementTier `json:"increments,omitempty"`
+	MaxBidAmount   float64         `json:"max_bid_amount,omitempty"`
+	NextMinimumBid float64         `json:"next_minimum_bid,omitempty"`
+
+	// proxies are the bidders' private maximums, and sealedBids the bids
+	// on a sealed or Vickrey auction. Being unexported keeps them out of
+	// every message; only the store writes them.
+	proxies    []proxyBid
+	sealedBids []Bid
+}
+
+// SoftClose stops sniping: a bid accepted in the last WindowSeconds pushes
+// the end back by ExtendSeconds, until the auction has been extended by
+// MaxExtensionSeconds in total. A MaxExtensionSeconds of 0 sets no cap.
+type SoftClose struct {
+	WindowSeconds       int `json:"window_seconds"`
+	ExtendSeconds       int `json:"extend_seconds"`
+	MaxExtensionSeconds int `json:"max_extension_seconds"`
 }
 
 type Bid struct {
+	AuctionID string    `json:"auction_id"`
+	Seq       int       `json:"seq,omitempty"` // 1 for an auction's first bid; 0 if rejected
 	BidderID  string    `json:"bidder_id"`
 	Amount    float64   `json:"amount"`
 	Timestamp time.Time `json:"timestamp"`
+	Proxy     bool      `json:"proxy,omitempty"`   // placed by the server against the bidder's maximum
+	BuyNow    bool      `json:"buy_now,omitempty"` // took the buy-it-now price and ended the auction
+}
+
+// IncrementTier is a row of an increment table: bids on an auction whose
+// current bid is below Below go up by at least Increment. Rows run from
+// the lowest price up, and the last row applies to every price above the
+// one before it, whatever its Below.
+type IncrementTier struct {
+	Below     float64 `json:"below,omitempty"`
+	Increment float64 `json:"increment"`
+}
+
+// BidEvent is one entry in an auction's timeline: an accepted bid, or one
+// that was turned down and why. IDs come from the store, increase in the
+// order bids arrive and are shared by all auctions.
+type BidEvent struct {
+	ID int64 `json:"id"`
+	Bid
+	Accepted bool   `json:"accepted"`
+	Reason   string `json:"reason,omitempty"`
 }
 
 type Message struct {
-	Type    string     `json:"type"`
-	Auction *Auction   `json:"auction,omitempty"`
-	Bid     *Bid       `json:"bid,omitempty"`
-	Error   string     `json:"error,omitempty"`
+	Type       string     `json:"type"`
+	RequestID  string     `json:"request_id,omitempty"`
+	Auction    *Auction   `json:"auction,omitempty"`
+	Bid        *Bid       `json:"bid,omitempty"`
+	History    []BidEvent `json:"history,omitempty"`
+	NextBefore int64      `json:"next_before,omitempty"`
+	MaxBid     float64    `json:"max_bid,omitempty"` // only ever sent to its bidder
+	Error      string     `json:"error,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+}
+
+// ClientMessage is what a browser sends. Bidder and timestamp are assigned
+// by the server, so only the auction and amount are read from a bid.
+// AuctionID names the auction for history and buy_now requests; Limit and
+// Before are for history.
+type ClientMessage struct {
+	Type      string `json:"type"`
+	RequestID string `json:"request_id"`
+	Bid       *struct {
+		AuctionID string  `json:"auction_id"`
+		Amount    float64 `json:"amount"`
+	} `json:"bid"`
+	AuctionID string `json:"auction_id"`
+	Limit     int    `json:"limit"`
+	Before    int64  `json:"before"`
 }
 
 type AuctionSubmission struct {
 	Name         string  `json:"name"`
 	Description  string  `json:"description"`
 	StartPrice   float64 `json:"startPrice"`
+	ReservePrice float64 `json:"reservePrice,omitempty"`
+	BuyNowPrice  float64 `json:"buyNowPrice,omitempty"`
 	Duration     int     `json:"duration"`
 	Email        string  `json:"email"`
 	Timestamp    string  `json:"timestamp"`
@@ -51,7 +151,7 @@ type AuctionSubmission struct {
 // ============ GLOBAL STATE ============
 var (
 	upgrader = websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool { return true },
+		CheckOrigin:      corsPolicy.CheckOrigin,
 		HandshakeTimeout: 10 * time.Second,
 		ReadBufferSize:   1024,
 		WriteBufferSize:  1024,
@@ -59,12 +159,8 @@ var (
 
 	auctions      = make(map[string]*Auction)
 	auctionMutex  sync.RWMutex
+	store         Store
 
-	clients       = make(map[*Client]bool)
-	clientsMutex  sync.RWMutex
-
-	broadcast     = make(chan Message, 256)
-	
 	startTime     = time.Now()
 	
 	// WebSocket ping/pong settings
@@ -73,100 +169,196 @@ var (
 	writeWait = 10 * time.Second
 )
 
-type Client struct {
-	conn *websocket.Conn
-	id   string
-}
-
-// ============ INITIALIZATION ============
-func init() {
-	now := time.Now()
-
-	// Active auction 1
-	auctions["auction-1"] = &Auction{
-		ID:            "auction-1",
-		Title:         "Vintage Camera Collection",
-		Description:   "1960s Leica M3 and accessories - Excellent condition",
-		StartPrice:    100,
-		CurrentBid:    850,
-		HighestBidder: "bidder_42",
-		BidCount:      24,
-		Status:        "active",
-		StartTime:     now.Add(-5 * time.Minute),
-		EndTime:       now.Add(5 * time.Minute),
-	}
-
-	// Active auction 2
-	auctions["auction-2"] = &Auction{
-		ID:            "auction-2",
-		Title:         "Modern Art Painting",
-		Description:   "Oil on canvas by emerging artist - 100x80cm",
-		StartPrice:    200,
-		CurrentBid:    2500,
-		HighestBidder: "bidder_elite",
-		BidCount:      47,
-		Status:        "active",
-		StartTime:     now.Add(-10 * time.Minute),
-		EndTime:       now.Add(2 * time.Minute),
-	}
-
-	// Scheduled auction
-	auctions["auction-3"] = &Auction{
-		ID:          "auction-3",
-		Title:       "Rare Vinyl Records",
-		Description: "Limited edition Beatles pressings - Mint condition",
-		StartPrice:  50,
-		CurrentBid:  50,
-		Status:      "scheduled",
-		StartTime:   now.Add(30 * time.Minute),
-		EndTime:     now.Add(60 * time.Minute),
-	}
-
-	// Ended auction
-	auctions["auction-4"] = &Auction{
-		ID:            "auction-4",
-		Title:         "Antique Watch",
-		Description:   "Swiss-made pocket watch - 1940s",
-		StartPrice:    150,
-		CurrentBid:    3200,
-		HighestBidder: "bidder_collector",
-		BidCount:      62,
-		Status:        "ended",
-		StartTime:     now.Add(-25 * time.Minute),
-		EndTime:       now.Add(-5 * time.Minute),
-	}
-}
-
-// ============ FRONTEND PATH DETECTION ============
-func getFrontendPath() string {
-	// Try different paths based on where the binary is run from
-	paths := []string{
-		"./frontend/dist",           // Running from Auctmah/
-		"./Auctmah/frontend/dist",   // Running from repo root
+
+// ============ DEMO DATA ============
+// demoAuctions is the showcase data saved on first start with -seed-demo:
+// two live auctions, one scheduled and one finished. The first has a
+// reserve it has not reached yet, the second a buy-it-now price and its
+// own increments and cap, and the scheduled one a reserve and buy-it-now.
+// Two more show the Dutch and Vickrey formats.
+func demoAuctions(now time.Time) []*Auction {
+	return []*Auction{
+		// Active auction 1
+		{
+			ID:            "auction-1",
+			Title:         "Vintage Camera Collection",
+			Description:   "1960s Leica M3 and accessories - Excellent condition",
+			StartPrice:    100,
+			CurrentBid:    850,
+			HighestBidder: "bidder_42",
+			BidCount:      24,
+			Status:        auctionActive,
+			Format:        "english",
+			StartTime:     now.Add(-5 * time.Minute),
+			EndTime:       now.Add(5 * time.Minute),
+			SoftClose:     &SoftClose{WindowSeconds: 30, ExtendSeconds: 30, MaxExtensionSeconds: 300},
+			HasReserve:    true,
+			reservePrice:  1000,
+		},
+		// Active auction 2
+		{
+			ID:            "auction-2",
+			Title:         "Modern Art Painting",
+			Description:   "Oil on canvas by emerging artist - 100x80cm",
+			StartPrice:    200,
+			CurrentBid:    2500,
+			HighestBidder: "bidder_elite",
+			BidCount:      47,
+			Status:        auctionActive,
+			Format:        "english",
+			StartTime:     now.Add(-10 * time.Minute),
+			EndTime:       now.Add(2 * time.Minute),
+			SoftClose:     &SoftClose{WindowSeconds: 60, ExtendSeconds: 60, MaxExtensionSeconds: 600},
+			BuyNowPrice:   4000,
+			Increments:    []IncrementTier{{Below: 5000, Increment: 25}, {Increment: 100}},
+			MaxBidAmount:  50000,
+		},
+		// Scheduled auction
+		{
+			ID:           "auction-3",
+			Title:        "Rare Vinyl Records",
+			Description:  "Limited edition Beatles pressings - Mint condition",
+			StartPrice:   50,
+			CurrentBid:   50,
+			Status:       auctionScheduled,
+			Format:       "english",
+			StartTime:    now.Add(30 * time.Minute),
+			EndTime:      now.Add(60 * time.Minute),
+			SoftClose:    &SoftClose{WindowSeconds: 30, ExtendSeconds: 30, MaxExtensionSeconds: 300},
+			HasReserve:   true,
+			reservePrice: 200,
+			BuyNowPrice:  500,
+		},
+		// Ended auction
+		{
+			ID:            "auction-4",
+			Title:         "Antique Watch",
+			Description:   "Swiss-made pocket watch - 1940s",
+			StartPrice:    150,
+			CurrentBid:    3200,
+			HighestBidder: "bidder_collector",
+			BidCount:      62,
+			Status:        auctionEnded,
+			Format:        "english",
+			StartTime:     now.Add(-25 * time.Minute),
+			EndTime:       now.Add(-5 * time.Minute),
+		},
+		// Dutch auction: the price falls until someone takes it
+		{
+			ID:          "auction-5",
+			Title:       "Mid-Century Armchair",
+			Description: "Teak frame, reupholstered in green wool",
+			StartPrice:  1200,
+			CurrentBid:  1200,
+			Status:      auctionActive,
+			Format:      "dutch",
+			Dutch:       &DutchSchedule{DropAmount: 25, DropEverySeconds: 10, FloorPrice: 400},
+			StartTime:   now.Add(-1 * time.Minute),
+			EndTime:     now.Add(10 * time.Minute),
+		},
+		// Vickrey auction: sealed bids, the winner pays the runner-up's bid
+		{
+			ID:          "auction-6",
+			Title:       "First Edition Novel",
+			Description: "Signed 1951 first printing with dust jacket",
+			StartPrice:  300,
+			CurrentBid:  300,
+			Status:      auctionActive,
+			Format:      "vickrey",
+			StartTime:   now.Add(-2 * time.Minute),
+			EndTime:     now.Add(8 * time.Minute),
+		},
 	}
-	
-	for _, path := range paths {
-		if _, err := os.Stat(path); err == nil {
-			fmt.Printf("📂 Serving frontend from: %s\n", path)
-			return path
+}
+
+// loadAuctions fills auctions from the store. With seedDemo an empty store
+// is given the demo auctions first; a store that already has auctions is
+// left alone, so restarting with the flag keeps live bidding.
+func loadAuctions(seedDemo bool) error {
+	saved, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if len(saved) == 0 && seedDemo {
+		saved = demoAuctions(time.Now())
+		for _, auction := range saved {
+			if err := store.SaveAuction(*auction); err != nil {
+				return err
+			}
 		}
+		slog.Info("seeded demo auctions", "auctions", len(saved))
 	}
-	
-	// Default to first path
-	fmt.Printf("⚠️  Frontend path not found, using default: ./frontend/dist\n")
-	return "./frontend/dist"
+	for _, auction := range saved {
+		if !auction.Status.known() {
+			return fmt.Errorf("auction %s has unknown status %q", auction.ID, auction.Status)
+		}
+		auction.refreshMinimum()
+		auctions[auction.ID] = auction
+	}
+	return nil
+}
+
+// ============ FRONTEND ============
+// The built frontend (index.html plus the wasm-pack output) is embedded so
+// the binary serves it from any working directory. build-auctmah.sh fills
+// frontend/dist before go build; the committed .gitkeep keeps the embed
+// valid in a fresh checkout.
+//
+//go:embed all:frontend/dist
+var embeddedFrontend embed.FS
+
+// frontendHandler serves the embedded frontend, or dir when it is set. A
+// directory is re-read as files change, for frontend work without a
+// rebuild.
+func frontendHandler(dir string) (http.Handler, error) {
+	if dir != "" {
+		slog.Info("serving frontend from disk", "path", dir)
+		return assets.New(os.DirFS(dir), assets.Options{Dev: true})
+	}
+	dist, err := fs.Sub(embeddedFrontend, "frontend/dist")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(dist, "index.html"); err != nil {
+		slog.Warn("no frontend embedded; run build-auctmah.sh before go build or pass -frontend-dir")
+	}
+	return assets.New(dist, assets.Options{})
 }
 
 // ============ MAIN ============
 func main() {
+	frontendDir := flag.String("frontend-dir", os.Getenv("FRONTEND_DIR"), "serve the frontend from this directory instead of the embedded copy")
+	dataDir := flag.String("data-dir", envOr("DATA_DIR", "data"), "directory for saved auctions and bids")
+	storeBackend := flag.String("store", envOr("STORE", "file"), "where auctions are saved: file, or sqlite in a binary built with -tags sqlite")
+	seedDemo := flag.Bool("seed-demo", os.Getenv("SEED_DEMO") == "true", "save the demo auctions if the store is empty")
+	flag.Parse()
+
+	if _, err := logging.Setup(logging.OptionsFromEnv("auctmah")); err != nil {
+		log.Fatal(err)
+	}
+
+	var err error
+	store, err = openStore(*storeBackend, *dataDir)
+	if err != nil {
+		logging.Fatal("failed to open store", "store", *storeBackend, "dir", *dataDir, "err", err)
+	}
+	if err := loadAuctions(*seedDemo); err != nil {
+		logging.Fatal("failed to load auctions", "store", *storeBackend, "dir", *dataDir, "err", err)
+	}
+	slog.Info("loaded auctions", "store", *storeBackend, "dir", *dataDir, "auctions", len(auctions))
+
 	http.HandleFunc("/ws", handleWebSocket)
-	http.HandleFunc("/api/health", handleHealth)
-	http.HandleFunc("/api/auctions", handleAuctions)
-	http.HandleFunc("/api/bid", handlePlaceBid)
-	http.HandleFunc("/api/create-auction", handleCreateAuction)
-	http.Handle("/", http.FileServer(http.Dir(getFrontendPath())))
+	router.Mount(http.DefaultServeMux, "/api/v1", routesV1, router.Options{
+		Aliases: []router.Alias{{Prefix: "/api", Deprecated: legacyAPIDeprecated, Sunset: legacyAPISunset}},
+	})
+	http.Handle("/metrics", registry.Handler())
+	frontend, err := frontendHandler(*frontendDir)
+	if err != nil {
+		logging.Fatal("failed to load frontend", "err", err)
+	}
+	http.Handle("/", frontend)
 
-	go broadcastMessages()
+	go hub.run()
 	go updateAuctionTimers()
 
 	port := os.Getenv("PORT")
@@ -174,86 +366,123 @@ func main() {
 		port = "8080"
 	}
 
-	fmt.Printf("🔨 Auctmah Server Starting\n")
-	fmt.Printf("📡 WebSocket: ws://localhost:%s/ws\n", port)
-	fmt.Printf("🎯 Live Auctions: http://localhost:%s\n", port)
-	fmt.Printf("⚡ Rust+WASM Frontend: High-speed canvas rendering\n")
+	slog.Info("Auctmah server starting", "websocket", "ws://localhost:"+port+"/ws", "site", "http://localhost:"+port)
 
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatal(err)
+	err = serve.Run(serve.Options{
+		Name:       "Auctmah server",
+		Addr:       ":" + port,
+		Handler:    logging.Middleware(httpMetrics.Instrument(http.DefaultServeMux, corsPolicy.Middleware(http.DefaultServeMux))),
+		OnShutdown: hub.Close,
+		Cleanup:    closeStore,
+	})
+	if err != nil {
+		logging.Fatal("server exited", "err", err)
 	}
 }
 
+// closeStore runs once requests have drained; taking auctionMutex lets a
+// bid that is being saved finish first.
+func closeStore(context.Context) error {
+	auctionMutex.Lock()
+	defer auctionMutex.Unlock()
+	return store.Close()
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
 // ============ WEBSOCKET HANDLER ============
 func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println("WebSocket upgrade error:", err)
+		logging.FromContext(r.Context()).Warn("websocket upgrade failed", "err", err)
 		return
 	}
 
 	client := &Client{
-		conn: conn,
-		id:   fmt.Sprintf("bidder_%d", time.Now().UnixNano()),
+		conn:  conn,
+		id:    fmt.Sprintf("bidder_%d", time.Now().UnixNano()),
+		queue: make(chan []byte, sendQueueSize),
 	}
+	logger := logging.FromContext(r.Context()).With("client", client.id)
 
-	clientsMutex.Lock()
-	clients[client] = true
-	totalClients := len(clients)
-	clientsMutex.Unlock()
-
-	log.Printf("✅ Client connected: %s (total: %d)\n", client.id, totalClients)
-
-	// Send all existing auctions to new client
+	// Queue the current auctions before the hub knows the client, so the
+	// snapshot is the first thing it receives. The client is registered
+	// before the lock is released: every change is broadcast under the
+	// write lock, so none can fall between the snapshot and registration.
 	auctionMutex.RLock()
-	auctionCount := len(auctions)
-	sentCount := 0
 	for _, auction := range auctions {
-		msg := Message{
-			Type:    "auction_update",
-			Auction: auction,
+		if auction.Status == auctionDraft {
+			continue
 		}
-		if err := client.conn.WriteJSON(msg); err != nil {
-			log.Printf("❌ Failed to send auction %s to client: %v\n", auction.ID, err)
-		} else {
-			sentCount++
+		data, err := json.Marshal(Message{Type: "auction_update", Auction: auction})
+		if err != nil {
+			logger.Warn("failed to encode auction for client", "auction", auction.ID, "err", err)
+			continue
+		}
+		select {
+		case client.queue <- data:
+		default:
+			logger.Warn("snapshot larger than the send queue", "auctions", len(auctions))
 		}
 	}
+	hub.pumps.Add(1)
+	hub.register <- client
 	auctionMutex.RUnlock()
-	
-	log.Printf("📤 Sent %d/%d existing auctions to client %s\n", sentCount, auctionCount, client.id)
 
-	// Broadcast updated client count to all clients
-	clientCountMsg := Message{
-		Type: "client_count_update",
-	}
-	broadcast <- clientCountMsg
-
-	// Start ping/pong handlers for keeping connection alive
 	go writePump(client)
 	go readMessages(client)
 }
 
+// ============ API ROUTES ============
+
+// The unversioned /api paths are kept, deprecated, for pages cached before
+// /api/v1 existed.
+var (
+	legacyAPIDeprecated = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	legacyAPISunset     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+func routesV1(g *router.Group) {
+	g = g.With(apiHeaders)
+	g.Handle("GET /openapi.json", apiDocument().Handler())
+	g.HandleFunc("GET /health", handleHealth)
+	g.HandleFunc("GET /auctions", handleAuctions)
+	g.HandleFunc("GET /auctions/{id}/bids", handleBidHistory)
+	g.HandleFunc("POST /create-auction", handleCreateAuction)
+	g.HandleFunc("POST /auctions/{id}/settle", handleCloseAuction(auctionSettled))
+	g.HandleFunc("POST /auctions/{id}/cancel", handleCloseAuction(auctionCancelled))
+}
+
+// apiHeaders marks every API response as JSON.
+func apiHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsPolicy lists the other sites allowed to call the API or open the
+// WebSocket; the bundled frontend is same-origin and needs no entry.
+var corsPolicy = cors.New(cors.Options{
+	AllowedOrigins:   cors.ParseOrigins(os.Getenv("CORS_ORIGINS")),
+	AllowCredentials: os.Getenv("CORS_ALLOW_CREDENTIALS") == "true",
+})
+
 // ============ HEALTH CHECK HANDLER ============
 func handleHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 
-	if r.Method == http.MethodOptions {
-		w.Header().Set("Access-Control-Allow-Methods", "GET")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		return
-	}
-
 	// Check system health
 	auctionMutex.RLock()
 	auctionCount := len(auctions)
 	auctionMutex.RUnlock()
 
-	clientsMutex.RLock()
-	clientCount := len(clients)
-	clientsMutex.RUnlock()
+	clientCount := hub.Count()
 
 	uptime := time.Since(startTime)
 
@@ -273,11 +502,8 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 // ============ MESSAGE READER ============
 func readMessages(client *Client) {
 	defer func() {
-		clientsMutex.Lock()
-		delete(clients, client)
-		clientsMutex.Unlock()
+		hub.unregister <- client
 		client.conn.Close()
-		log.Printf("❌ Client disconnected: %s (total: %d)\n", client.id, len(clients))
 	}()
 
 	client.conn.SetReadDeadline(time.Now().Add(pongWait))
@@ -287,21 +513,37 @@ func readMessages(client *Client) {
 	})
 
 	for {
-		var msg Message
-		err := client.conn.ReadJSON(&msg)
+		_, data, err := client.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+				slog.Warn("websocket error", "client", client.id, "err", err)
 			}
 			break
 		}
+		var msg ClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			hub.Send(client, Message{Type: "error", Error: "Message is not valid JSON."})
+			continue
+		}
 
 		// Handle different message types
 		switch msg.Type {
 		case "place_bid":
-			if msg.Bid != nil {
-				processBid(msg.Bid, client.id)
+			if msg.Bid == nil {
+				hub.Send(client, Message{Type: "bid_rejected", RequestID: msg.RequestID, Error: "place_bid needs a bid.", Reason: "invalid_bid"})
+				continue
 			}
+			hub.Send(client, processBid(msg.RequestID, msg.Bid.AuctionID, msg.Bid.Amount, client.id))
+		case "set_max_bid":
+			if msg.Bid == nil {
+				hub.Send(client, Message{Type: "bid_rejected", RequestID: msg.RequestID, Error: "set_max_bid needs a bid.", Reason: "invalid_bid"})
+				continue
+			}
+			hub.Send(client, setMaxBid(msg.RequestID, msg.Bid.AuctionID, msg.Bid.Amount, client.id))
+		case "buy_now":
+			hub.Send(client, buyNow(msg.RequestID, msg.AuctionID, client.id))
+		case "history":
+			hub.Send(client, historyReply(msg))
 		case "pong":
 			// Client responded to ping
 			client.conn.SetReadDeadline(time.Now().Add(pongWait))
@@ -309,124 +551,183 @@ func readMessages(client *Client) {
 	}
 }
 
-// ============ MESSAGE WRITER (PING/PONG HANDLER) ============
-func writePump(client *Client) {
-	ticker := time.NewTicker(pingPeriod)
-	defer func() {
-		ticker.Stop()
-		client.conn.Close()
-	}()
+// ============ BID PROCESSING ============
+const MAX_BID_AMOUNT = 10000000.0 // $10 million; an auction's MaxBidAmount can only lower it
 
-	for {
-		select {
-		case <-ticker.C:
-			client.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := client.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
-			}
+// defaultIncrements is the increment table for auctions without their own.
+var defaultIncrements = []IncrementTier{
+	{Below: 100, Increment: 1},
+	{Below: 1000, Increment: 10},
+	{Increment: 50},
+}
+
+// incrementAt looks price up in tiers, returning 0 for an empty table.
+func incrementAt(tiers []IncrementTier, price float64) float64 {
+	for i, t := range tiers {
+		if price < t.Below || i == len(tiers)-1 {
+			return t.Increment
 		}
 	}
+	return 0
 }
 
-// ============ BID PROCESSING ============
-const (
-	MAX_BID_AMOUNT = 10000000.0  // $10 million maximum bid
-	MIN_BID_INCREMENT = 1.0       // $1 minimum increment
-)
+// increment is how far the next bid must beat the current one. A row
+// without a positive increment falls back to the default table, so a
+// bad table can never let the price stand still.
+func (a *Auction) increment() float64 {
+	if inc := incrementAt(a.Increments, a.CurrentBid); inc > 0 {
+		return inc
+	}
+	return incrementAt(defaultIncrements, a.CurrentBid)
+}
 
-func processBid(bid *Bid, bidderId string) {
-	bid.BidderID = bidderId
-	bid.Timestamp = time.Now()
+// maxBid is the most a single bid on the auction can be.
+func (a *Auction) maxBid() float64 {
+	if a.MaxBidAmount > 0 && a.MaxBidAmount < MAX_BID_AMOUNT {
+		return a.MaxBidAmount
+	}
+	return MAX_BID_AMOUNT
+}
+
+// refreshMinimum sets NextMinimumBid after the price or status changes.
+func (a *Auction) refreshMinimum() {
+	a.NextMinimumBid = 0
+	if a.Status.open() || a.Status == auctionScheduled {
+		a.NextMinimumBid = a.format().minimumBid(a)
+	}
+}
 
+// processBid applies a bid to the auction it names and returns the reply
+// for the bidder: bid_confirmed, or bid_rejected with the reason. Only
+// accepted bids are broadcast, as bid_accepted.
+func processBid(requestID, auctionID string, amount float64, bidderId string) Message {
 	auctionMutex.Lock()
 	defer auctionMutex.Unlock()
 
-	// Validation: Maximum bid limit
-	if bid.Amount > MAX_BID_AMOUNT {
-		msg := Message{
-			Type:  "bid_rejected",
-			Error: fmt.Sprintf("Maximum bid amount is $%s", formatMoney(MAX_BID_AMOUNT)),
+	auction, ok := listedAuction(auctionID)
+
+	// Rejections go into the auction's history too, so its timeline shows
+	// the bids that lost out and why.
+	reject := func(reason, format string, args ...interface{}) Message {
+		bidsRejected.Inc(reason)
+		slog.Info("bid rejected", "reason", reason, "bidder", bidderId, "auction", auctionID, "amount", amount)
+		if ok && reason != "not_saved" {
+			bid := Bid{AuctionID: auctionID, BidderID: bidderId, Amount: amount, Timestamp: time.Now()}
+			if err := store.RecordRejected(bid, reason); err != nil {
+				slog.Warn("failed to record rejected bid", "auction", auctionID, "err", err)
+			}
 		}
-		broadcast <- msg
-		log.Printf("❌ Bid rejected: Amount $%.2f exceeds maximum of $%.2f", bid.Amount, MAX_BID_AMOUNT)
-		return
+		return Message{Type: "bid_rejected", RequestID: requestID, Error: fmt.Sprintf(format, args...), Reason: reason}
 	}
 
-	// Find auction and validate
-	for _, auction := range auctions {
-		// Check if bid is for this auction (you'll need to add auction_id to Bid struct)
-		// For now, we'll process the first matching conditions
-		
-		// CRITICAL FIX #1: Reject bids on ENDED auctions
-		if auction.Status == "ended" {
-			msg := Message{
-				Type:  "bid_rejected",
-				Error: "This auction has ended. Bids are no longer accepted.",
-			}
-			broadcast <- msg
-			log.Printf("❌ Bid rejected: Auction '%s' has ENDED", auction.Title)
-			continue
-		}
+	if amount <= 0 {
+		return reject("invalid_amount", "Bid amount must be greater than zero.")
+	}
+	if !ok {
+		return reject("unknown_auction", "No auction with ID %q.", auctionID)
+	}
+	if amount > auction.maxBid() {
+		return reject("above_maximum", "Maximum bid amount is $%s", formatMoney(auction.maxBid()))
+	}
 
-		// CRITICAL FIX #2: Reject bids on SCHEDULED auctions
-		if auction.Status == "scheduled" {
-			msg := Message{
-				Type:  "bid_rejected",
-				Error: "This auction has not started yet. Please wait until it begins.",
-			}
-			broadcast <- msg
-			log.Printf("❌ Bid rejected: Auction '%s' is SCHEDULED", auction.Title)
-			continue
-		}
+	if reason, text := closedReason(auction); reason != "" {
+		return reject(reason, "%s", text)
+	}
 
-		// Only process bids on ACTIVE auctions
-		if auction.Status == "active" {
-			// CRITICAL FIX #3: Minimum bid increment validation
-			minRequired := auction.CurrentBid + MIN_BID_INCREMENT
-			if bid.Amount < minRequired {
-				msg := Message{
-					Type:  "bid_rejected",
-					Error: fmt.Sprintf("Bid must be at least $%.2f (current bid + $%.2f increment)", minRequired, MIN_BID_INCREMENT),
-				}
-				broadcast <- msg
-				log.Printf("❌ Bid rejected: $%.2f is below minimum required $%.2f", bid.Amount, minRequired)
-				return
-			}
+	price, reason, text := auction.format().checkBid(auction, bidderId, amount)
+	if reason != "" {
+		return reject(reason, "%s", text)
+	}
+
+	bid := Bid{AuctionID: auction.ID, BidderID: bidderId, Amount: price, Timestamp: time.Now()}
+	if err := acceptBid(auction, &bid); err != nil {
+		slog.Error("failed to save bid", "auction", auction.ID, "bidder", bidderId, "err", err)
+		return reject("not_saved", "Your bid could not be saved. Please try again.")
+	}
+	if auction.Status == auctionEnded {
+		// A Dutch auction's first bid wins it.
+		hub.Broadcast(Message{Type: "auction_ended", Auction: auction})
+		slog.Info("auction ended", "auction", auction.ID, "winner", auction.HighestBidder, "price", auction.CurrentBid)
+	}
+	runProxies(auction, "")
 
-			// CRITICAL FIX #4: Ensure price never decreases
-			if bid.Amount > auction.CurrentBid {
-				auction.CurrentBid = bid.Amount
-				auction.HighestBidder = bidderId
-				auction.BidCount++
+	// The reply carries the auction after any maximum bids have answered,
+	// so the bidder can tell whether they still lead.
+	snapshot := *auction
+	return Message{Type: "bid_confirmed", RequestID: requestID, Auction: &snapshot, Bid: &bid}
+}
 
-				msg := Message{
-					Type:    "bid_accepted",
-					Auction: auction,
-					Bid:     bid,
-				}
-				broadcast <- msg
-				log.Printf("💰 Bid accepted: $%.2f by %s on %s (New price: $%.2f)", 
-					bid.Amount, bidderId, auction.Title, auction.CurrentBid)
-				return
-			}
-		}
+// closedReason says why the auction is not taking bids, or returns "" if
+// it is.
+func closedReason(auction *Auction) (reason, text string) {
+	switch {
+	case auction.Status == auctionCancelled:
+		return "auction_cancelled", "This auction was cancelled."
+	case auction.Status.closed(), !time.Now().Before(auction.EndTime):
+		// The timer may not have marked it yet; a bid at or after the end
+		// must not count.
+		return "auction_ended", "This auction has ended. Bids are no longer accepted."
+	case !auction.Status.open():
+		return "auction_scheduled", "This auction has not started yet. Please wait until it begins."
 	}
+	return "", ""
 }
 
-func formatMoney(amount float64) string {
-	return fmt.Sprintf("%,.2f", amount)
+// acceptBid makes bid the auction's leading bid, numbering it, and tells
+// every client: with bid_accepted, or for a sealed bid with just the new
+// count. The bid is saved before it takes effect, so a bidder is never
+// told they lead with a bid a restart would forget; on error nothing has
+// changed.
+func acceptBid(auction *Auction, bid *Bid) error {
+	bid.Seq = auction.BidCount + 1
+	snapshot := *auction
+	extended := snapshot.apply(*bid)
+	if err := store.RecordBid(snapshot, *bid); err != nil {
+		return err
+	}
+	reserveMet := snapshot.ReserveMet && !auction.ReserveMet
+	*auction = snapshot
+	if snapshot.format().sealed() {
+		hub.Broadcast(Message{Type: "sealed_bid_received", Auction: &snapshot})
+	} else {
+		hub.Broadcast(Message{Type: "bid_accepted", Auction: &snapshot, Bid: bid})
+	}
+	if reserveMet {
+		hub.Broadcast(Message{Type: "reserve_met", Auction: &snapshot})
+		slog.Info("reserve met", "auction", auction.ID, "price", snapshot.CurrentBid)
+	}
+	if extended {
+		hub.Broadcast(Message{Type: "auction_extended", Auction: &snapshot})
+		slog.Info("auction extended", "auction", auction.ID, "end_time", snapshot.EndTime, "extended_seconds", snapshot.ExtendedSeconds)
+	}
+	bidsAccepted.Inc()
+	slog.Info("bid accepted", "bidder", bid.BidderID, "auction", auction.ID, "amount", bid.Amount, "proxy", bid.Proxy)
+	return nil
 }
 
-// ============ BROADCAST LOOP ============
-func broadcastMessages() {
-	for {
-		msg := <-broadcast
-		clientsMutex.RLock()
-		for client := range clients {
-			client.send(msg)
-		}
-		clientsMutex.RUnlock()
+// apply records b on the auction, as its format does, and reports whether
+// soft close moved the end time. It depends only on the auction and the
+// bid, so replaying a bid at startup extends (or ends) the auction exactly
+// as the live bid did.
+func (a *Auction) apply(b Bid) (extended bool) {
+	a.BidCount = b.Seq
+	extended = a.format().apply(a, b)
+	a.refreshMinimum()
+	return extended
+}
+
+// formatMoney renders amount with thousands separators, e.g. 10,000,000.00.
+func formatMoney(amount float64) string {
+	s := fmt.Sprintf("%.2f", amount)
+	whole, cents := s[:len(s)-3], s[len(s)-3:]
+	sign := ""
+	if strings.HasPrefix(whole, "-") {
+		sign, whole = "-", whole[1:]
+	}
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
 	}
+	return sign + whole + cents
 }
 
 // ============ AUCTION TIMER ============
@@ -438,17 +739,42 @@ func updateAuctionTimers() {
 		auctionMutex.Lock()
 		now := time.Now()
 		for _, auction := range auctions {
-			if auction.Status == "active" && now.After(auction.EndTime) {
-				auction.Status = "ended"
-				msg := Message{
-					Type:    "auction_ended",
-					Auction: auction,
+			if auction.Status == auctionScheduled && !now.Before(auction.StartTime) {
+				startAuction(auction, now)
+				continue
+			}
+			if !auction.Status.open() {
+				continue
+			}
+			if !now.After(auction.EndTime) {
+				if auction.format().tick(auction, now) {
+					auction.refreshMinimum()
+					hub.Broadcast(Message{Type: "price_dropped", Auction: auction})
 				}
-				auctionMutex.Unlock()
-				broadcast <- msg
-				auctionMutex.Lock()
-				log.Printf("🏁 Auction ended: %s (Winner: %s at $%.2f)", auction.Title, auction.HighestBidder, auction.CurrentBid)
+				continue
+			}
+
+			// Settle a copy and keep it only once it is saved, as bids
+			// are; an auction that fails to save stays open past its end,
+			// refusing bids, and the next tick tries again.
+			settled := *auction
+			settled.format().settle(&settled)
+			settled.refreshMinimum()
+			if err := store.SaveAuction(settled); err != nil {
+				slog.Error("failed to save ended auction", "auction", auction.ID, "err", err)
+				continue
 			}
+			*auction = settled
+			if auction.Status == auctionUnsold {
+				hub.Broadcast(Message{Type: "auction_unsold", Auction: auction})
+				slog.Info("auction ended unsold", "auction", auction.ID, "price", auction.CurrentBid)
+				continue
+			}
+			hub.Broadcast(Message{
+				Type:    "auction_ended",
+				Auction: auction,
+			})
+			slog.Info("auction ended", "auction", auction.ID, "winner", auction.HighestBidder, "price", auction.CurrentBid)
 		}
 		auctionMutex.Unlock()
 	}
@@ -456,79 +782,189 @@ func updateAuctionTimers() {
 
 // ============ HTTP HANDLERS ============
 func handleAuctions(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
 	auctionMutex.RLock()
 	defer auctionMutex.RUnlock()
 
 	var auctionList []*Auction
 	for _, auction := range auctions {
-		auctionList = append(auctionList, auction)
+		if auction.Status != auctionDraft {
+			auctionList = append(auctionList, auction)
+		}
 	}
 	json.NewEncoder(w).Encode(auctionList)
 }
 
-func handlePlaceBid(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+// ============ BID HISTORY ============
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+var errUnknownAuction = errors.New("unknown auction")
 
-	if r.Method == http.MethodOptions {
-		w.Header().Set("Access-Control-Allow-Methods", "POST")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		return
+// bidHistory returns the auction and a page of its bid events, newest
+// first, starting below the event ID before (0 for the newest). next is
+// the before value for the following page, or 0 on the last one.
+func bidHistory(auctionID string, before int64, limit int) (auction Auction, events []BidEvent, next int64, err error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
 	}
 
-	var bid Bid
-	json.NewDecoder(r.Body).Decode(&bid)
+	auctionMutex.RLock()
+	defer auctionMutex.RUnlock()
 
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]string{"status": "bid received"})
+	a, ok := listedAuction(auctionID)
+	if !ok {
+		return Auction{}, nil, 0, errUnknownAuction
+	}
+	// One extra event says whether another page follows.
+	events, err = store.History(auctionID, before, limit+1)
+	if err != nil {
+		return Auction{}, nil, 0, err
+	}
+	if len(events) > limit {
+		events = events[:limit]
+		next = events[limit-1].ID
+	}
+	if reason, _ := closedReason(a); reason != "auction_ended" && a.format().sealed() {
+		// Sealed amounts stay secret until the close. The page is a
+		// copy, so this leaves the store's events alone.
+		for i := range events {
+			events[i].Amount = 0
+		}
+	}
+	return *a, events, next, nil
 }
 
-// ============ CREATE AUCTION HANDLER ============
-func handleCreateAuction(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+// handleBidHistory serves GET /auctions/{id}/bids?limit=&before=.
+func handleBidHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var limit int
+	var before int64
+	var err error
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			http.Error(w, "Invalid request: limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("before"); v != "" {
+		if before, err = strconv.ParseInt(v, 10, 64); err != nil || before < 1 {
+			http.Error(w, "Invalid request: before must be an event id from next_before", http.StatusBadRequest)
+			return
+		}
+	}
 
-	if r.Method == http.MethodOptions {
-		w.Header().Set("Access-Control-Allow-Methods", "POST")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	_, events, next, err := bidHistory(r.PathValue("id"), before, limit)
+	if errors.Is(err, errUnknownAuction) {
+		http.Error(w, "Auction not found", http.StatusNotFound)
 		return
 	}
-
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("failed to read bid history", "auction", r.PathValue("id"), "err", err)
+		http.Error(w, "Server error", http.StatusInternalServerError)
 		return
 	}
+	if events == nil {
+		events = []BidEvent{}
+	}
+	page := struct {
+		Bids       []BidEvent `json:"bids"`
+		NextBefore int64      `json:"next_before,omitempty"`
+	}{events, next}
+	json.NewEncoder(w).Encode(page)
+}
+
+// adminToken guards the routes for whoever runs the site. They are refused
+// while it is unset.
+var adminToken = os.Getenv("ADMIN_TOKEN")
+
+// requireAdmin checks the ?token= admin credential, writing the refusal
+// when it does not match.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	switch {
+	case adminToken == "":
+		http.Error(w, "Admin routes are disabled; set ADMIN_TOKEN to use them", http.StatusForbidden)
+	case subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(adminToken)) != 1:
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	default:
+		return true
+	}
+	return false
+}
+
+// handleCloseAuction settles or cancels the auction named in the path; see
+// closeAuction.
+func handleCloseAuction(to auctionStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAdmin(w, r) {
+			return
+		}
+		auction, err := closeAuction(r.PathValue("id"), to)
+		switch {
+		case errors.Is(err, errUnknownAuction):
+			http.Error(w, "Auction not found", http.StatusNotFound)
+			return
+		case errors.Is(err, errStatusChange):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			logging.FromContext(r.Context()).Error("failed to save auction", "auction", r.PathValue("id"), "status", to, "err", err)
+			http.Error(w, "Server error", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(auction)
+	}
+}
+
+// historyReply answers a WebSocket history request with the same page the
+// HTTP route would return, plus the auction as it stands.
+func historyReply(msg ClientMessage) Message {
+	auction, events, next, err := bidHistory(msg.AuctionID, msg.Before, msg.Limit)
+	switch {
+	case errors.Is(err, errUnknownAuction):
+		return Message{Type: "error", RequestID: msg.RequestID, Error: fmt.Sprintf("No auction with ID %q.", msg.AuctionID), Reason: "unknown_auction"}
+	case err != nil:
+		slog.Error("failed to read bid history", "auction", msg.AuctionID, "err", err)
+		return Message{Type: "error", RequestID: msg.RequestID, Error: "Bid history is unavailable right now."}
+	}
+	return Message{Type: "history", RequestID: msg.RequestID, Auction: &auction, History: events, NextBefore: next}
+}
 
+// ============ CREATE AUCTION HANDLER ============
+func handleCreateAuction(w http.ResponseWriter, r *http.Request) {
 	var submission AuctionSubmission
-	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, auctionSubmissionBody, &submission) {
 		return
 	}
-
-	// Simple validation
-	if submission.Name == "" || submission.Description == "" || submission.StartPrice <= 0 || submission.Email == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+	if problem := submission.priceProblem(); problem != "" {
+		http.Error(w, "Invalid request: "+problem, http.StatusBadRequest)
 		return
 	}
 
 	// Save to file (simple persistence)
 	file, err := os.OpenFile("auction_submissions.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		log.Println("Error opening submissions file:", err)
+		logging.FromContext(r.Context()).Error("failed to open submissions file", "err", err)
 		http.Error(w, "Server error", http.StatusInternalServerError)
 		return
 	}
 	defer file.Close()
 
 	submissionData, _ := json.Marshal(submission)
-	file.Write(submissionData)
-	file.WriteString("\n")
+	start := time.Now()
+	_, err = file.Write(append(submissionData, '\n'))
+	storeWriteSeconds.Observe(metrics.Since(start), "submissions")
+	if err != nil {
+		logging.FromContext(r.Context()).Error("failed to write submission", "err", err)
+		http.Error(w, "Server error", http.StatusInternalServerError)
+		return
+	}
 
-	log.Printf("📝 New auction submission: %s (Starting price: $%.2f, Email: %s)", 
-		submission.Name, submission.StartPrice, submission.Email)
+	logging.FromContext(r.Context()).Info("auction submission received", "name", submission.Name, "start_price", submission.StartPrice, "email", submission.Email)
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{
@@ -537,7 +973,15 @@ func handleCreateAuction(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// ============ CLIENT HELPERS ============
-func (c *Client) send(msg Message) {
-	c.conn.WriteJSON(msg)
+// priceProblem checks the optional prices against each other, which the
+// schema cannot: a reserve below the start price would always be met, and
+// a buy-it-now price must be worth more than bidding to the reserve.
+func (s AuctionSubmission) priceProblem() string {
+	switch {
+	case s.ReservePrice > 0 && s.ReservePrice < s.StartPrice:
+		return "reservePrice must be at least startPrice"
+	case s.BuyNowPrice > 0 && s.BuyNowPrice <= max(s.StartPrice, s.ReservePrice):
+		return "buyNowPrice must be above startPrice and reservePrice"
+	}
+	return ""
 }
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// useAuctions swaps in a fresh file store, hub and auction table holding
+// list, and puts the old ones back when the test ends. Nothing runs the
+// hub, so broadcasts wait in its channels for the test to read.
+func useAuctions(t *testing.T, list ...*Auction) *Hub {
+	t.Helper()
+	s, err := openFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	savedStore, savedHub, savedAuctions := store, hub, auctions
+	t.Cleanup(func() { store, hub, auctions = savedStore, savedHub, savedAuctions })
+	store, hub, auctions = s, newHub(), make(map[string]*Auction, len(list))
+	for _, a := range list {
+		a.refreshMinimum()
+		auctions[a.ID] = a
+	}
+	return hub
+}
+
+// broadcasts drains the messages h has been asked to send to everyone.
+func broadcasts(t *testing.T, h *Hub) []Message {
+	t.Helper()
+	var msgs []Message
+	for {
+		select {
+		case data := <-h.broadcast:
+			var msg Message
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatal(err)
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func TestProcessBid(t *testing.T) {
+	now := time.Now()
+	open := func(id string) *Auction {
+		return &Auction{ID: id, Format: "english", Status: auctionActive, StartPrice: 100, CurrentBid: 100, StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour), MaxBidAmount: 5000}
+	}
+	closed := func(id string, status auctionStatus) *Auction {
+		a := open(id)
+		a.Status = status
+		return a
+	}
+	overdue := open("overdue")
+	overdue.EndTime = now.Add(-time.Second)
+	scheduled := closed("scheduled", auctionScheduled)
+	scheduled.StartTime = now.Add(time.Hour)
+	scheduled.EndTime = now.Add(2 * time.Hour)
+
+	tests := []struct {
+		name       string
+		auctionID  string
+		amount     float64
+		wantType   string
+		wantReason string
+		wantLogged bool
+	}{
+		{name: "accepted", auctionID: "a", amount: 110, wantType: "bid_confirmed"},
+		{name: "routed by auction ID", auctionID: "b", amount: 150, wantType: "bid_confirmed"},
+		{name: "zero", auctionID: "a", amount: 0, wantType: "bid_rejected", wantReason: "invalid_amount", wantLogged: true},
+		{name: "negative", auctionID: "a", amount: -5, wantType: "bid_rejected", wantReason: "invalid_amount", wantLogged: true},
+		{name: "no such auction", auctionID: "nope", amount: 150, wantType: "bid_rejected", wantReason: "unknown_auction"},
+		{name: "drafts are not listed", auctionID: "draft", amount: 150, wantType: "bid_rejected", wantReason: "unknown_auction"},
+		{name: "over the auction's maximum", auctionID: "a", amount: 5000.01, wantType: "bid_rejected", wantReason: "above_maximum", wantLogged: true},
+		{name: "below the increment", auctionID: "a", amount: 109.99, wantType: "bid_rejected", wantReason: "below_minimum", wantLogged: true},
+		{name: "cancelled", auctionID: "cancelled", amount: 150, wantType: "bid_rejected", wantReason: "auction_cancelled", wantLogged: true},
+		{name: "ended", auctionID: "ended", amount: 150, wantType: "bid_rejected", wantReason: "auction_ended", wantLogged: true},
+		{name: "past its end time", auctionID: "overdue", amount: 150, wantType: "bid_rejected", wantReason: "auction_ended", wantLogged: true},
+		{name: "not started", auctionID: "scheduled", amount: 150, wantType: "bid_rejected", wantReason: "auction_scheduled", wantLogged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := useAuctions(t, open("a"), open("b"), closed("draft", auctionDraft), closed("cancelled", auctionCancelled), closed("ended", auctionEnded), overdue, scheduled)
+
+			reply := processBid("req-1", tt.auctionID, tt.amount, "bidder_1")
+			if reply.Type != tt.wantType || reply.Reason != tt.wantReason || reply.RequestID != "req-1" {
+				t.Fatalf("reply %+v, want %s %q for req-1", reply, tt.wantType, tt.wantReason)
+			}
+
+			sent := broadcasts(t, h)
+			if tt.wantType == "bid_confirmed" {
+				if reply.Auction == nil || reply.Auction.ID != tt.auctionID || reply.Bid == nil || reply.Bid.AuctionID != tt.auctionID || reply.Bid.Seq != 1 {
+					t.Fatalf("confirmation %+v does not name auction %s", reply, tt.auctionID)
+				}
+				for id, a := range auctions {
+					if want := id == tt.auctionID; (a.HighestBidder == "bidder_1") != want {
+						t.Fatalf("auction %s led by %q after a bid on %s", id, a.HighestBidder, tt.auctionID)
+					}
+				}
+				if len(sent) != 1 || sent[0].Type != "bid_accepted" || sent[0].Auction.ID != tt.auctionID || sent[0].Auction.CurrentBid != tt.amount {
+					t.Fatalf("broadcasts %+v, want one bid_accepted for %s", sent, tt.auctionID)
+				}
+				return
+			}
+			if len(sent) != 0 {
+				t.Fatalf("a rejected bid was broadcast: %+v", sent)
+			}
+
+			events, err := store.History(tt.auctionID, 0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if logged := len(events) == 1 && !events[0].Accepted && events[0].Reason == tt.wantReason; logged != tt.wantLogged {
+				t.Fatalf("history %+v, want the rejection logged: %v", events, tt.wantLogged)
+			}
+		})
+	}
+}
+
+func TestBidHistoryPages(t *testing.T) {
+	now := time.Now()
+	a := &Auction{ID: "a", Format: "english", Status: auctionActive, StartPrice: 100, CurrentBid: 100, StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)}
+	b := &Auction{ID: "b", Format: "sealed", Status: auctionActive, StartPrice: 100, CurrentBid: 100, StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)}
+	useAuctions(t, a, b)
+
+	// Events 1-7 on a (2 and 5 rejected) and 8 on b share one sequence of IDs.
+	for _, bid := range []struct {
+		auction string
+		amount  float64
+	}{{"a", 110}, {"a", 50}, {"b", 500}, {"a", 120}, {"a", 125}, {"a", 130}, {"a", 140}, {"b", 600}} {
+		processBid("", bid.auction, bid.amount, "bidder_1")
+	}
+
+	ids := func(events []BidEvent) []int64 {
+		out := make([]int64, len(events))
+		for i, e := range events {
+			out[i] = e.ID
+		}
+		return out
+	}
+	tests := []struct {
+		before   int64
+		limit    int
+		wantIDs  []int64
+		wantNext int64
+	}{
+		{before: 0, limit: 2, wantIDs: []int64{7, 6}, wantNext: 6},
+		{before: 6, limit: 2, wantIDs: []int64{5, 4}, wantNext: 4},
+		{before: 4, limit: 2, wantIDs: []int64{2, 1}},
+		{before: 2, limit: 2, wantIDs: []int64{1}},
+		{before: 1, limit: 2, wantIDs: []int64{}},
+		{before: 0, limit: 6, wantIDs: []int64{7, 6, 5, 4, 2, 1}},
+		{before: 0, limit: 0, wantIDs: []int64{7, 6, 5, 4, 2, 1}},
+		{before: 100, limit: 3, wantIDs: []int64{7, 6, 5}, wantNext: 5},
+	}
+	for _, tt := range tests {
+		_, events, next, err := bidHistory("a", tt.before, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(events); !reflect.DeepEqual(got, tt.wantIDs) || next != tt.wantNext {
+			t.Errorf("before %d, limit %d: IDs %v, next %d; want %v, next %d", tt.before, tt.limit, got, next, tt.wantIDs, tt.wantNext)
+		}
+	}
+
+	_, events, _, err := bidHistory("a", 6, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := events[0]; e.Accepted || e.Reason != "below_minimum" || e.Amount != 125 {
+		t.Errorf("event 5 is %+v, want the rejected bid of 125", e)
+	}
+
+	// Sealed amounts stay hidden until the close.
+	_, events, _, err = bidHistory("b", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(events); !reflect.DeepEqual(got, []int64{8, 3}) || events[0].Amount != 0 || events[1].Amount != 0 {
+		t.Errorf("sealed history %+v, want events 8 and 3 without amounts", events)
+	}
+
+	if _, _, _, err := bidHistory("missing", 0, 10); err != errUnknownAuction {
+		t.Errorf("history of a missing auction: %v", err)
+	}
+}
+
+func TestIncrementAt(t *testing.T) {
+	custom := []IncrementTier{{Below: 50, Increment: 0.5}, {Below: 500, Increment: 5}, {Below: 1, Increment: 25}}
+	tests := []struct {
+		tiers []IncrementTier
+		price float64
+		want  float64
+	}{
+		{defaultIncrements, 0, 1},
+		{defaultIncrements, 99.99, 1},
+		{defaultIncrements, 100, 10},
+		{defaultIncrements, 999.99, 10},
+		{defaultIncrements, 1000, 50},
+		{defaultIncrements, 5_000_000, 50},
+		{custom, 49.99, 0.5},
+		{custom, 50, 5},
+		{custom, 500, 25},
+		{[]IncrementTier{{Increment: 3}}, 1e9, 3},
+		{nil, 100, 0},
+	}
+	for _, tt := range tests {
+		if got := incrementAt(tt.tiers, tt.price); got != tt.want {
+			t.Errorf("incrementAt(%v, %v) = %v, want %v", tt.tiers, tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestRefreshMinimum(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		status auctionStatus
+		price  float64
+		tiers  []IncrementTier
+		want   float64
+	}{
+		{name: "below the first boundary", format: "english", status: auctionActive, price: 99, want: 100},
+		{name: "on the first boundary", format: "english", status: auctionActive, price: 100, want: 110},
+		{name: "below the second boundary", format: "english", status: auctionExtended, price: 990, want: 1000},
+		{name: "on the second boundary", format: "english", status: auctionActive, price: 1000, want: 1050},
+		{name: "scheduled", format: "english", status: auctionScheduled, price: 100, want: 110},
+		{name: "own table", format: "english", status: auctionActive, price: 20, tiers: []IncrementTier{{Below: 20, Increment: 1}, {Increment: 2.5}}, want: 22.5},
+		{name: "own table without an increment", format: "english", status: auctionActive, price: 20, tiers: []IncrementTier{{Increment: 0}}, want: 21},
+		{name: "ended", format: "english", status: auctionEnded, price: 100, want: 0},
+		{name: "cancelled", format: "english", status: auctionCancelled, price: 100, want: 0},
+		{name: "dutch asks its price", format: "dutch", status: auctionActive, price: 100, want: 100},
+		{name: "sealed asks the start price", format: "sealed", status: auctionActive, price: 300, want: 50},
+	}
+	for _, tt := range tests {
+		a := &Auction{Format: tt.format, Status: tt.status, StartPrice: 50, CurrentBid: tt.price, Increments: tt.tiers}
+		a.refreshMinimum()
+		if a.NextMinimumBid != tt.want {
+			t.Errorf("%s: next minimum %v, want %v", tt.name, a.NextMinimumBid, tt.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/metrics"
+)
+
+// ============ METRICS ============
+var (
+	registry    = metrics.NewRegistry()
+	httpMetrics = registry.NewHTTPMetrics()
+
+	bidsAccepted      = registry.NewCounter("auctmah_bids_accepted_total", "Bids accepted.")
+	bidsRejected      = registry.NewCounter("auctmah_bids_rejected_total", "Bids rejected, by reason.", "reason")
+	clientsEvicted    = registry.NewCounter("auctmah_websocket_clients_evicted_total", "WebSocket clients dropped because their send queue filled up.")
+	storeWriteSeconds = registry.NewHistogram("auctmah_store_write_duration_seconds", "Time taken to persist data, by store.", nil, "store")
+)
+
+func init() {
+	registry.NewGaugeFunc("auctmah_websocket_clients", "Connected WebSocket clients.", nil, func(emit func(float64, ...string)) {
+		emit(float64(hub.Count()))
+	})
+	registry.NewGaugeFunc("auctmah_broadcast_queue_depth", "Messages waiting in the broadcast queue.", nil, func(emit func(float64, ...string)) {
+		emit(float64(hub.QueueDepth()))
+	})
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/openapi"
+)
+
+// ============ API CONTRACT ============
+// The request schemas below drive both validation in the handlers and the
+// document served at /api/v1/openapi.json. Limits match the seller form.
+var (
+	auctionSubmissionBody = openapi.Object(map[string]*openapi.Schema{
+		"name":         openapi.String().Len(3, 100),
+		"description":  openapi.String().Len(20, 500),
+		"startPrice":   openapi.Number().Above(0).AtMost(10_000_000),
+		"reservePrice": openapi.Number().AtLeast(0).AtMost(10_000_000).Describe("Hidden minimum; bidding that ends below it sells nothing. At least startPrice."),
+		"buyNowPrice":  openapi.Number().AtLeast(0).AtMost(10_000_000).Describe("Price that ends the auction at once. Above startPrice and reservePrice."),
+		"duration":     openapi.Integer().AtLeast(1).AtMost(30).Describe("Auction length in days."),
+		"email":        openapi.String().Len(1, 254).WithFormat(openapi.FormatEmail),
+		"timestamp":    openapi.String().WithFormat(openapi.FormatDateTime).Describe("When the seller submitted the form."),
+	}, "name", "description", "startPrice", "email")
+)
+
+// decodeRequest reads a JSON body into dst if it matches schema, otherwise
+// writes a plain-text 400 naming each bad field; the site shows that text
+// in its error toast.
+func decodeRequest(w http.ResponseWriter, r *http.Request, schema *openapi.Schema, dst interface{}) bool {
+	fields, err := openapi.Decode(r.Body, schema, dst)
+	if errors.Is(err, openapi.ErrBodyTooLarge) {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+	if len(fields) > 0 {
+		problems := make([]string, len(fields))
+		for i, f := range fields {
+			problems[i] = f.Field + " " + f.Message
+		}
+		http.Error(w, "Invalid request: "+strings.Join(problems, "; "), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func apiDocument() *openapi.Document {
+	doc := openapi.New("Auctmah API", "1.0.0",
+		"Live auctions. Bids are placed over the WebSocket at /ws; the HTTP API lists auctions and takes seller submissions. "+
+			"String fields are trimmed before validation.")
+	doc.Servers = []openapi.Server{{URL: "/api/v1"}}
+	doc.SecurityScheme("adminToken", openapi.SecurityScheme{
+		Type:        "apiKey",
+		In:          "query",
+		Name:        "token",
+		Description: "The server's ADMIN_TOKEN. Admin routes are refused while none is set.",
+	})
+
+	auctionRef := doc.Schema("Auction", openapi.Object(map[string]*openapi.Schema{
+		"id":             openapi.String(),
+		"title":          openapi.String(),
+		"description":    openapi.String(),
+		"start_price":    openapi.Number(),
+		"current_bid":    openapi.Number(),
+		"highest_bidder": openapi.String(),
+		"bid_count":      openapi.Integer(),
+		"status":         openapi.String().OneOf("scheduled", "active", "extended", "ended", "unsold", "settled", "cancelled").Describe("extended: still open, with end_time moved back by soft close. unsold: ended without a sale, with no bids or bidding below the reserve. Drafts are never listed."),
+		"start_time":     openapi.String().WithFormat(openapi.FormatDateTime),
+		"end_time":       openapi.String().WithFormat(openapi.FormatDateTime).Describe("Moves later when soft close extends the auction."),
+		"soft_close": openapi.Object(map[string]*openapi.Schema{
+			"window_seconds":        openapi.Integer().Describe("A bid accepted this close to the end extends the auction."),
+			"extend_seconds":        openapi.Integer(),
+			"max_extension_seconds": openapi.Integer().Describe("Total extension allowed; 0 extends for as long as bids keep coming."),
+		}).Describe("Absent when the auction ends at a fixed time."),
+		"extended_seconds": openapi.Integer().Describe("How far soft close has pushed end_time back so far."),
+		"format":           openapi.String().OneOf("english", "dutch", "sealed", "vickrey").Describe("How bids work and who wins; sealed and vickrey keep bid amounts secret until the close."),
+		"dutch": openapi.Object(map[string]*openapi.Schema{
+			"drop_amount":        openapi.Number(),
+			"drop_every_seconds": openapi.Integer(),
+			"floor_price":        openapi.Number(),
+		}).Describe("A Dutch auction's price falls from start_price by drop_amount every drop_every_seconds, down to floor_price."),
+		"has_reserve":   openapi.Boolean().Describe("The seller set a reserve; its amount is never shown."),
+		"reserve_met":   openapi.Boolean().Describe("The bidding has reached the reserve."),
+		"buy_now_price": openapi.Number().Describe("Buys the auction outright; offered while current_bid is below it."),
+		"increments": openapi.Array(openapi.Object(map[string]*openapi.Schema{
+			"below":     openapi.Number().Describe("The tier covers current bids under this; absent on the last tier."),
+			"increment": openapi.Number(),
+		})).Describe("The auction's own increment table, lowest tier first. Absent when it uses the default."),
+		"max_bid_amount":   openapi.Number().Describe("The largest bid the auction takes, when lower than the site-wide limit."),
+		"next_minimum_bid": openapi.Number().Describe("The least the next bid can be. Absent once the auction has closed."),
+	}))
+	bidEventRef := doc.Schema("BidEvent", openapi.Object(map[string]*openapi.Schema{
+		"id":         openapi.Integer().Describe("Increases in the order bids arrive; pass it as before to page back from here."),
+		"auction_id": openapi.String(),
+		"seq":        openapi.Integer().Describe("The bid's position among the auction's accepted bids. Absent on rejected bids."),
+		"bidder_id":  openapi.String(),
+		"amount":     openapi.Number(),
+		"timestamp":  openapi.String().WithFormat(openapi.FormatDateTime),
+		"accepted":   openapi.Boolean(),
+		"reason":     openapi.String().Describe("Why a rejected bid was turned down, as in bid_rejected."),
+		"proxy":      openapi.Boolean().Describe("The server placed the bid for a bidder's maximum."),
+		"buy_now":    openapi.Boolean().Describe("The bid took the buy-it-now price."),
+	}))
+	invalid := openapi.Status("The body was not JSON or failed validation; the plain-text body names each bad field.")
+
+	doc.Add(http.MethodGet, "/health", &openapi.Operation{
+		Summary: "Service health",
+		Responses: map[string]openapi.Response{
+			"200": openapi.JSON("Healthy.", openapi.Object(map[string]*openapi.Schema{
+				"status":         openapi.String(),
+				"timestamp":      openapi.Integer(),
+				"uptime_seconds": openapi.Integer(),
+				"auctions":       openapi.Integer(),
+				"active_clients": openapi.Integer(),
+				"version":        openapi.String(),
+			})),
+		},
+	})
+	doc.Add(http.MethodGet, "/auctions", &openapi.Operation{
+		Summary:   "List auctions",
+		Responses: map[string]openapi.Response{"200": openapi.JSON("Every auction, in no particular order.", openapi.Array(auctionRef))},
+	})
+	doc.Add(http.MethodGet, "/auctions/{id}/bids", &openapi.Operation{
+		Summary:     "Bid history",
+		Description: "Every accepted and rejected bid on the auction, newest first. On an open sealed or Vickrey auction the amounts are 0.",
+		Parameters: []openapi.Parameter{
+			{Name: "id", In: "path", Required: true, Schema: openapi.String()},
+			{Name: "limit", In: "query", Description: "Page size, 1 to 500; defaults to 50.", Schema: openapi.Integer().AtLeast(1)},
+			{Name: "before", In: "query", Description: "Return events older than this ID: the next_before of the previous page.", Schema: openapi.Integer().AtLeast(1)},
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.JSON("A page of events.", openapi.Object(map[string]*openapi.Schema{
+				"bids":        openapi.Array(bidEventRef),
+				"next_before": openapi.Integer().Describe("Present when older events remain."),
+			}, "bids")),
+			"400": openapi.Status("limit or before is not a positive integer."),
+			"404": openapi.Status("No auction has that ID."),
+		},
+	})
+	closeAuction := func(summary, description string) *openapi.Operation {
+		return &openapi.Operation{
+			Summary:     summary,
+			Description: description,
+			Security:    []map[string][]string{{"adminToken": {}}},
+			Parameters:  []openapi.Parameter{{Name: "id", In: "path", Required: true, Schema: openapi.String()}},
+			Responses: map[string]openapi.Response{
+				"200": openapi.JSON("The auction as saved.", auctionRef),
+				"401": openapi.Status("The token is missing or wrong."),
+				"403": openapi.Status("No ADMIN_TOKEN is set."),
+				"404": openapi.Status("No auction has that ID."),
+				"409": openapi.Status("The auction's status does not allow it; the plain-text body says why."),
+			},
+		}
+	}
+	doc.Add(http.MethodPost, "/auctions/{id}/settle", closeAuction("Settle a sale",
+		"Marks an ended auction settled once it is paid for and handed over, and broadcasts auction_settled."))
+	doc.Add(http.MethodPost, "/auctions/{id}/cancel", closeAuction("Cancel an auction",
+		"Cancels an auction that has not closed, refusing further bids with auction_cancelled, and broadcasts auction_cancelled. Drafts are cancelled without a broadcast."))
+	doc.Add(http.MethodPost, "/create-auction", &openapi.Operation{
+		Summary:     "Submit an item for auction",
+		RequestBody: openapi.JSONBody(auctionSubmissionBody),
+		Responses: map[string]openapi.Response{
+			"201": openapi.JSON("Submission received.", openapi.Object(map[string]*openapi.Schema{
+				"status":  openapi.String(),
+				"message": openapi.String(),
+			})),
+			"400": invalid,
+			"500": openapi.Status("The submission could not be saved."),
+		},
+	})
+	doc.Add(http.MethodGet, "/ws", &openapi.Operation{
+		Summary: "Live auction WebSocket",
+		Servers: []openapi.Server{{URL: "/", Description: "The WebSocket is not versioned."}},
+		Description: "Upgrade to a WebSocket. The server pushes auction_update, client_count_update, auction_started, bid_accepted, sealed_bid_received, price_dropped, reserve_met, auction_extended, auction_bought, auction_ended, auction_unsold, auction_settled and auction_cancelled messages to everyone; " +
+			"clients send place_bid with a request_id and bid.auction_id, and only the bidder gets the bid_confirmed or bid_rejected reply echoing that request_id. " +
+			"set_max_bid, shaped like place_bid, leaves a private maximum the server bids up to; it is answered with max_bid_set or max_bid_exceeded, and a maximum passed later sends its owner max_bid_exceeded. " +
+			"buy_now with a request_id and auction_id buys the auction at its buy_now_price and is answered with buy_now_confirmed or bid_rejected. " +
+			"A history request with auction_id, and optionally limit and before, is answered with the same page as GET /api/v1/auctions/{id}/bids.",
+		Responses: map[string]openapi.Response{"101": openapi.Status("Switching to the WebSocket protocol.")},
+	})
+
+	return doc
+}
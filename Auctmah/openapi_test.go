@@ -0,0 +1,30 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/router"
+)
+
+// The WebSocket is served outside the versioned API, and documented with
+// its own server URL.
+var unversionedRoutes = []string{"GET /ws"}
+
+// TestRoutesMatchDocument keeps the published API description and the
+// mounted routes in step. The document does not describe itself.
+func TestRoutesMatchDocument(t *testing.T) {
+	var mounted []string
+	for _, route := range router.Routes(routesV1) {
+		if route != "GET /openapi.json" {
+			mounted = append(mounted, route)
+		}
+	}
+	mounted = append(mounted, unversionedRoutes...)
+	sort.Strings(mounted)
+
+	if documented := apiDocument().Routes(); !reflect.DeepEqual(mounted, documented) {
+		t.Fatalf("mounted routes\n  %v\ndo not match the document\n  %v", mounted, documented)
+	}
+}
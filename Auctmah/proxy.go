@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ============ PROXY BIDDING ============
+// A bidder can leave a private maximum on an auction and walk away. Each
+// time the price moves, the server bids for them, one increment over
+// whoever else is bidding, until their maximum is reached. When two
+// maximums meet, the higher one wins at one increment over the lower, or
+// at its own maximum if that is less; on a tie the bidder who was already
+// leading keeps the lead. A maximum that reaches the auction's reserve
+// bids the reserve straight away, since the seller will sell there. Only
+// the resulting price is ever shown.
+
+type proxyBid struct {
+	BidderID string    `json:"bidder_id"`
+	Max      float64   `json:"max"`
+	Since    time.Time `json:"since"`
+}
+
+// proxyFor returns the bidder's maximum on the auction, or 0.
+func (a *Auction) proxyFor(bidderID string) float64 {
+	for _, p := range a.proxies {
+		if p.BidderID == bidderID {
+			return p.Max
+		}
+	}
+	return 0
+}
+
+// setProxy records p, replacing the bidder's earlier maximum. It builds a
+// new slice, since copies of the auction held by the store share the old
+// one.
+func (a *Auction) setProxy(p proxyBid) {
+	proxies := make([]proxyBid, 0, len(a.proxies)+1)
+	for _, existing := range a.proxies {
+		if existing.BidderID != p.BidderID {
+			proxies = append(proxies, existing)
+		}
+	}
+	a.proxies = append(proxies, p)
+}
+
+// setMaxBid records a bidder's maximum on an auction and lets it bid at
+// once if someone else leads. The reply goes to that bidder alone and is
+// max_bid_set, or max_bid_exceeded if another maximum already beats it.
+func setMaxBid(requestID, auctionID string, maxBid float64, bidderID string) Message {
+	auctionMutex.Lock()
+	defer auctionMutex.Unlock()
+
+	reject := func(reason, format string, args ...interface{}) Message {
+		bidsRejected.Inc(reason)
+		slog.Info("maximum bid rejected", "reason", reason, "bidder", bidderID, "auction", auctionID)
+		return Message{Type: "bid_rejected", RequestID: requestID, Error: fmt.Sprintf(format, args...), Reason: reason}
+	}
+
+	if maxBid <= 0 {
+		return reject("invalid_amount", "Maximum bid must be greater than zero.")
+	}
+	auction, ok := listedAuction(auctionID)
+	if !ok {
+		return reject("unknown_auction", "No auction with ID %q.", auctionID)
+	}
+	if maxBid > auction.maxBid() {
+		return reject("above_maximum", "Maximum bid amount is $%s", formatMoney(auction.maxBid()))
+	}
+	if reason, text := closedReason(auction); reason != "" {
+		return reject(reason, "%s", text)
+	}
+	if !auction.ascending() {
+		return reject("unsupported_format", "Automatic bidding is only available on English auctions.")
+	}
+
+	// The leader can raise their maximum without moving the price; anyone
+	// else has to be able to outbid the current price.
+	if auction.HighestBidder == bidderID {
+		if floor := max(auction.CurrentBid, auction.proxyFor(bidderID)); maxBid <= floor {
+			return reject("below_minimum", "You already lead; a new maximum must be above $%.2f.", floor)
+		}
+	} else if minRequired := auction.NextMinimumBid; maxBid < minRequired {
+		return reject("below_minimum", "Maximum bid must be at least $%.2f (current bid + $%.2f increment)", minRequired, auction.increment())
+	}
+
+	previous := auction.proxies
+	auction.setProxy(proxyBid{BidderID: bidderID, Max: maxBid, Since: time.Now()})
+	if err := store.SaveAuction(*auction); err != nil {
+		auction.proxies = previous
+		slog.Error("failed to save maximum bid", "auction", auction.ID, "bidder", bidderID, "err", err)
+		return reject("not_saved", "Your maximum bid could not be saved. Please try again.")
+	}
+	slog.Info("maximum bid set", "auction", auction.ID, "bidder", bidderID)
+	passed := runProxies(auction, bidderID)
+
+	snapshot := *auction
+	if passed {
+		return Message{Type: "max_bid_exceeded", RequestID: requestID, Auction: &snapshot, MaxBid: maxBid}
+	}
+	return Message{Type: "max_bid_set", RequestID: requestID, Auction: &snapshot, MaxBid: maxBid}
+}
+
+// runProxies lets the maximums answer the auction's price until none can
+// beat it, then drops the ones that have been passed, telling their
+// bidders with max_bid_exceeded. The requester, who is about to get a
+// reply anyway, is not told; instead runProxies reports whether their
+// maximum was passed. Call it after anything that moves the price or adds
+// a maximum, with auctionMutex held.
+func runProxies(auction *Auction, requester string) (requesterPassed bool) {
+	if len(auction.proxies) == 0 {
+		return false
+	}
+
+loop:
+	for {
+		if reason, _ := closedReason(auction); reason != "" {
+			break
+		}
+		leaderMax := max(auction.CurrentBid, auction.proxyFor(auction.HighestBidder))
+		belowReserve := auction.HasReserve && !auction.ReserveMet
+		inc := auction.increment()
+
+		bid := Bid{AuctionID: auction.ID, Timestamp: time.Now(), Proxy: true}
+		var bidderMax float64
+		challenger, ok := bestChallenger(auction)
+		switch {
+		case ok && challenger.Max >= auction.CurrentBid+inc && challenger.Max > leaderMax:
+			bid.BidderID, bid.Amount, bidderMax = challenger.BidderID, min(challenger.Max, leaderMax+inc), challenger.Max
+		case ok && challenger.Max >= auction.CurrentBid+inc:
+			// The leader's maximum holds and rises just enough to stay
+			// ahead, which leaves the challenger's maximum passed.
+			bid.BidderID, bid.Amount, bidderMax = auction.HighestBidder, min(leaderMax, challenger.Max+inc), leaderMax
+		case belowReserve && leaderMax >= auction.reservePrice:
+			// Unchallenged, but the leader's maximum covers the reserve.
+			bid.BidderID, bid.Amount, bidderMax = auction.HighestBidder, auction.reservePrice, leaderMax
+		default:
+			break loop
+		}
+		if belowReserve && bid.Amount < auction.reservePrice && bidderMax >= auction.reservePrice {
+			bid.Amount = auction.reservePrice
+		}
+		if err := acceptBid(auction, &bid); err != nil {
+			slog.Error("failed to save automatic bid", "auction", auction.ID, "bidder", bid.BidderID, "err", err)
+			break
+		}
+	}
+
+	kept := make([]proxyBid, 0, len(auction.proxies))
+	var passed []proxyBid
+	for _, p := range auction.proxies {
+		if p.BidderID != auction.HighestBidder && p.Max < auction.NextMinimumBid {
+			passed = append(passed, p)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if len(passed) == 0 {
+		return false
+	}
+	auction.proxies = kept
+	if err := store.SaveAuction(*auction); err != nil {
+		// The passed maximums are dropped again after the next bid.
+		slog.Warn("failed to save dropped maximum bids", "auction", auction.ID, "err", err)
+	}
+	snapshot := *auction
+	for _, p := range passed {
+		if p.BidderID == requester {
+			requesterPassed = true
+			continue
+		}
+		hub.SendToBidder(p.BidderID, Message{Type: "max_bid_exceeded", Auction: &snapshot, MaxBid: p.Max})
+	}
+	return requesterPassed
+}
+
+// bestChallenger is the highest maximum held by someone other than the
+// leader, the earliest one on a tie.
+func bestChallenger(auction *Auction) (proxyBid, bool) {
+	var best proxyBid
+	found := false
+	for _, p := range auction.proxies {
+		if p.BidderID == auction.HighestBidder {
+			continue
+		}
+		if !found || p.Max > best.Max || (p.Max == best.Max && p.Since.Before(best.Since)) {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
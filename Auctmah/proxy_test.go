@@ -0,0 +1,88 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRunProxies(t *testing.T) {
+	now := time.Now()
+	proxy := func(bidder string, max float64, age time.Duration) proxyBid {
+		return proxyBid{BidderID: bidder, Max: max, Since: now.Add(-age)}
+	}
+
+	tests := []struct {
+		name       string
+		leader     string
+		price      float64
+		reserve    float64
+		proxies    []proxyBid
+		requester  string
+		wantLeader string
+		wantPrice  float64
+		wantKept   []string
+		wantPassed bool
+	}{
+		{name: "lone maximum opens the bidding", price: 100, proxies: []proxyBid{proxy("ada", 200, time.Minute)},
+			wantLeader: "ada", wantPrice: 110, wantKept: []string{"ada"}},
+		{name: "higher maximum wins one increment over the lower", price: 100, proxies: []proxyBid{proxy("ada", 300, 2*time.Minute), proxy("bob", 200, time.Minute)},
+			wantLeader: "ada", wantPrice: 210, wantKept: []string{"ada"}},
+		{name: "winner pays its own maximum when that is less", price: 100, proxies: []proxyBid{proxy("ada", 205, 2*time.Minute), proxy("bob", 200, time.Minute)},
+			wantLeader: "ada", wantPrice: 205, wantKept: []string{"ada"}},
+		{name: "tie goes to the earlier maximum", price: 100, proxies: []proxyBid{proxy("bob", 200, time.Minute), proxy("ada", 200, 2*time.Minute)},
+			wantLeader: "ada", wantPrice: 200, wantKept: []string{"ada"}},
+		{name: "tie goes to the leader over an earlier maximum", leader: "ada", price: 150, proxies: []proxyBid{proxy("ada", 200, time.Minute), proxy("bob", 200, 2*time.Minute)}, requester: "bob",
+			wantLeader: "ada", wantPrice: 200, wantKept: []string{"ada"}, wantPassed: true},
+		{name: "maximum outbids a plain leader", leader: "cy", price: 150, proxies: []proxyBid{proxy("ada", 400, time.Minute)},
+			wantLeader: "ada", wantPrice: 160, wantKept: []string{"ada"}},
+		{name: "increment steps up a tier", leader: "cy", price: 990, proxies: []proxyBid{proxy("ada", 2000, time.Minute)},
+			wantLeader: "ada", wantPrice: 1000, wantKept: []string{"ada"}},
+		{name: "too low to bid is passed", leader: "cy", price: 100, proxies: []proxyBid{proxy("ada", 109, time.Minute)}, requester: "ada",
+			wantLeader: "cy", wantPrice: 100, wantKept: []string{}, wantPassed: true},
+		{name: "maximum covering the reserve bids it", price: 100, reserve: 500, proxies: []proxyBid{proxy("ada", 800, time.Minute)},
+			wantLeader: "ada", wantPrice: 500, wantKept: []string{"ada"}},
+		{name: "maximum short of the reserve bids normally", price: 100, reserve: 500, proxies: []proxyBid{proxy("ada", 400, time.Minute)},
+			wantLeader: "ada", wantPrice: 110, wantKept: []string{"ada"}},
+		{name: "leader's maximum lifts it to the reserve", leader: "ada", price: 300, reserve: 500, proxies: []proxyBid{proxy("ada", 600, time.Minute)},
+			wantLeader: "ada", wantPrice: 500, wantKept: []string{"ada"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Auction{
+				ID: "a", Format: "english", Status: auctionActive,
+				StartPrice: 100, CurrentBid: tt.price, HighestBidder: tt.leader,
+				StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour),
+				HasReserve: tt.reserve > 0, reservePrice: tt.reserve,
+				proxies: tt.proxies,
+			}
+			h := useAuctions(t, a)
+
+			passed := runProxies(a, tt.requester)
+			if a.HighestBidder != tt.wantLeader || a.CurrentBid != tt.wantPrice || passed != tt.wantPassed {
+				t.Fatalf("%q leads at %v, requester passed %v; want %q at %v, %v", a.HighestBidder, a.CurrentBid, passed, tt.wantLeader, tt.wantPrice, tt.wantPassed)
+			}
+			kept := []string{}
+			for _, p := range a.proxies {
+				kept = append(kept, p.BidderID)
+			}
+			if !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Fatalf("kept maximums %v, want %v", kept, tt.wantKept)
+			}
+
+			// Passed maximums hear about it, except the requester's, which
+			// is in their reply.
+			told := map[string]bool{}
+			for len(h.direct) > 0 {
+				d := <-h.direct
+				told[d.bidderID] = true
+			}
+			for _, p := range tt.proxies {
+				if want := !slices.Contains(kept, p.BidderID) && p.BidderID != tt.requester; told[p.BidderID] != want {
+					t.Fatalf("%s told they were passed: %v, want %v", p.BidderID, told[p.BidderID], want)
+				}
+			}
+		})
+	}
+}
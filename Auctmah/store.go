@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/atomicfile"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/metrics"
+)
+
+// ============ PERSISTENCE ============
+// Auctions and every accepted bid are saved, so a restart in the middle of
+// an auction resumes with the same price, leader and end time. Each bid
+// carries its position in the auction (Seq); the auction records how many
+// bids it has applied (BidCount), and a backend whose auction record can
+// lag its bid log replays the bids past that count when it loads.
+
+// Store persists auctions and their bids. Writes are serialized by
+// auctionMutex; History runs under its read lock.
+type Store interface {
+	// Load returns every saved auction with all of its bids applied.
+	Load() ([]*Auction, error)
+	// SaveAuction records a new auction or a change outside bidding, such
+	// as its status.
+	SaveAuction(a Auction) error
+	// RecordBid durably appends b, which has been applied to a. An error
+	// means the bid must not be accepted.
+	RecordBid(a Auction, b Bid) error
+	// RecordRejected adds a bid that was turned down to the auction's
+	// history. The bid has no Seq.
+	RecordRejected(b Bid, reason string) error
+	// History returns up to limit of the auction's events with IDs below
+	// before, newest first. before 0 starts from the newest.
+	History(auctionID string, before int64, limit int) ([]BidEvent, error)
+	Close() error
+}
+
+// storeBackends maps a -store name to its constructor, which is given the
+// data directory. Optional backends add themselves from their own files.
+var storeBackends = map[string]func(dir string) (Store, error){
+	"file": openFileStore,
+}
+
+func openStore(backend, dir string) (Store, error) {
+	open, ok := storeBackends[backend]
+	if !ok {
+		if backend == "sqlite" {
+			return nil, errors.New("this binary was built without SQLite; rebuild with -tags sqlite or use -store file")
+		}
+		return nil, fmt.Errorf("unknown store %q; use file or sqlite", backend)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return open(dir)
+}
+
+// savedAuction is an auction as the stores write it: with the reserve
+// price, maximum bids and sealed bids that are never sent to clients.
+type savedAuction struct {
+	Auction
+	ReservePrice float64    `json:"reserve_price,omitempty"`
+	Proxies      []proxyBid `json:"proxies,omitempty"`
+	SealedBids   []Bid      `json:"sealed_bids,omitempty"`
+}
+
+func toSaved(a Auction) savedAuction {
+	return savedAuction{Auction: a, ReservePrice: a.reservePrice, Proxies: a.proxies, SealedBids: a.sealedBids}
+}
+
+// auction restores the hidden fields. Auctions saved before formats
+// existed are English.
+func (s savedAuction) auction() *Auction {
+	a := s.Auction
+	a.reservePrice = s.ReservePrice
+	a.HasReserve = s.ReservePrice > 0
+	a.proxies = s.Proxies
+	a.sealedBids = s.SealedBids
+	if a.Format == "" {
+		a.Format = defaultFormat
+	}
+	return &a
+}
+
+// replayBids applies the bids each auction has not counted yet. Bids must
+// be in the order they were recorded.
+func replayBids(auctions map[string]*Auction, bids []Bid) (replayed int) {
+	for _, b := range bids {
+		a, ok := auctions[b.AuctionID]
+		switch {
+		case !ok:
+			slog.Warn("bid log names an unknown auction", "auction", b.AuctionID, "seq", b.Seq)
+		case b.Seq <= a.BidCount:
+			// Already in the auction record.
+		case b.Seq != a.BidCount+1:
+			slog.Warn("bid log skips a bid", "auction", a.ID, "have", a.BidCount, "seq", b.Seq)
+			fallthrough
+		default:
+			a.apply(b)
+			replayed++
+		}
+	}
+	return replayed
+}
+
+// ============ FILE STORE ============
+// fileStore keeps auctions.json, a snapshot rewritten atomically when an
+// auction is created or changes status, and bids.jsonl, an append-only log
+// with one bid per line that is synced before the bid is accepted. A bid
+// does not rewrite the snapshot, so the snapshot holds the bids up to its
+// last write and Load replays the rest from the log.
+//
+// Rejected bids are logged too, with a "reason" field and no sync. An
+// event's ID is its line number, and the whole log is kept in memory by
+// auction to answer History.
+type fileStore struct {
+	auctionsPath string
+	auctions     map[string]Auction
+	history      map[string][]BidEvent
+	lines        int64
+
+	bids *os.File
+	// size is the length of the log's complete lines. A failed append is
+	// cut back to it so a torn line cannot sit in the middle of the log.
+	size int64
+}
+
+func openFileStore(dir string) (Store, error) {
+	s := &fileStore{
+		auctionsPath: filepath.Join(dir, "auctions.json"),
+		auctions:     make(map[string]Auction),
+		history:      make(map[string][]BidEvent),
+	}
+	bids, err := os.OpenFile(filepath.Join(dir, "bids.jsonl"), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := bids.Stat()
+	if err != nil {
+		bids.Close()
+		return nil, err
+	}
+	s.bids, s.size = bids, info.Size()
+	return s, nil
+}
+
+func (s *fileStore) Load() ([]*Auction, error) {
+	var records []savedAuction
+	data, err := os.ReadFile(s.auctionsPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.auctionsPath, err)
+		}
+	}
+	saved := make([]*Auction, len(records))
+	byID := make(map[string]*Auction, len(saved))
+	for i, r := range records {
+		saved[i] = r.auction()
+		byID[r.ID] = saved[i]
+	}
+
+	events, err := s.readLog()
+	if err != nil {
+		return nil, err
+	}
+	var bids []Bid
+	for _, e := range events {
+		s.history[e.AuctionID] = append(s.history[e.AuctionID], e)
+		if e.Accepted {
+			bids = append(bids, e.Bid)
+		}
+	}
+	if n := replayBids(byID, bids); n > 0 {
+		slog.Info("replayed bids from the log", "bids", n)
+	}
+
+	for _, a := range saved {
+		s.auctions[a.ID] = *a
+	}
+	return saved, nil
+}
+
+// logLine is a line of bids.jsonl: a Bid, plus the reason if it was
+// rejected.
+type logLine struct {
+	Bid
+	Reason string `json:"reason,omitempty"`
+}
+
+// readLog reads the whole log. A final line without its newline is a
+// write cut short by a crash, and that bid was never confirmed; it is cut
+// off so the next bid starts on a line of its own.
+func (s *fileStore) readLog() ([]BidEvent, error) {
+	if _, err := s.bids.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	var events []BidEvent
+	var good int64
+	r := bufio.NewReader(s.bids)
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if err != nil {
+			if len(data) > 0 {
+				slog.Warn("discarding incomplete bid at the end of the log", "line", line, "bytes", len(data))
+				if err := s.bids.Truncate(good); err != nil {
+					return nil, err
+				}
+			}
+			break
+		}
+		var l logLine
+		if err := json.Unmarshal(data, &l); err != nil {
+			return nil, fmt.Errorf("bids.jsonl line %d: %w", line, err)
+		}
+		events = append(events, BidEvent{ID: int64(line), Bid: l.Bid, Accepted: l.Reason == "", Reason: l.Reason})
+		good += int64(len(data))
+		s.lines = int64(line)
+	}
+	s.size = good
+	return events, nil
+}
+
+// SaveAuction rewrites auctions.json with a in it, and only remembers a
+// once the write has succeeded, so a failed save is not written out by the
+// next one.
+func (s *fileStore) SaveAuction(a Auction) error {
+	list := make([]savedAuction, 0, len(s.auctions)+1)
+	for id, saved := range s.auctions {
+		if id != a.ID {
+			list = append(list, toSaved(saved))
+		}
+	}
+	list = append(list, toSaved(a))
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = atomicfile.WriteFile(s.auctionsPath, data, 0o644)
+	storeWriteSeconds.Observe(metrics.Since(start), "auctions")
+	if err != nil {
+		return err
+	}
+	s.auctions[a.ID] = a
+	return nil
+}
+
+func (s *fileStore) RecordBid(a Auction, b Bid) error {
+	if err := s.appendLine(logLine{Bid: b}, true); err != nil {
+		return err
+	}
+	s.auctions[a.ID] = a
+	return nil
+}
+
+func (s *fileStore) RecordRejected(b Bid, reason string) error {
+	return s.appendLine(logLine{Bid: b, Reason: reason}, false)
+}
+
+// appendLine writes l to the log, syncing it first if sync is set, and
+// adds it to the history.
+func (s *fileStore) appendLine(l logLine, sync bool) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	start := time.Now()
+	_, err = s.bids.Write(data)
+	if err == nil && sync {
+		err = s.bids.Sync()
+	}
+	storeWriteSeconds.Observe(metrics.Since(start), "bids")
+	if err != nil {
+		s.bids.Truncate(s.size)
+		return err
+	}
+	s.size += int64(len(data))
+	s.lines++
+	e := BidEvent{ID: s.lines, Bid: l.Bid, Accepted: l.Reason == "", Reason: l.Reason}
+	s.history[l.AuctionID] = append(s.history[l.AuctionID], e)
+	return nil
+}
+
+func (s *fileStore) History(auctionID string, before int64, limit int) ([]BidEvent, error) {
+	events := s.history[auctionID]
+	end := len(events)
+	if before > 0 {
+		end = sort.Search(len(events), func(i int) bool { return events[i].ID >= before })
+	}
+	page := make([]BidEvent, 0, min(limit, end))
+	for i := end - 1; i >= 0 && len(page) < limit; i-- {
+		page = append(page, events[i])
+	}
+	return page, nil
+}
+
+func (s *fileStore) Close() error {
+	return s.bids.Close()
+}
@@ -0,0 +1,206 @@
+//go:build sqlite
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/metrics"
+	_ "modernc.org/sqlite"
+)
+
+// ============ SQLITE STORE ============
+// Built with -tags sqlite and chosen with -store sqlite. The auction row
+// and its new bid are written in one transaction, so nothing needs
+// replaying at load. Auctions are kept as JSON so new fields need no
+// migration; bids get columns so their history can be queried.
+//
+// bid_events holds accepted bids, which have a seq, and rejected ones,
+// which have a reason. Databases from before rejections were recorded
+// have a bids table instead, which is copied over and dropped on open;
+// columns added since (proxy, buy_now) are added to older tables.
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS auctions (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS bid_events (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	auction_id TEXT    NOT NULL,
+	seq        INTEGER,
+	bidder_id  TEXT    NOT NULL,
+	amount     REAL    NOT NULL,
+	placed_at  TEXT    NOT NULL,
+	reason     TEXT    NOT NULL DEFAULT '',
+	proxy      INTEGER NOT NULL DEFAULT 0,
+	buy_now    INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS bid_events_seq ON bid_events (auction_id, seq);
+CREATE INDEX IF NOT EXISTS bid_events_auction ON bid_events (auction_id, id);`
+
+const sqliteMigrateBids = `
+INSERT INTO bid_events (auction_id, seq, bidder_id, amount, placed_at)
+	SELECT auction_id, seq, bidder_id, amount, placed_at FROM bids ORDER BY placed_at, seq;
+DROP TABLE bids;`
+
+func init() {
+	storeBackends["sqlite"] = openSQLiteStore
+}
+
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func openSQLiteStore(dir string) (Store, error) {
+	dsn := filepath.Join(dir, "auctmah.db") + "?_pragma=journal_mode(WAL)&_pragma=synchronous(FULL)&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func migrateSQLite(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return err
+	}
+	for _, column := range []string{"proxy", "buy_now"} {
+		var has int
+		if err := tx.QueryRow(`SELECT count(*) FROM pragma_table_info('bid_events') WHERE name = ?`, column).Scan(&has); err != nil {
+			return err
+		}
+		if has == 0 {
+			if _, err := tx.Exec(`ALTER TABLE bid_events ADD COLUMN ` + column + ` INTEGER NOT NULL DEFAULT 0`); err != nil {
+				return err
+			}
+		}
+	}
+	var oldBids int
+	if err := tx.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'bids'`).Scan(&oldBids); err != nil {
+		return err
+	}
+	if oldBids > 0 {
+		if _, err := tx.Exec(sqliteMigrateBids); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Load() ([]*Auction, error) {
+	rows, err := s.db.Query(`SELECT data FROM auctions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var saved []*Auction
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var r savedAuction
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, err
+		}
+		saved = append(saved, r.auction())
+	}
+	return saved, rows.Err()
+}
+
+func (s *sqliteStore) SaveAuction(a Auction) error {
+	start := time.Now()
+	defer func() { storeWriteSeconds.Observe(metrics.Since(start), "auctions") }()
+	return saveAuctionRow(s.db, a)
+}
+
+func (s *sqliteStore) RecordBid(a Auction, b Bid) error {
+	start := time.Now()
+	defer func() { storeWriteSeconds.Observe(metrics.Since(start), "bids") }()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(`INSERT INTO bid_events (auction_id, seq, bidder_id, amount, placed_at, proxy, buy_now) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		b.AuctionID, b.Seq, b.BidderID, b.Amount, b.Timestamp.UTC().Format(time.RFC3339Nano), b.Proxy, b.BuyNow)
+	if err != nil {
+		return err
+	}
+	if err := saveAuctionRow(tx, a); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) RecordRejected(b Bid, reason string) error {
+	start := time.Now()
+	defer func() { storeWriteSeconds.Observe(metrics.Since(start), "bids") }()
+	_, err := s.db.Exec(`INSERT INTO bid_events (auction_id, bidder_id, amount, placed_at, reason, buy_now) VALUES (?, ?, ?, ?, ?, ?)`,
+		b.AuctionID, b.BidderID, b.Amount, b.Timestamp.UTC().Format(time.RFC3339Nano), reason, b.BuyNow)
+	return err
+}
+
+func (s *sqliteStore) History(auctionID string, before int64, limit int) ([]BidEvent, error) {
+	if before <= 0 {
+		before = math.MaxInt64
+	}
+	rows, err := s.db.Query(`SELECT id, seq, bidder_id, amount, placed_at, reason, proxy, buy_now FROM bid_events
+		WHERE auction_id = ? AND id < ? ORDER BY id DESC LIMIT ?`, auctionID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []BidEvent
+	for rows.Next() {
+		e := BidEvent{Bid: Bid{AuctionID: auctionID}}
+		var seq sql.NullInt64
+		var placedAt string
+		if err := rows.Scan(&e.ID, &seq, &e.BidderID, &e.Amount, &placedAt, &e.Reason, &e.Proxy, &e.BuyNow); err != nil {
+			return nil, err
+		}
+		e.Seq = int(seq.Int64)
+		e.Accepted = e.Reason == ""
+		if e.Timestamp, err = time.Parse(time.RFC3339Nano, placedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// execer is a *sql.DB or *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func saveAuctionRow(db execer, a Auction) error {
+	data, err := json.Marshal(toSaved(a))
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO auctions (id, data) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET data = excluded.data`, a.ID, data)
+	return err
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// reopen closes s and loads its directory again, as a restart would.
+func reopen(t *testing.T, s Store, dir string) (Store, map[string]*Auction) {
+	t.Helper()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, err := openFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	loaded, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]*Auction, len(loaded))
+	for _, a := range loaded {
+		byID[a.ID] = a
+	}
+	return s, byID
+}
+
+func TestFailedSaveIsNotPersistedLater(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := s.(*fileStore)
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	a := Auction{ID: "a", Format: "english", Status: auctionActive, StartPrice: 100, CurrentBid: 100, StartTime: start, EndTime: start.Add(time.Hour)}
+	if err := s.SaveAuction(a); err != nil {
+		t.Fatal(err)
+	}
+
+	// A save that cannot be written must not ride along with the next one.
+	fs.auctionsPath = filepath.Join(dir, "missing", "auctions.json")
+	ended := a
+	ended.Status = auctionEnded
+	if err := s.SaveAuction(ended); err == nil {
+		t.Fatal("save into a missing directory succeeded")
+	}
+	fs.auctionsPath = filepath.Join(dir, "auctions.json")
+	if err := s.SaveAuction(Auction{ID: "b", Format: "english", Status: auctionScheduled, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, loaded := reopen(t, s, dir)
+	if got := loaded["a"]; got == nil || got.Status != auctionActive {
+		t.Fatalf("auction a loaded as %+v, want it still active", got)
+	}
+	if loaded["b"] == nil {
+		t.Fatal("auction b was not saved")
+	}
+}
+
+// record applies and logs a bid as acceptBid does, without saving the
+// auction record, so Load has to replay it from the log.
+func record(t *testing.T, s Store, a *Auction, bidder string, amount float64, at time.Time) {
+	t.Helper()
+	b := Bid{AuctionID: a.ID, Seq: a.BidCount + 1, BidderID: bidder, Amount: amount, Timestamp: at}
+	a.apply(b)
+	if err := s.RecordBid(*a, b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileStoreReplaysTornLog(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	a := &Auction{ID: "a", Format: "english", Status: auctionActive, StartPrice: 100, CurrentBid: 100, StartTime: start, EndTime: start.Add(time.Hour)}
+	if err := s.SaveAuction(*a); err != nil {
+		t.Fatal(err)
+	}
+	record(t, s, a, "ada", 110, start.Add(time.Minute))
+	if err := s.RecordRejected(Bid{AuctionID: "a", BidderID: "bob", Amount: 105, Timestamp: start.Add(2 * time.Minute)}, "below_minimum"); err != nil {
+		t.Fatal(err)
+	}
+	record(t, s, a, "bob", 120, start.Add(3*time.Minute))
+
+	// A crash in the middle of the next append leaves half a line.
+	logPath := filepath.Join(dir, "bids.jsonl")
+	complete, err := os.Stat(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"auction_id":"a","seq":3,"bidder_id":"cy","amou`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	s, loaded := reopen(t, s, dir)
+	got := loaded["a"]
+	if got == nil || got.BidCount != 2 || got.CurrentBid != 120 || got.HighestBidder != "bob" {
+		t.Fatalf("loaded %+v, want bob leading at 120 after 2 bids", got)
+	}
+	info, err := os.Stat(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != complete.Size() {
+		t.Fatalf("log is %d bytes after loading, want the torn line cut back to %d", info.Size(), complete.Size())
+	}
+
+	// The next bid starts a line of its own and survives another restart.
+	record(t, s, got, "cy", 130, start.Add(4*time.Minute))
+	_, loaded = reopen(t, s, dir)
+	if got := loaded["a"]; got.BidCount != 3 || got.CurrentBid != 130 || got.HighestBidder != "cy" {
+		t.Fatalf("after the next bid loaded %+v, want cy leading at 130 after 3 bids", got)
+	}
+}
@@ -27,8 +27,8 @@ cd ..
 go mod download
 go mod tidy
 
-echo " Building Go backend..."
-go build -o app main.go
+echo " Building Go backend (embeds frontend/dist)..."
+go build -o app .
 
 cd ..
 echo "✅ Build complete!"
@@ -1,3 +1,3 @@
 #!/bin/bash
 cd signal-bank-landing
-go build -o app main.go
+go build -o app .
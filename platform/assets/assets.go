@@ -0,0 +1,320 @@
+// Package assets serves a website from an fs.FS, normally an embed.FS
+// compiled into the binary. Every non-HTML file is also reachable under a
+// content-hashed name (app.js as app.3f9c01d2ab7e.js) that is cached for a
+// year, and HTML pages have their references to those files rewritten to
+// the hashed names. Responses carry ETags, and gzip variants are served
+// when the client accepts them.
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	immutable   = "public, max-age=31536000, immutable"
+	revalidate  = "no-cache"
+	hashLen     = 12
+	minCompress = 512
+)
+
+// Options configures a Handler.
+type Options struct {
+	// Dev re-reads the file system whenever a file changes, for serving a
+	// working copy from disk. Leave it off for embedded assets.
+	Dev bool
+}
+
+// Handler serves the files of one file system.
+type Handler struct {
+	fsys fs.FS
+	dev  bool
+
+	mu    sync.RWMutex
+	files map[string]*file
+	stamp string
+}
+
+type file struct {
+	contentType string
+	cache       string
+	etag        string
+	plain       []byte
+	gzip        []byte
+}
+
+// New indexes fsys. Compressible files are gzipped once, here, unless a
+// "name.gz" file next to "name" already holds its gzip encoding.
+func New(fsys fs.FS, opts Options) (*Handler, error) {
+	h := &Handler{fsys: fsys, dev: opts.Dev}
+	stamp, err := h.fingerprint()
+	if err != nil {
+		return nil, err
+	}
+	files, err := build(fsys)
+	if err != nil {
+		return nil, err
+	}
+	h.files, h.stamp = files, stamp
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.dev {
+		if err := h.refresh(); err != nil {
+			http.Error(w, "failed to read assets: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" || strings.HasSuffix(r.URL.Path, "/") {
+		name = path.Join(name, "index.html")
+	}
+
+	h.mu.RLock()
+	f, ok := h.files[name]
+	h.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, etag := f.plain, f.etag
+	header := w.Header()
+	if f.gzip != nil {
+		header.Add("Vary", "Accept-Encoding")
+		if acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			body, etag = f.gzip, strings.TrimSuffix(etag, `"`)+`-gz"`
+			header.Set("Content-Encoding", "gzip")
+		}
+	}
+
+	header.Set("Content-Type", f.contentType)
+	header.Set("Cache-Control", f.cache)
+	header.Set("ETag", etag)
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(body))
+}
+
+// refresh rebuilds the index when any file's size or modification time has
+// changed since the last build.
+func (h *Handler) refresh() error {
+	stamp, err := h.fingerprint()
+	if err != nil {
+		return err
+	}
+	h.mu.RLock()
+	current := stamp == h.stamp
+	h.mu.RUnlock()
+	if current {
+		return nil
+	}
+
+	files, err := build(h.fsys)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	h.files, h.stamp = files, stamp
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *Handler) fingerprint() (string, error) {
+	sum := sha256.New()
+	err := fs.WalkDir(h.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || hidden(p) {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(sum, "%s %d %d\n", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return hex.EncodeToString(sum.Sum(nil)), err
+}
+
+// build reads every file, registers hashed names for everything but HTML,
+// then rewrites the HTML pages to point at those names.
+func build(fsys fs.FS) (map[string]*file, error) {
+	sources := map[string][]byte{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || hidden(p) {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		sources[p] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("assets: %w", err)
+	}
+
+	files := map[string]*file{}
+	hashed := map[string]string{}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if isVariant(name) || isHTML(name) {
+			continue
+		}
+		data := sources[name]
+		hash := contentHash(data)
+		f := newFile(name, data, hash, sources)
+
+		hashedName := withHash(name, hash)
+		hashed[name] = hashedName
+		files[name] = f
+
+		long := *f
+		long.cache = immutable
+		files[hashedName] = &long
+	}
+
+	for _, name := range names {
+		if !isHTML(name) {
+			continue
+		}
+		data := rewrite(name, sources[name], hashed)
+		f := newFile(name, data, contentHash(data), nil)
+		if bytes.Equal(data, sources[name]) {
+			// Untouched pages can still use a precompressed sibling.
+			f = newFile(name, data, contentHash(data), sources)
+		}
+		files[name] = f
+	}
+	return files, nil
+}
+
+func newFile(name string, data []byte, hash string, sources map[string][]byte) *file {
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	f := &file{
+		contentType: contentType,
+		cache:       revalidate,
+		etag:        `"` + hash + `"`,
+		plain:       data,
+	}
+	if sources != nil {
+		f.gzip = sources[name+".gz"]
+	}
+	if f.gzip == nil && compressible(contentType) && len(data) >= minCompress {
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		zw.Write(data)
+		zw.Close()
+		if buf.Len() < len(data) {
+			f.gzip = buf.Bytes()
+		}
+	}
+	return f
+}
+
+// reference matches src and href attributes and the specifiers of inline
+// module imports, which is how wasm-bindgen glue is usually loaded.
+var reference = regexp.MustCompile(`(?i)(?:\b(?:src|href)\s*=\s*|\bfrom\s+)["']([^"'\s]+)["']`)
+
+// rewrite swaps references that name a known asset for its hashed name,
+// keeping the reference relative if it was.
+func rewrite(page string, data []byte, hashed map[string]string) []byte {
+	dir := path.Dir(page)
+	return reference.ReplaceAllFunc(data, func(m []byte) []byte {
+		parts := reference.FindSubmatch(m)
+		ref := string(parts[1])
+		if strings.Contains(ref, ":") || strings.HasPrefix(ref, "//") || strings.ContainsAny(ref, "?#") {
+			return m
+		}
+		target := strings.TrimPrefix(ref, "/")
+		if !strings.HasPrefix(ref, "/") {
+			target = path.Join(dir, ref)
+		}
+		hashedName, ok := hashed[target]
+		if !ok {
+			return m
+		}
+		newRef := ref[:strings.LastIndex(ref, "/")+1] + path.Base(hashedName)
+		return bytes.Replace(m, parts[1], []byte(newRef), 1)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip,
+// by name or by "*", with a weight above zero.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		params = strings.ReplaceAll(params, " ", "")
+		if params == "q=0" || strings.HasPrefix(params, "q=0.0") && strings.Trim(params[4:], "0.") == "" {
+			continue
+		}
+		if coding = strings.ToLower(coding); coding == "gzip" || coding == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:hashLen]
+}
+
+// withHash turns dir/app.js into dir/app.<hash>.js.
+func withHash(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+func isHTML(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".html") || strings.EqualFold(path.Ext(name), ".htm")
+}
+
+// hidden reports dot-files such as .gitkeep, which are never served.
+func hidden(name string) bool {
+	return strings.HasPrefix(path.Base(name), ".")
+}
+
+func isVariant(name string) bool {
+	return strings.HasSuffix(name, ".gz")
+}
+
+func compressible(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/javascript",
+		mediaType == "application/json",
+		mediaType == "application/wasm",
+		mediaType == "image/svg+xml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var (
+	appJS = strings.Repeat("console.log('auction');\n", 40)
+	logo  = []byte("\x89PNG\r\n\x1a\nnot really")
+)
+
+func site() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html": {Data: []byte(`<link href="css/site.css"><script src="/app.js"></script>
+<script type="module">import init from "./app.js";</script>
+<img src="img/logo.png"><a href="https://example.com/app.js">x</a><script src="app.js?v=1"></script><img src="missing.png">`)},
+		"about/index.html": {Data: []byte(`<link href="../css/site.css">`)},
+		"plain.html":       {Data: []byte(`<p>no assets</p>`)},
+		"plain.html.gz":    {Data: gzipped(`<p>no assets</p>`)},
+		"app.js":           {Data: []byte(appJS)},
+		"css/site.css":     {Data: []byte("body{}")},
+		"css/site.css.gz":  {Data: gzipped("body{}")},
+		"img/logo.png":     {Data: logo},
+		".gitkeep":         {},
+		"img/.DS_Store":    {Data: []byte("junk")},
+	}
+}
+
+func gzipped(s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	io.WriteString(zw, s)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func get(t *testing.T, h http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func newHandler(t *testing.T) *Handler {
+	t.Helper()
+	h, err := New(site(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestHashedNames(t *testing.T) {
+	h := newHandler(t)
+	hashedJS := "/app." + contentHash([]byte(appJS)) + ".js"
+	hashedCSS := "/css/site." + contentHash([]byte("body{}")) + ".css"
+
+	tests := []struct {
+		path      string
+		wantCode  int
+		wantCache string
+		wantType  string
+	}{
+		{"/app.js", http.StatusOK, revalidate, "text/javascript; charset=utf-8"},
+		{hashedJS, http.StatusOK, immutable, "text/javascript; charset=utf-8"},
+		{hashedCSS, http.StatusOK, immutable, "text/css; charset=utf-8"},
+		{"/img/logo.png", http.StatusOK, revalidate, "image/png"},
+		{"/", http.StatusOK, revalidate, "text/html; charset=utf-8"},
+		{"/about/", http.StatusOK, revalidate, "text/html; charset=utf-8"},
+		{"/app.000000000000.js", http.StatusNotFound, "", ""},
+		{"/index." + contentHash([]byte("x")) + ".html", http.StatusNotFound, "", ""},
+		{"/css/site.css.gz", http.StatusNotFound, "", ""},
+		{"/.gitkeep", http.StatusNotFound, "", ""},
+		{"/img/.DS_Store", http.StatusNotFound, "", ""},
+		{"/../app.js", http.StatusOK, revalidate, "text/javascript; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		rec := get(t, h, tt.path)
+		if rec.Code != tt.wantCode {
+			t.Errorf("GET %s: %d, want %d", tt.path, rec.Code, tt.wantCode)
+			continue
+		}
+		if tt.wantCode != http.StatusOK {
+			continue
+		}
+		if got := rec.Header().Get("Cache-Control"); got != tt.wantCache {
+			t.Errorf("GET %s: Cache-Control %q, want %q", tt.path, got, tt.wantCache)
+		}
+		if got := rec.Header().Get("Content-Type"); got != tt.wantType {
+			t.Errorf("GET %s: Content-Type %q, want %q", tt.path, got, tt.wantType)
+		}
+	}
+
+	if body := get(t, h, hashedJS).Body.String(); body != appJS {
+		t.Errorf("hashed name serves %q", body)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	h := newHandler(t)
+	js := "app." + contentHash([]byte(appJS)) + ".js"
+	css := "site." + contentHash([]byte("body{}")) + ".css"
+	png := "logo." + contentHash(logo) + ".png"
+
+	page := get(t, h, "/").Body.String()
+	for _, want := range []string{
+		`href="css/` + css + `"`,
+		`src="/` + js + `"`,
+		`from "./` + js + `"`,
+		`src="img/` + png + `"`,
+		`href="https://example.com/app.js"`,
+		`src="app.js?v=1"`,
+		`src="missing.png"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("index.html lacks %s:\n%s", want, page)
+		}
+	}
+	if about := get(t, h, "/about/index.html").Body.String(); about != `<link href="../css/`+css+`">` {
+		t.Errorf("about/index.html is %s", about)
+	}
+
+	// The page's ETag follows the rewritten content, so it changes with
+	// the assets it names.
+	if got, want := get(t, h, "/").Header().Get("ETag"), `"`+contentHash([]byte(page))+`"`; got != want {
+		t.Errorf("index.html ETag %s, want %s", got, want)
+	}
+}
+
+func TestETag(t *testing.T) {
+	h := newHandler(t)
+	etag := get(t, h, "/app.js").Header().Get("ETag")
+	if etag != `"`+contentHash([]byte(appJS))+`"` {
+		t.Fatalf("ETag %s", etag)
+	}
+
+	tests := []struct {
+		name     string
+		header   []string
+		wantCode int
+	}{
+		{"matching", []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"one of several", []string{"If-None-Match", `"nope", ` + etag}, http.StatusNotModified},
+		{"weak", []string{"If-None-Match", "W/" + etag}, http.StatusNotModified},
+		{"stale", []string{"If-None-Match", `"000000000000"`}, http.StatusOK},
+		{"gzip ETag for a plain request", []string{"If-None-Match", strings.TrimSuffix(etag, `"`) + `-gz"`}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := get(t, h, "/app.js", tt.header...)
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s: %d, want %d", tt.name, rec.Code, tt.wantCode)
+		}
+		if rec.Code == http.StatusNotModified && rec.Body.Len() != 0 {
+			t.Errorf("%s: 304 with a body", tt.name)
+		}
+	}
+
+	gz := get(t, h, "/app.js", "Accept-Encoding", "gzip").Header().Get("ETag")
+	if gz == etag {
+		t.Fatal("gzip and identity responses share an ETag")
+	}
+	if rec := get(t, h, "/app.js", "Accept-Encoding", "gzip", "If-None-Match", gz); rec.Code != http.StatusNotModified {
+		t.Fatalf("gzip revalidation returned %d", rec.Code)
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	h := newHandler(t)
+	tests := []struct {
+		path, accept string
+		wantGzip     bool
+		wantVary     bool
+		wantBody     string
+	}{
+		{"/app.js", "gzip, deflate, br", true, true, appJS},
+		{"/app.js", "GZIP", true, true, appJS},
+		{"/app.js", "*", true, true, appJS},
+		{"/app.js", "", false, true, appJS},
+		{"/app.js", "br", false, true, appJS},
+		{"/app.js", "gzip;q=0", false, true, appJS},
+		{"/app.js", "gzip; q=0.000, identity", false, true, appJS},
+		{"/app.js", "gzip;q=0.5", true, true, appJS},
+		{"/css/site.css", "gzip", true, true, "body{}"},
+		{"/plain.html", "gzip", true, true, "<p>no assets</p>"},
+		{"/img/logo.png", "gzip", false, false, string(logo)},
+	}
+	for _, tt := range tests {
+		rec := get(t, h, tt.path, "Accept-Encoding", tt.accept)
+		gotGzip := rec.Header().Get("Content-Encoding") == "gzip"
+		gotVary := rec.Header().Get("Vary") == "Accept-Encoding"
+		if gotGzip != tt.wantGzip || gotVary != tt.wantVary {
+			t.Errorf("GET %s with %q: gzip %v, vary %v; want %v, %v", tt.path, tt.accept, gotGzip, gotVary, tt.wantGzip, tt.wantVary)
+			continue
+		}
+		body := rec.Body.Bytes()
+		if gotGzip {
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("GET %s: %v", tt.path, err)
+			}
+			if body, err = io.ReadAll(zr); err != nil {
+				t.Fatalf("GET %s: %v", tt.path, err)
+			}
+		}
+		if string(body) != tt.wantBody {
+			t.Errorf("GET %s with %q: body %q", tt.path, tt.accept, body)
+		}
+	}
+}
+
+func TestMethods(t *testing.T) {
+	h := newHandler(t)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/app.js", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+		t.Fatalf("POST returned %d with Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/app.js", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 || rec.Header().Get("Content-Length") != "960" {
+		t.Fatalf("HEAD returned %d, %d bytes, Content-Length %q", rec.Code, rec.Body.Len(), rec.Header().Get("Content-Length"))
+	}
+}
+
+func TestDevRefresh(t *testing.T) {
+	fsys := site()
+	h, err := New(fsys, Options{Dev: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys["app.js"] = &fstest.MapFile{Data: []byte("changed()"), ModTime: fsys["app.js"].ModTime.Add(1)}
+
+	if body := get(t, h, "/app.js").Body.String(); body != "changed()" {
+		t.Fatalf("dev handler served %q after the file changed", body)
+	}
+	if page := get(t, h, "/").Body.String(); !strings.Contains(page, "app."+contentHash([]byte("changed()"))+".js") {
+		t.Fatalf("index.html still names the old hash:\n%s", page)
+	}
+}
@@ -0,0 +1,175 @@
+// Package cors applies one origin allowlist to cross-origin HTTP requests
+// and WebSocket handshakes. Origins not on the list get no CORS headers, so
+// browsers keep the response from the calling page; same-origin requests
+// are unaffected either way.
+package cors
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxAge is how long browsers may cache a preflight answer when
+// Options.MaxAge is zero.
+const DefaultMaxAge = 10 * time.Minute
+
+// Options configures a Policy.
+type Options struct {
+	// AllowedOrigins lists origins such as https://example.com. "*" allows
+	// every origin.
+	AllowedOrigins []string
+
+	// AllowCredentials lets listed origins send cookies and read the
+	// response; the origin is echoed rather than "*", as the spec requires.
+	// It never applies to origins admitted only by "*", which would let any
+	// site act with the user's cookies: those still get "*" and no
+	// credentials.
+	AllowCredentials bool
+
+	// AllowedHeaders are the request headers a cross-origin page may set.
+	// Defaults to Content-Type, Authorization and X-Request-ID.
+	AllowedHeaders []string
+
+	// ExposedHeaders are the response headers such a page may read.
+	// Defaults to X-Request-ID and the API deprecation headers.
+	ExposedHeaders []string
+
+	MaxAge time.Duration
+}
+
+// Policy is a compiled Options.
+type Policy struct {
+	any         bool
+	origins     map[string]bool
+	credentials bool
+	headers     string
+	exposed     string
+	maxAge      string
+}
+
+// New compiles opts. Origins are compared case-insensitively and without a
+// trailing slash.
+func New(opts Options) *Policy {
+	p := &Policy{origins: map[string]bool{}, credentials: opts.AllowCredentials}
+	for _, origin := range opts.AllowedOrigins {
+		origin = normalize(origin)
+		if origin == "*" {
+			p.any = true
+			continue
+		}
+		if origin != "" {
+			p.origins[origin] = true
+		}
+	}
+
+	headers := opts.AllowedHeaders
+	if headers == nil {
+		headers = []string{"Content-Type", "Authorization", "X-Request-ID"}
+	}
+	exposed := opts.ExposedHeaders
+	if exposed == nil {
+		exposed = []string{"X-Request-ID", "Deprecation", "Sunset", "Link"}
+	}
+	maxAge := opts.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultMaxAge
+	}
+	p.headers = strings.Join(headers, ", ")
+	p.exposed = strings.Join(exposed, ", ")
+	p.maxAge = strconv.Itoa(int(maxAge.Seconds()))
+	return p
+}
+
+// ParseOrigins splits a comma-separated list, as read from an environment
+// variable.
+func ParseOrigins(s string) []string {
+	var origins []string
+	for _, origin := range strings.Split(s, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// Allowed reports whether origin is on the allowlist.
+func (p *Policy) Allowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	return p.any || p.origins[normalize(origin)]
+}
+
+// CheckOrigin is a websocket.Upgrader CheckOrigin. Handshakes without an
+// Origin header (not from a browser) and from the server's own host are
+// accepted; other origins must be on the allowlist.
+func (p *Policy) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return p.Allowed(origin)
+}
+
+// Middleware adds CORS headers for allowed origins and answers preflight
+// requests for any path and method itself; the wrapped handler only sees
+// the real request.
+func (p *Policy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		header := w.Header()
+		header.Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !p.Allowed(origin) {
+			if preflight {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch listed := p.origins[normalize(origin)]; {
+		case p.credentials && listed:
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Credentials", "true")
+		case p.any:
+			header.Set("Access-Control-Allow-Origin", "*")
+		default:
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if !preflight {
+			if p.exposed != "" {
+				header.Set("Access-Control-Expose-Headers", p.exposed)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Whether the method exists on this path is left to the real
+		// request, which gets a normal 404 or 405.
+		header.Set("Access-Control-Allow-Methods", strings.ToUpper(r.Header.Get("Access-Control-Request-Method")))
+		if p.headers != "" {
+			header.Set("Access-Control-Allow-Headers", p.headers)
+		}
+		header.Set("Access-Control-Max-Age", p.maxAge)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func normalize(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
@@ -0,0 +1,62 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+
+	tests := []struct {
+		name            string
+		origins         []string
+		credentials     bool
+		origin          string
+		preflight       bool
+		wantStatus      int
+		wantAllow       string
+		wantCredentials bool
+	}{
+		{name: "same origin", origins: []string{"https://a.example"}, wantStatus: http.StatusTeapot},
+		{name: "listed origin", origins: []string{"https://A.example/"}, origin: "https://a.example", wantStatus: http.StatusTeapot, wantAllow: "https://a.example"},
+		{name: "unlisted origin", origins: []string{"https://a.example"}, origin: "https://b.example", wantStatus: http.StatusTeapot},
+		{name: "wildcard", origins: []string{"*"}, origin: "https://b.example", wantStatus: http.StatusTeapot, wantAllow: "*"},
+		{name: "preflight", origins: []string{"https://a.example"}, origin: "https://a.example", preflight: true, wantStatus: http.StatusNoContent, wantAllow: "https://a.example"},
+		{name: "refused preflight", origins: []string{"https://a.example"}, origin: "https://b.example", preflight: true, wantStatus: http.StatusForbidden},
+		{name: "credentials for a listed origin", origins: []string{"https://a.example"}, credentials: true, origin: "https://a.example", wantStatus: http.StatusTeapot, wantAllow: "https://a.example", wantCredentials: true},
+		{name: "credentials on preflight", origins: []string{"https://a.example"}, credentials: true, origin: "https://a.example", preflight: true, wantStatus: http.StatusNoContent, wantAllow: "https://a.example", wantCredentials: true},
+		{name: "no credentials through the wildcard", origins: []string{"*"}, credentials: true, origin: "https://b.example", wantStatus: http.StatusTeapot, wantAllow: "*"},
+		{name: "listed origin beside the wildcard", origins: []string{"*", "https://a.example"}, credentials: true, origin: "https://a.example", wantStatus: http.StatusTeapot, wantAllow: "https://a.example", wantCredentials: true},
+		{name: "unlisted origin beside the wildcard", origins: []string{"*", "https://a.example"}, credentials: true, origin: "https://b.example", wantStatus: http.StatusTeapot, wantAllow: "*"},
+		{name: "no credentials for an unlisted origin", origins: []string{"https://a.example"}, credentials: true, origin: "https://b.example", wantStatus: http.StatusTeapot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := http.MethodPost
+			if tt.preflight {
+				method = http.MethodOptions
+			}
+			req := httptest.NewRequest(method, "/api/v1/bid", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+			New(Options{AllowedOrigins: tt.origins, AllowCredentials: tt.credentials}).Middleware(ok).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllow {
+				t.Fatalf("Access-Control-Allow-Origin %q, want %q", got, tt.wantAllow)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); (got == "true") != tt.wantCredentials {
+				t.Fatalf("Access-Control-Allow-Credentials %q, want credentials %v", got, tt.wantCredentials)
+			}
+		})
+	}
+}
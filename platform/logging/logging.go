@@ -0,0 +1,117 @@
+// Package logging sets up log/slog for the services: text or JSON output,
+// a configurable level, redaction of emails and credentials, and request
+// IDs carried through the context.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Options selects the level and output format.
+type Options struct {
+	Service string
+	Level   string // debug, info, warn or error
+	Format  string // text or json
+}
+
+// OptionsFromEnv reads LOG_LEVEL and LOG_FORMAT.
+func OptionsFromEnv(service string) Options {
+	return Options{
+		Service: service,
+		Level:   os.Getenv("LOG_LEVEL"),
+		Format:  os.Getenv("LOG_FORMAT"),
+	}
+}
+
+// ParseLevel accepts debug, info, warn or error; empty means info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", s)
+}
+
+// ValidFormat reports whether f is a supported output format.
+func ValidFormat(f string) bool {
+	switch strings.ToLower(strings.TrimSpace(f)) {
+	case "", "text", "json":
+		return true
+	}
+	return false
+}
+
+// New builds a redacting logger writing to w.
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	if !ValidFormat(opts.Format) {
+		return nil, fmt.Errorf("unknown log format %q (want text or json)", opts.Format)
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if strings.EqualFold(strings.TrimSpace(opts.Format), "json") {
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(w, handlerOpts)
+	}
+
+	logger := slog.New(&redactingHandler{next: handler})
+	if opts.Service != "" {
+		logger = logger.With("service", opts.Service)
+	}
+	return logger, nil
+}
+
+// Setup builds a logger on stderr and makes it the default, which also
+// routes the standard log package through it.
+func Setup(opts Options) (*slog.Logger, error) {
+	logger, err := New(os.Stderr, opts)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// Fatal logs at error level and exits.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+type contextKey struct{}
+
+// WithRequestID returns a context carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// RequestID returns the ID stored by the middleware, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// FromContext returns the default logger tagged with the request ID, so
+// store errors can be matched to the request that caused them.
+func FromContext(ctx context.Context) *slog.Logger {
+	if id := RequestID(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
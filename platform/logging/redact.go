@@ -0,0 +1,145 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	paramPattern  = regexp.MustCompile(`(?i)\b(token|claimToken|secret|password|api_key|key|email)=([^&\s"]+)`)
+	bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[^\s"]+`)
+	keyPattern    = regexp.MustCompile(`\b(sk|rk|whsec)_[A-Za-z0-9_]+`)
+)
+
+// sensitiveKeys are attribute and field names whose values are always
+// dropped, lower-cased and without underscores or dashes so admin_token,
+// adminToken and Admin-Token all match.
+var sensitiveKeys = map[string]bool{
+	"token":         true,
+	"admintoken":    true,
+	"claimtoken":    true,
+	"secret":        true,
+	"password":      true,
+	"authorization": true,
+	"email":         true,
+}
+
+func sensitive(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	return sensitiveKeys[key]
+}
+
+// Redact masks email addresses, credential query parameters, bearer
+// tokens and provider keys in s.
+func Redact(s string) string {
+	s = emailPattern.ReplaceAllString(s, "[email]")
+	s = paramPattern.ReplaceAllString(s, "${1}="+redacted)
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+	s = keyPattern.ReplaceAllString(s, "${1}_"+redacted)
+	return s
+}
+
+// redactingHandler applies Redact to the message and every string
+// attribute before handing the record on.
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	clean := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		clean.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		clean[i] = redactAttr(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(clean)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	if sensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		clean := make([]any, len(group))
+		for i, g := range group {
+			clean[i] = redactAttr(g)
+		}
+		return slog.Group(a.Key, clean...)
+	case slog.KindAny:
+		return slog.Any(a.Key, redactAny(v.Any()))
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// redactAny cleans a value logged with slog.Any. Errors and Stringers such
+// as *url.URL are logged as their redacted text. Anything else is taken
+// through its JSON form, so a struct or map holding a token loses it
+// whether the field is named after a secret or the secret sits in a URL.
+func redactAny(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case error:
+		return Redact(v.Error())
+	case fmt.Stringer:
+		return Redact(v.String())
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Redact(fmt.Sprintf("%+v", v))
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return Redact(string(data))
+	}
+	return redactJSON(generic)
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case string:
+		return Redact(v)
+	case map[string]any:
+		for key, field := range v {
+			if sensitive(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return v
+}
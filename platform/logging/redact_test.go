@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"nothing to hide", "nothing to hide"},
+		{"from ada@example.com", "from [email]"},
+		{"/api/v1/auditions?token=s3cret&limit=5", "/api/v1/auditions?token=[redacted]&limit=5"},
+		{"/refund?claimToken=abc123", "/refund?claimToken=[redacted]"},
+		{"GET /x?TOKEN=abc", "GET /x?TOKEN=[redacted]"},
+		{"Authorization: Bearer eyJhbGciOi", "Authorization: Bearer [redacted]"},
+		{"using sk_live_51Habc and whsec_xyz", "using sk_[redacted] and whsec_[redacted]"},
+		{"monkey=banana&token=", "monkey=banana&token="},
+	}
+	for _, tt := range tests {
+		if got := Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerRedacts(t *testing.T) {
+	type settings struct {
+		AdminToken string
+		Endpoint   string
+		Nested     map[string]string
+	}
+
+	for _, format := range []string{"text", "json"} {
+		var buf bytes.Buffer
+		logger, err := New(&buf, Options{Format: format, Level: "debug"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		logger.With("admin_token", "leak-1").WithGroup("req").Info("login by ada@example.com",
+			"path", "/api/v1/auditions?token=leak-2",
+			"url", &url.URL{Path: "/api/v1/ballot", RawQuery: "token=leak-3"},
+			"err", errors.New("provider rejected sk_live_leak4"),
+			slog.Group("headers", "Authorization", "Bearer leak-5"),
+			"claimToken", "leak-6",
+			"config", settings{AdminToken: "leak-7", Endpoint: "https://x.example/?secret=leak-8", Nested: map[string]string{"password": "leak-9"}},
+			"queries", []string{"a=1", "token=leak-10"},
+			"count", 3,
+		)
+
+		out := buf.String()
+		for _, leak := range []string{"leak-1", "leak-2", "leak-3", "leak4", "leak-5", "leak-6", "leak-7", "leak-8", "leak-9", "leak-10"} {
+			if strings.Contains(out, leak) {
+				t.Errorf("%s output contains %s:\n%s", format, leak, out)
+			}
+		}
+		for _, want := range []string{"[email]", "[redacted]", "/api/v1/ballot", "x.example", "count"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output lacks %q:\n%s", format, want, out)
+			}
+		}
+	}
+}
+
+func TestMiddlewareRedactsQuery(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Options{Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if RequestID(r.Context()) == "" {
+			t.Error("handler ran without a request ID")
+		}
+		FromContext(r.Context()).InfoContext(context.Background(), "handling", "query", r.URL.Query())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/signal-bank/admin/refunds?token=admin-secret&limit=5", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	out := buf.String()
+	if strings.Contains(out, "admin-secret") {
+		t.Fatalf("log contains the admin token:\n%s", out)
+	}
+	if !strings.Contains(out, `"path":"/api/v1/signal-bank/admin/refunds?token=[redacted]&limit=5"`) || !strings.Contains(out, `"request_id":"req-1"`) {
+		t.Fatalf("access log lacks the redacted path or request ID:\n%s", out)
+	}
+	if rec.Header().Get(RequestIDHeader) != "req-1" {
+		t.Fatalf("response request ID %q, want req-1", rec.Header().Get(RequestIDHeader))
+	}
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPMetrics records request counts and latencies per route, method and
+// status.
+type HTTPMetrics struct {
+	requests *Counter
+	duration *Histogram
+}
+
+func (r *Registry) NewHTTPMetrics() *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: r.NewCounter("http_requests_total", "HTTP requests handled.", "route", "method", "status"),
+		duration: r.NewHistogram("http_request_duration_seconds", "Time taken to handle HTTP requests.", nil, "route", "method", "status"),
+	}
+}
+
+// Router reports the pattern a request was routed to; *http.ServeMux
+// implements it.
+type Router interface {
+	Handler(r *http.Request) (http.Handler, string)
+}
+
+// Instrument wraps next, labelling each request with the router pattern that
+// matched it so paths with IDs do not create a series each.
+func (m *HTTPMetrics) Instrument(router Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if _, pattern := router.Handler(r); pattern != "" {
+			route = pattern
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		status := strconv.Itoa(rec.status)
+		if rec.hijacked {
+			status = "hijacked"
+		}
+		method := methodLabel(r.Method)
+		m.requests.Inc(route, method, status)
+		m.duration.Observe(time.Since(start).Seconds(), route, method, status)
+	})
+}
+
+// knownMethods are the methods given their own series. Clients can send
+// any verb, so the rest share one label value.
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+func methodLabel(method string) string {
+	if knownMethods[method] {
+		return method
+	}
+	return "OTHER"
+}
+
+// statusRecorder captures the response status while still letting
+// WebSocket upgrades hijack the connection.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("metrics: response does not support hijacking")
+	}
+	s.hijacked = true
+	return h.Hijack()
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Since returns the seconds elapsed since start, for Histogram.Observe.
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// sampleLine is a sample in the text exposition format: a metric name,
+// optional labels and a value.
+var sampleLine = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\]|\\.)*"(?:,[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\]|\\.)*")*\})? (\S+)$`)
+
+// scrape fetches url and checks every line against the exposition format,
+// returning the samples by name and labels, e.g.
+// `http_requests_total{route="GET /items/{id}",method="GET",status="200"}`.
+func scrape(t *testing.T, url string) map[string]float64 {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("scrape returned %s with Content-Type %q", resp.Status, resp.Header.Get("Content-Type"))
+	}
+
+	samples := make(map[string]float64)
+	typed := make(map[string]string)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# HELP ") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "# TYPE "); ok {
+			name, kind, _ := strings.Cut(rest, " ")
+			if typed[name] != "" {
+				t.Errorf("%s has two TYPE lines", name)
+			}
+			typed[name] = kind
+			continue
+		}
+		m := sampleLine.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("line %q is not in the exposition format", line)
+		}
+		family := m[1]
+		if typed[family] == "" {
+			family = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(family, "_bucket"), "_sum"), "_count")
+		}
+		if typed[family] == "" {
+			t.Errorf("sample %q comes before its TYPE line", line)
+		}
+		value, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		samples[m[1]+m[2]] = value
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return samples
+}
+
+func TestScrapeAfterRequests(t *testing.T) {
+	reg := NewRegistry()
+	httpMetrics := reg.NewHTTPMetrics()
+	writes := reg.NewHistogram("store_write_seconds", "Time taken to write a store file.", []float64{0.01, 0.1, 1}, "store")
+	connected := reg.NewGauge("clients_connected", "Open WebSocket connections.")
+	reg.NewGaugeFunc("auctions", "Auctions by status.", []string{"status"}, func(emit func(float64, ...string)) {
+		emit(2, "active")
+		emit(1, `say "hi"`)
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, r.PathValue("id")) })
+	mux.HandleFunc("POST /items", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) })
+	mux.Handle("GET /metrics", reg.Handler())
+	srv := httptest.NewServer(httpMetrics.Instrument(mux, mux))
+	defer srv.Close()
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/items/1"},
+		{http.MethodGet, "/items/2"},
+		{http.MethodPost, "/items"},
+		{http.MethodGet, "/nowhere"},
+		{"PURGE", "/items/1"},
+	} {
+		r, err := http.NewRequest(req.method, srv.URL+req.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	writes.Observe(0.005, "ballot")
+	writes.Observe(0.05, "ballot")
+	writes.Observe(5, "ballot")
+	connected.Set(3)
+	connected.Add(-1)
+
+	samples := scrape(t, srv.URL+"/metrics")
+
+	for series, want := range map[string]float64{
+		`http_requests_total{route="GET /items/{id}",method="GET",status="200"}`:                            2,
+		`http_requests_total{route="POST /items",method="POST",status="201"}`:                               1,
+		`http_requests_total{route="unmatched",method="GET",status="404"}`:                                  1,
+		`http_requests_total{route="unmatched",method="OTHER",status="405"}`:                                1,
+		`http_request_duration_seconds_count{route="GET /items/{id}",method="GET",status="200"}`:            2,
+		`http_request_duration_seconds_bucket{route="GET /items/{id}",method="GET",status="200",le="+Inf"}`: 2,
+		`store_write_seconds_bucket{store="ballot",le="0.01"}`:                                              1,
+		`store_write_seconds_bucket{store="ballot",le="0.1"}`:                                               2,
+		`store_write_seconds_bucket{store="ballot",le="1"}`:                                                 2,
+		`store_write_seconds_bucket{store="ballot",le="+Inf"}`:                                              3,
+		`store_write_seconds_sum{store="ballot"}`:                                                           5.055,
+		`store_write_seconds_count{store="ballot"}`:                                                         3,
+		`clients_connected`:             2,
+		`auctions{status="active"}`:     2,
+		`auctions{status="say \"hi\""}`: 1,
+	} {
+		got, ok := samples[series]
+		if !ok {
+			t.Errorf("scrape lacks %s", series)
+		} else if got != want {
+			t.Errorf("%s = %v, want %v", series, got, want)
+		}
+	}
+
+	// Buckets are cumulative.
+	last := -1.0
+	for _, le := range []string{"0.001", "0.0025", "0.005", "0.01", "0.025", "0.05", "0.1", "0.25", "0.5", "1", "2.5", "5", "10", "+Inf"} {
+		got := samples[`http_request_duration_seconds_bucket{route="GET /items/{id}",method="GET",status="200",le="`+le+`"}`]
+		if got < last {
+			t.Errorf("bucket le=%s holds %v, less than the bucket below it", le, got)
+		}
+		last = got
+	}
+}
+
+func TestRegistryRejectsMisuse(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.NewCounter("jobs_total", "Jobs run.", "kind")
+
+	for name, f := range map[string]func(){
+		"duplicate name":       func() { reg.NewGauge("jobs_total", "Again.") },
+		"missing label value":  func() { c.Inc() },
+		"decreasing a counter": func() { c.Add(-1, "x") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
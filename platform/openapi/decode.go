@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrInvalidJSON is returned by Decode when the body is not JSON at all.
+	ErrInvalidJSON = errors.New("invalid JSON")
+
+	// ErrBodyTooLarge is returned by Decode when the body was cut off by
+	// http.MaxBytesReader, so callers can answer 413 rather than 400.
+	ErrBodyTooLarge = errors.New("request body too large")
+)
+
+// Decode reads a JSON body, trims surrounding whitespace from every string
+// in it, validates the result against s and, if it passes, unmarshals it
+// into dst. Field problems come back as a slice rather than an error so the
+// caller can render them in its own envelope.
+func Decode(r io.Reader, s *Schema, dst any) ([]FieldError, error) {
+	var raw any
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrBodyTooLarge
+		}
+		return nil, ErrInvalidJSON
+	}
+	raw = trimStrings(raw)
+
+	if errs := s.Validate(raw); len(errs) > 0 {
+		return errs, nil
+	}
+
+	clean, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(clean, dst); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	return nil, nil
+}
+
+func trimStrings(v any) any {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []any:
+		for i := range v {
+			v[i] = trimStrings(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = trimStrings(v[k])
+		}
+	}
+	return v
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	schema := Object(map[string]*Schema{
+		"name":  String().Len(1, 20),
+		"email": String().WithFormat(FormatEmail),
+		"tags":  Array(String().Len(1, 0)),
+		"kind":  String().OneOf("a", "b"),
+	}, "name")
+
+	type body struct {
+		Name  string   `json:"name"`
+		Email string   `json:"email"`
+		Tags  []string `json:"tags"`
+		Kind  string   `json:"kind"`
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		want       body
+		wantFields []FieldError
+		wantErr    error
+	}{
+		{name: "trims every string", input: `{"name":"  Ada ","email":" ada@example.com\n","tags":[" x "],"kind":" a "}`, want: body{Name: "Ada", Email: "ada@example.com", Tags: []string{"x"}, Kind: "a"}},
+		{name: "whitespace only is missing", input: `{"name":"   "}`, wantFields: []FieldError{{"name", "is required"}}},
+		{name: "validated after trimming", input: `{"name":"Ada","tags":["ok","  "]}`, wantFields: []FieldError{{"tags[1]", "is required"}}},
+		{name: "every problem at once", input: `{"email":"nope","kind":"c"}`, wantFields: []FieldError{{"email", "must be a valid email address"}, {"kind", "must be a or b"}, {"name", "is required"}}},
+		{name: "not JSON", input: `{"name":`, wantErr: ErrInvalidJSON},
+		{name: "empty body", input: ``, wantErr: ErrInvalidJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got body
+			fields, err := Decode(strings.NewReader(tt.input), schema, &got)
+			if err != tt.wantErr {
+				t.Fatalf("Decode error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Fatalf("Decode fields = %v, want %v", fields, tt.wantFields)
+			}
+			if tt.wantErr == nil && tt.wantFields == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("decoded %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeLeavesDestinationOnFailure(t *testing.T) {
+	schema := Object(map[string]*Schema{"name": String().Len(1, 3)}, "name")
+	dst := struct {
+		Name string `json:"name"`
+	}{Name: "kept"}
+	if fields, _ := Decode(strings.NewReader(`{"name":"toolong"}`), schema, &dst); len(fields) != 1 || dst.Name != "kept" {
+		t.Fatalf("fields %v, dst %+v: want one problem and dst untouched", fields, dst)
+	}
+}
+
+func TestDecodeBodyTooLarge(t *testing.T) {
+	schema := Object(map[string]*Schema{"name": String()})
+	rec := httptest.NewRecorder()
+	body := http.MaxBytesReader(rec, io.NopCloser(strings.NewReader(`{"name":"`+strings.Repeat("a", 100)+`"}`)), 32)
+
+	var dst struct {
+		Name string `json:"name"`
+	}
+	if _, err := Decode(body, schema, &dst); err != ErrBodyTooLarge {
+		t.Fatalf("Decode error = %v, want ErrBodyTooLarge", err)
+	}
+}
@@ -0,0 +1,288 @@
+// Package openapi describes the services' HTTP APIs as OpenAPI 3.1
+// documents and validates request bodies against the same schemas, so the
+// published contract and the checks the handlers run cannot drift apart.
+package openapi
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of JSON Schema the services use. Build one with
+// String, Number, Integer, Boolean, Array or Object and the chained
+// constraint methods.
+type Schema struct {
+	Ref              string             `json:"$ref,omitempty"`
+	Type             string             `json:"type,omitempty"`
+	Format           string             `json:"format,omitempty"`
+	Description      string             `json:"description,omitempty"`
+	Enum             []string           `json:"enum,omitempty"`
+	MinLength        int                `json:"minLength,omitempty"`
+	MaxLength        int                `json:"maxLength,omitempty"`
+	Minimum          *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum *float64           `json:"exclusiveMinimum,omitempty"`
+	Maximum          *float64           `json:"maximum,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	MinItems         int                `json:"minItems,omitempty"`
+	MaxItems         int                `json:"maxItems,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	Example          any                `json:"example,omitempty"`
+
+	// resolved is the component a reference returned by Document.Schema
+	// points at, so validation can follow it.
+	resolved *Schema
+}
+
+// Formats understood by Validate. "uri" is stricter than JSON Schema's: the
+// services only ever accept http and https links.
+const (
+	FormatEmail    = "email"
+	FormatURI      = "uri"
+	FormatDateTime = "date-time"
+	FormatDate     = "date"
+)
+
+func String() *Schema  { return &Schema{Type: "string"} }
+func Number() *Schema  { return &Schema{Type: "number"} }
+func Integer() *Schema { return &Schema{Type: "integer"} }
+func Boolean() *Schema { return &Schema{Type: "boolean"} }
+
+// Array returns an array schema whose elements match items.
+func Array(items *Schema) *Schema { return &Schema{Type: "array", Items: items} }
+
+// Object returns an object schema. Unknown properties are allowed, as the
+// handlers have always ignored them.
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// Ref points at a schema under #/components/schemas. Only references
+// returned by Document.Schema can be validated against; a bare Ref fails
+// validation rather than letting every value through.
+func Ref(name string) *Schema { return &Schema{Ref: "#/components/schemas/" + name} }
+
+// Len bounds a string's length; zero leaves that side open.
+func (s *Schema) Len(min, max int) *Schema {
+	s.MinLength, s.MaxLength = min, max
+	return s
+}
+
+// Size bounds an array's length; zero leaves that side open.
+func (s *Schema) Size(min, max int) *Schema {
+	s.MinItems, s.MaxItems = min, max
+	return s
+}
+
+func (s *Schema) WithFormat(format string) *Schema {
+	s.Format = format
+	return s
+}
+
+// OneOf restricts a string to the given values.
+func (s *Schema) OneOf(values ...string) *Schema {
+	s.Enum = values
+	return s
+}
+
+// Above requires a number strictly greater than n.
+func (s *Schema) Above(n float64) *Schema {
+	s.ExclusiveMinimum = &n
+	return s
+}
+
+// AtLeast requires a number greater than or equal to n.
+func (s *Schema) AtLeast(n float64) *Schema {
+	s.Minimum = &n
+	return s
+}
+
+// AtMost requires a number less than or equal to n.
+func (s *Schema) AtMost(n float64) *Schema {
+	s.Maximum = &n
+	return s
+}
+
+func (s *Schema) Describe(text string) *Schema {
+	s.Description = text
+	return s
+}
+
+// FieldError names one property that failed validation.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Validate checks a decoded JSON value (as produced by encoding/json into
+// an any) and returns every problem found, in a stable order.
+func (s *Schema) Validate(value any) []FieldError {
+	var errs []FieldError
+	s.validate("", value, &errs)
+	return errs
+}
+
+func (s *Schema) validate(path string, value any, errs *[]FieldError) {
+	add := func(msg string, args ...any) {
+		field := path
+		if field == "" {
+			field = "body"
+		}
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf(msg, args...)})
+	}
+
+	if s.Ref != "" {
+		if s.resolved == nil {
+			add("cannot be checked against the unregistered schema %s", s.Ref)
+			return
+		}
+		s.resolved.validate(path, value, errs)
+		return
+	}
+
+	switch s.Type {
+	case "string":
+		v, ok := value.(string)
+		if !ok {
+			add("must be a string")
+			return
+		}
+		if v == "" && s.MinLength > 0 {
+			add("is required")
+			return
+		}
+		n := len([]rune(v))
+		if s.MinLength > 0 && n < s.MinLength {
+			add("must be at least %d characters", s.MinLength)
+		}
+		if s.MaxLength > 0 && n > s.MaxLength {
+			add("must be at most %d characters", s.MaxLength)
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, v) {
+			add("must be %s", orList(s.Enum))
+		}
+		if v != "" {
+			if msg := checkFormat(s.Format, v); msg != "" {
+				add(msg)
+			}
+		}
+
+	case "number", "integer":
+		v, ok := value.(float64)
+		if !ok {
+			add("must be a number")
+			return
+		}
+		if s.Type == "integer" && v != float64(int64(v)) {
+			add("must be a whole number")
+		}
+		if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+			if *s.ExclusiveMinimum == 0 {
+				add("must be greater than zero")
+			} else {
+				add("must be greater than %g", *s.ExclusiveMinimum)
+			}
+		}
+		if s.Minimum != nil && v < *s.Minimum {
+			add("must be at least %g", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			add("must be at most %g", *s.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			add("must be true or false")
+		}
+
+	case "array":
+		v, ok := value.([]any)
+		if !ok {
+			add("must be a list")
+			return
+		}
+		if len(v) == 0 && s.MinItems > 0 {
+			add("is required")
+			return
+		}
+		if s.MinItems > 0 && len(v) < s.MinItems {
+			add("must have at least %d entries", s.MinItems)
+		}
+		if s.MaxItems > 0 && len(v) > s.MaxItems {
+			add("must have at most %d entries", s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+
+	case "object":
+		v, ok := value.(map[string]any)
+		if !ok {
+			add("must be an object")
+			return
+		}
+		for _, name := range sortedKeys(s.Properties) {
+			child := name
+			if path != "" {
+				child = path + "." + name
+			}
+			prop, present := v[name]
+			if !present || prop == nil {
+				if contains(s.Required, name) {
+					*errs = append(*errs, FieldError{Field: child, Message: "is required"})
+				}
+				continue
+			}
+			s.Properties[name].validate(child, prop, errs)
+		}
+	}
+}
+
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+func checkFormat(format, v string) string {
+	switch format {
+	case FormatEmail:
+		addr, err := mail.ParseAddress(v)
+		if err != nil || addr.Address != v || !strings.Contains(v[strings.LastIndex(v, "@"):], ".") {
+			return "must be a valid email address"
+		}
+	case FormatURI:
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "must be a valid http(s) link"
+		}
+	case FormatDateTime:
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			return "must be an RFC3339 timestamp"
+		}
+	case FormatDate:
+		if _, err := time.Parse("2006-01-02", v); err != nil || !datePattern.MatchString(v) {
+			return "must be a date in YYYY-MM-DD form"
+		}
+	}
+	return ""
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// orList renders ["a","b","c"] as "a, b or c".
+func orList(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
@@ -0,0 +1,103 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	signup := Object(map[string]*Schema{
+		"name":    String().Len(1, 5),
+		"email":   String().WithFormat(FormatEmail),
+		"site":    String().WithFormat(FormatURI),
+		"at":      String().WithFormat(FormatDateTime),
+		"day":     String().WithFormat(FormatDate),
+		"plan":    String().OneOf("free", "pro", "team"),
+		"amount":  Number().Above(0).AtMost(100),
+		"seats":   Integer().AtLeast(1),
+		"agree":   Boolean(),
+		"tags":    Array(String().Len(1, 0)).Size(1, 2),
+		"address": Object(map[string]*Schema{"city": String().Len(1, 0)}, "city"),
+	}, "name", "amount")
+
+	tests := []struct {
+		name string
+		body string
+		want []FieldError
+	}{
+		{name: "valid", body: `{"name":"Ada","amount":5,"plan":"pro","email":"ada@example.com","site":"https://example.com","at":"2026-10-18T12:00:00Z","day":"2026-10-18","seats":2,"agree":true,"tags":["a"],"address":{"city":"Lagos"}}`},
+		{name: "required missing", body: `{}`, want: []FieldError{{"amount", "is required"}, {"name", "is required"}}},
+		{name: "null counts as missing", body: `{"name":null,"amount":1}`, want: []FieldError{{"name", "is required"}}},
+		{name: "empty required string", body: `{"name":"","amount":1}`, want: []FieldError{{"name", "is required"}}},
+		{name: "too long", body: `{"name":"Adelaide","amount":1}`, want: []FieldError{{"name", "must be at most 5 characters"}}},
+		{name: "length counts runes", body: `{"name":"Zoë-é","amount":1}`},
+		{name: "enum", body: `{"name":"Ada","amount":1,"plan":"gold"}`, want: []FieldError{{"plan", "must be free, pro or team"}}},
+		{name: "wrong types", body: `{"name":7,"amount":"7","agree":"yes","tags":"a","address":[]}`, want: []FieldError{
+			{"address", "must be an object"}, {"agree", "must be true or false"}, {"amount", "must be a number"}, {"name", "must be a string"}, {"tags", "must be a list"},
+		}},
+		{name: "number bounds", body: `{"name":"Ada","amount":0,"seats":0}`, want: []FieldError{{"amount", "must be greater than zero"}, {"seats", "must be at least 1"}}},
+		{name: "number maximum", body: `{"name":"Ada","amount":101}`, want: []FieldError{{"amount", "must be at most 100"}}},
+		{name: "whole number", body: `{"name":"Ada","amount":1,"seats":1.5}`, want: []FieldError{{"seats", "must be a whole number"}}},
+		{name: "formats", body: `{"name":"Ada","amount":1,"email":"Ada <ada@example.com>","site":"ftp://example.com","at":"yesterday","day":"2026-1-8"}`, want: []FieldError{
+			{"at", "must be an RFC3339 timestamp"}, {"day", "must be a date in YYYY-MM-DD form"}, {"email", "must be a valid email address"}, {"site", "must be a valid http(s) link"},
+		}},
+		{name: "email needs a domain", body: `{"name":"Ada","amount":1,"email":"ada@localhost"}`, want: []FieldError{{"email", "must be a valid email address"}}},
+		{name: "empty optional format", body: `{"name":"Ada","amount":1,"email":"","site":""}`},
+		{name: "array bounds", body: `{"name":"Ada","amount":1,"tags":[]}`, want: []FieldError{{"tags", "is required"}}},
+		{name: "array items", body: `{"name":"Ada","amount":1,"tags":["a","",""]}`, want: []FieldError{{"tags", "must have at most 2 entries"}, {"tags[1]", "is required"}, {"tags[2]", "is required"}}},
+		{name: "nested object", body: `{"name":"Ada","amount":1,"address":{}}`, want: []FieldError{{"address.city", "is required"}}},
+		{name: "unknown properties allowed", body: `{"name":"Ada","amount":1,"extra":true}`},
+		{name: "body not an object", body: `[]`, want: []FieldError{{"body", "must be an object"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+				t.Fatal(err)
+			}
+			if got := signup.Validate(value); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Validate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFollowsRefs(t *testing.T) {
+	doc := New("Test", "1", "")
+	address := doc.Schema("Address", Object(map[string]*Schema{"city": String().Len(1, 50)}, "city"))
+	order := Object(map[string]*Schema{
+		"ship_to": address,
+		"stops":   Array(address),
+		"bill_to": Ref("Address"),
+	}, "ship_to")
+
+	tests := []struct {
+		body string
+		want []FieldError
+	}{
+		{`{"ship_to":{"city":"Lagos"},"stops":[{"city":"Accra"}]}`, nil},
+		{`{"ship_to":{}}`, []FieldError{{"ship_to.city", "is required"}}},
+		{`{"ship_to":"Lagos"}`, []FieldError{{"ship_to", "must be an object"}}},
+		{`{"ship_to":{"city":"Lagos"},"stops":[{"city":""}]}`, []FieldError{{"stops[0].city", "is required"}}},
+		{`{"ship_to":{"city":"Lagos"},"bill_to":{"city":"Lagos"}}`, []FieldError{{"bill_to", "cannot be checked against the unregistered schema #/components/schemas/Address"}}},
+	}
+	for _, tt := range tests {
+		var value any
+		if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+			t.Fatal(err)
+		}
+		if got := order.Validate(value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Validate(%s) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+
+	// The reference still serializes as a plain $ref.
+	data, err := json.Marshal(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"$ref":"#/components/schemas/Address"}` {
+		t.Fatalf("reference marshals as %s", data)
+	}
+}
@@ -0,0 +1,168 @@
+// Package router mounts an API version's routes on a ServeMux using Go
+// 1.22 method-and-path patterns, optionally again under deprecated alias
+// prefixes, so /api/v1 and a future /api/v2 can be served side by side
+// while old clients keep working.
+package router
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Middleware wraps a handler.
+type Middleware func(http.Handler) http.Handler
+
+// Alias serves a version's routes under an older prefix as well, marking
+// every response with Deprecation, Sunset and successor Link headers.
+type Alias struct {
+	Prefix     string
+	Deprecated time.Time
+	Sunset     time.Time
+}
+
+// Options configures Mount.
+type Options struct {
+	Aliases []Alias
+
+	// NotAllowed answers requests whose path matches a route but whose
+	// method does not. The Allow header is already set when it runs.
+	// Defaults to a plain-text 405.
+	NotAllowed http.HandlerFunc
+}
+
+// Mount calls routes once for prefix and once per alias. routes should only
+// register handlers, since it runs more than once.
+func Mount(mux *http.ServeMux, prefix string, routes func(*Group), opts Options) {
+	notAllowed := opts.NotAllowed
+	if notAllowed == nil {
+		notAllowed = func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+
+	mount := func(p string, mw []Middleware) {
+		g := &Group{
+			mux:        mux,
+			prefix:     strings.TrimSuffix(p, "/"),
+			middleware: mw,
+			methods:    map[string][]string{},
+		}
+		routes(g)
+		g.registerNotAllowed(notAllowed)
+	}
+
+	mount(prefix, nil)
+	for _, alias := range opts.Aliases {
+		mount(alias.Prefix, []Middleware{deprecated(alias, strings.TrimSuffix(prefix, "/"))})
+	}
+}
+
+// Routes returns the "METHOD /path" patterns routes registers, relative to
+// the mount prefix and sorted, for checking them against a published API
+// description.
+func Routes(routes func(*Group)) []string {
+	g := &Group{mux: http.NewServeMux(), methods: map[string][]string{}}
+	routes(g)
+
+	var out []string
+	for path, methods := range g.methods {
+		for _, m := range methods {
+			out = append(out, m+" "+path)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Group registers routes under one prefix. Groups derived with With share
+// the prefix and the method bookkeeping of their parent.
+type Group struct {
+	mux        *http.ServeMux
+	prefix     string
+	middleware []Middleware
+	methods    map[string][]string
+}
+
+// Prefix returns the path prefix the group's routes are mounted under.
+func (g *Group) Prefix() string { return g.prefix }
+
+// With returns a group that wraps its handlers in mw, inside any middleware
+// the group already applies.
+func (g *Group) With(mw ...Middleware) *Group {
+	child := *g
+	child.middleware = append(append([]Middleware(nil), g.middleware...), mw...)
+	return &child
+}
+
+// Handle registers h for a "METHOD /path" pattern relative to the group's
+// prefix. A GET route also answers HEAD, as with ServeMux.
+func (g *Group) Handle(pattern string, h http.Handler) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		panic("router: pattern " + strconv.Quote(pattern) + " must be \"METHOD /path\"")
+	}
+
+	for i := len(g.middleware) - 1; i >= 0; i-- {
+		h = g.middleware[i](h)
+	}
+
+	full := g.prefix + path
+	g.mux.Handle(method+" "+full, h)
+	g.methods[full] = append(g.methods[full], method)
+}
+
+// HandleFunc registers f for a "METHOD /path" pattern.
+func (g *Group) HandleFunc(pattern string, f func(http.ResponseWriter, *http.Request)) {
+	g.Handle(pattern, http.HandlerFunc(f))
+}
+
+// registerNotAllowed adds a method-less pattern per path. ServeMux prefers
+// the method-specific patterns, so this only sees the methods nobody
+// registered.
+func (g *Group) registerNotAllowed(notAllowed http.HandlerFunc) {
+	for path, methods := range g.methods {
+		allow := append([]string(nil), methods...)
+		for _, m := range methods {
+			if m == http.MethodGet {
+				allow = append(allow, http.MethodHead)
+			}
+		}
+		sort.Strings(allow)
+		header := strings.Join(allow, ", ")
+
+		var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", header)
+			notAllowed(w, r)
+		})
+		for i := len(g.middleware) - 1; i >= 0; i-- {
+			h = g.middleware[i](h)
+		}
+		g.mux.Handle(path, h)
+	}
+}
+
+// deprecated sets the RFC 9745 Deprecation header, the RFC 8594 Sunset
+// header and a Link to the same route under the current version.
+func deprecated(alias Alias, successor string) Middleware {
+	prefix := strings.TrimSuffix(alias.Prefix, "/")
+	deprecation := "@" + strconv.FormatInt(alias.Deprecated.Unix(), 10)
+	sunset := ""
+	if !alias.Sunset.IsZero() {
+		sunset = alias.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("Deprecation", deprecation)
+			if sunset != "" {
+				h.Set("Sunset", sunset)
+			}
+			h.Add("Link", "<"+successor+strings.TrimPrefix(r.URL.Path, prefix)+`>; rel="successor-version"`)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	deprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	sunsetAt     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+func routes(g *Group) {
+	g.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "item "+r.PathValue("id")) })
+	g.With(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Admin", "yes")
+			next.ServeHTTP(w, r)
+		})
+	}).HandleFunc("POST /items", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) })
+}
+
+func newMux(opts Options) *http.ServeMux {
+	opts.Aliases = []Alias{
+		{Prefix: "/api", Deprecated: deprecatedAt, Sunset: sunsetAt},
+		{Prefix: "/old/", Deprecated: deprecatedAt},
+	}
+	mux := http.NewServeMux()
+	Mount(mux, "/api/v1/", routes, opts)
+	return mux
+}
+
+func TestMount(t *testing.T) {
+	mux := newMux(Options{})
+	deprecation := "@1792281600"
+
+	tests := []struct {
+		method, path    string
+		wantCode        int
+		wantBody        string
+		wantAllow       string
+		wantDeprecation string
+		wantSunset      string
+		wantLink        string
+	}{
+		{method: "GET", path: "/api/v1/items/7", wantCode: http.StatusOK, wantBody: "item 7"},
+		{method: "HEAD", path: "/api/v1/items/7", wantCode: http.StatusOK},
+		{method: "POST", path: "/api/v1/items", wantCode: http.StatusCreated},
+		{method: "GET", path: "/api/items/7", wantCode: http.StatusOK, wantBody: "item 7",
+			wantDeprecation: deprecation, wantSunset: "Fri, 30 Apr 2027 00:00:00 GMT", wantLink: `</api/v1/items/7>; rel="successor-version"`},
+		{method: "POST", path: "/old/items", wantCode: http.StatusCreated,
+			wantDeprecation: deprecation, wantLink: `</api/v1/items>; rel="successor-version"`},
+		{method: "DELETE", path: "/api/v1/items/7", wantCode: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD"},
+		{method: "GET", path: "/api/v1/items", wantCode: http.StatusMethodNotAllowed, wantAllow: "POST"},
+		{method: "PUT", path: "/api/items/7", wantCode: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD",
+			wantDeprecation: deprecation, wantSunset: "Fri, 30 Apr 2027 00:00:00 GMT", wantLink: `</api/v1/items/7>; rel="successor-version"`},
+		{method: "GET", path: "/api/v2/items/7", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		h := rec.Header()
+		if rec.Code != tt.wantCode || (tt.wantBody != "" && rec.Body.String() != tt.wantBody) {
+			t.Errorf("%s %s: %d %q, want %d %q", tt.method, tt.path, rec.Code, rec.Body.String(), tt.wantCode, tt.wantBody)
+		}
+		if h.Get("Allow") != tt.wantAllow {
+			t.Errorf("%s %s: Allow %q, want %q", tt.method, tt.path, h.Get("Allow"), tt.wantAllow)
+		}
+		if h.Get("Deprecation") != tt.wantDeprecation || h.Get("Sunset") != tt.wantSunset || h.Get("Link") != tt.wantLink {
+			t.Errorf("%s %s: Deprecation %q, Sunset %q, Link %q; want %q, %q, %q",
+				tt.method, tt.path, h.Get("Deprecation"), h.Get("Sunset"), h.Get("Link"), tt.wantDeprecation, tt.wantSunset, tt.wantLink)
+		}
+	}
+}
+
+func TestCustomNotAllowed(t *testing.T) {
+	called := false
+	mux := newMux(Options{NotAllowed: func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if w.Header().Get("Allow") != "POST" {
+			t.Errorf("NotAllowed ran before Allow was set: %q", w.Header().Get("Allow"))
+		}
+		w.WriteHeader(http.StatusTeapot)
+	}})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/items", nil))
+	if !called || rec.Code != http.StatusTeapot {
+		t.Fatalf("custom NotAllowed called %v, status %d", called, rec.Code)
+	}
+}
+
+func TestWith(t *testing.T) {
+	mux := newMux(Options{})
+	for _, path := range []string{"/api/v1/items", "/old/items"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Header().Get("X-Admin") != "yes" {
+			t.Errorf("POST %s skipped the group's middleware", path)
+		}
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/items/7", nil))
+	if rec.Header().Get("X-Admin") != "" {
+		t.Error("middleware added with With leaked to its parent's routes")
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	want := []string{"GET /items/{id}", "POST /items"}
+	if got := Routes(routes); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Routes = %v, want %v", got, want)
+	}
+}
+
+func TestHandleRejectsBadPatterns(t *testing.T) {
+	for _, pattern := range []string{"/items", "GET items", " /items"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("pattern %q did not panic", pattern)
+				}
+			}()
+			Routes(func(g *Group) { g.HandleFunc(pattern, func(http.ResponseWriter, *http.Request) {}) })
+		}()
+	}
+}
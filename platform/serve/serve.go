@@ -0,0 +1,177 @@
+// Package serve runs an HTTP server with production timeouts and a graceful
+// shutdown on SIGINT or SIGTERM. It is shared by the Digital Oracle,
+// SocioVault and Auctmah binaries.
+package serve
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Defaults used for any Options field left at zero.
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultMaxBodyBytes      = 1 << 20
+	DefaultShutdownTimeout   = 20 * time.Second
+)
+
+// Options configures Run.
+type Options struct {
+	Name    string
+	Addr    string
+	Handler http.Handler
+
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// MaxBodyBytes caps every request body; a negative value disables it.
+	MaxBodyBytes int64
+
+	// ShutdownTimeout bounds the whole shutdown: draining requests and
+	// running Cleanup. Render sends SIGKILL 30 seconds after SIGTERM.
+	ShutdownTimeout time.Duration
+
+	// OnShutdown runs as soon as shutdown begins. It is meant for
+	// connections the server no longer tracks, such as hijacked WebSockets.
+	OnShutdown func()
+
+	// Cleanup runs after in-flight requests have drained, to flush stores.
+	Cleanup func(ctx context.Context) error
+}
+
+func (o *Options) setDefaults() {
+	if o.Name == "" {
+		o.Name = "server"
+	}
+	if o.ReadHeaderTimeout == 0 {
+		o.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if o.ReadTimeout == 0 {
+		o.ReadTimeout = DefaultReadTimeout
+	}
+	if o.WriteTimeout == 0 {
+		o.WriteTimeout = DefaultWriteTimeout
+	}
+	if o.IdleTimeout == 0 {
+		o.IdleTimeout = DefaultIdleTimeout
+	}
+	if o.MaxBodyBytes == 0 {
+		o.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if o.ShutdownTimeout == 0 {
+		o.ShutdownTimeout = DefaultShutdownTimeout
+	}
+}
+
+// Run serves until the listener fails or the process is asked to stop. On
+// SIGINT or SIGTERM it stops accepting connections, waits for in-flight
+// requests, runs Cleanup and returns. A second signal kills the process.
+func Run(opts Options) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Stop catching signals as soon as the first arrives, so the next one
+	// gets the default behaviour.
+	context.AfterFunc(ctx, stop)
+
+	addr := opts.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return serve(ctx, ln, opts)
+}
+
+// serve runs the server on ln until ctx is done, then shuts it down as Run
+// describes.
+func serve(ctx context.Context, ln net.Listener, opts Options) error {
+	opts.setDefaults()
+
+	handler := opts.Handler
+	if opts.MaxBodyBytes > 0 {
+		handler = limitBody(handler, opts.MaxBodyBytes)
+	}
+
+	srv := &http.Server{
+		Addr:              opts.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+	}
+	// net/http starts shutdown hooks in their own goroutines once the
+	// listeners are closed; hooksDone lets Run wait for this one.
+	hooksDone := make(chan struct{})
+	if opts.OnShutdown != nil {
+		srv.RegisterOnShutdown(func() {
+			defer close(hooksDone)
+			opts.OnShutdown()
+		})
+	} else {
+		close(hooksDone)
+	}
+
+	listenErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "server", opts.Name, "addr", ln.Addr().String())
+		listenErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-listenErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down, draining in-flight requests", "server", opts.Name, "timeout", opts.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+		srv.Close()
+	}
+	select {
+	case <-hooksDone:
+	case <-shutdownCtx.Done():
+	}
+	if opts.Cleanup != nil {
+		if err := opts.Cleanup(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := <-listenErr; !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	slog.Info("stopped", "server", opts.Name)
+	return nil
+}
+
+func limitBody(next http.Handler, limit int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
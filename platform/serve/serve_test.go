@@ -0,0 +1,147 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// start serves opts on a free port until the returned cancel is called,
+// and returns the base URL and the channel serve's result arrives on.
+func start(t *testing.T, opts Options) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, ln, opts) }()
+	return "http://" + ln.Addr().String(), cancel, done
+}
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	var finished, hookRan, cleanedUp atomic.Bool
+	url, cancel, done := start(t, Options{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			finished.Store(true)
+			io.WriteString(w, "done")
+		}),
+		ShutdownTimeout: 2 * time.Second,
+		OnShutdown:      func() { hookRan.Store(true) },
+		Cleanup: func(ctx context.Context) error {
+			if !finished.Load() {
+				t.Error("Cleanup ran before the in-flight request finished")
+			}
+			cleanedUp.Store(true)
+			return nil
+		},
+	})
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{string(body), err}
+	}()
+
+	<-started
+	began := time.Now()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve returned %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("serve did not return within the shutdown timeout")
+	}
+	if elapsed := time.Since(began); elapsed > 2*time.Second {
+		t.Fatalf("shutdown took %s", elapsed)
+	}
+	if r := <-response; r.err != nil || r.body != "done" {
+		t.Fatalf("in-flight request got %q, %v", r.body, r.err)
+	}
+	if !hookRan.Load() || !cleanedUp.Load() {
+		t.Fatalf("OnShutdown ran %v, Cleanup ran %v", hookRan.Load(), cleanedUp.Load())
+	}
+
+	// The listener is closed, so new requests are refused.
+	if _, err := http.Get(url + "/late"); err == nil {
+		t.Fatal("a request after shutdown was served")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	var cleanupErr error
+	url, cancel, done := start(t, Options{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		}),
+		ShutdownTimeout: 100 * time.Millisecond,
+		Cleanup: func(ctx context.Context) error {
+			// Cleanup still runs, with the expired deadline.
+			cleanupErr = ctx.Err()
+			return nil
+		},
+	})
+	go http.Get(url + "/stuck")
+
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("serve returned %v, want the deadline exceeded", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("serve waited past its shutdown timeout")
+	}
+	if !errors.Is(cleanupErr, context.DeadlineExceeded) {
+		t.Fatalf("Cleanup saw %v", cleanupErr)
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	url, _, _ := start(t, Options{
+		MaxBodyBytes: 8,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := io.ReadAll(r.Body); err != nil {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			}
+		}),
+	})
+	for body, want := range map[string]int{"small": http.StatusOK, "far too large": http.StatusRequestEntityTooLarge} {
+		resp, err := http.Post(url, "text/plain", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%d-byte body: %d, want %d", len(body), resp.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,742 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/logging"
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/router"
+
+    "digital-oracle-server/config"
+    "digital-oracle-server/payments"
+)
+
+// The unversioned /api paths predate /api/v1. They keep answering, with
+// Deprecation and Sunset headers, until pages cached on phones have had
+// time to pick up the versioned ones.
+var (
+    legacyAPIDeprecated = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+    legacyAPISunset     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// api holds what the handlers need. Each API version gets its own routes
+// method so a v2 can change payloads without touching v1's handlers.
+type api struct {
+    features   config.Features
+    adminToken string
+
+    submissions *fileStore
+    ballots     *ballotStore
+    bank        *contributionStore
+    campaigns   *campaignStore
+    payouts     *payoutStore
+    reports     *reportPublisher
+    moderation  *moderator
+    provider    payments.Provider
+
+    refundWindow       time.Duration
+    defaultOverfunding overfundingPolicy
+
+    pledgeMu sync.Mutex
+
+    spec http.Handler
+}
+
+// mount serves v1 under /api/v1 and, deprecated, under /api.
+func (a *api) mount(mux *http.ServeMux) {
+    router.Mount(mux, "/api/v1", a.routesV1, router.Options{
+        Aliases: []router.Alias{{Prefix: "/api", Deprecated: legacyAPIDeprecated, Sunset: legacyAPISunset}},
+        NotAllowed: func(w http.ResponseWriter, r *http.Request) {
+            methodNotAllowed(w)
+        },
+    })
+}
+
+func (a *api) routesV1(g *router.Group) {
+    g.Handle("GET /openapi.json", a.spec)
+
+    auditions := g.With(a.feature(a.features.Auditions))
+    auditions.HandleFunc("POST /auditions", a.createAudition)
+    auditions.HandleFunc("GET /auditions", a.admin(a.listAuditions))
+
+    voting := g.With(a.feature(a.features.Voting))
+    voting.HandleFunc("GET /ballot", a.getBallot)
+    voting.HandleFunc("POST /ballot", a.admin(a.setBallot))
+    voting.HandleFunc("POST /ballot/close", a.admin(a.closeBallot))
+    voting.HandleFunc("POST /vote", a.castVote)
+
+    bank := g.With(a.feature(a.features.SignalBank))
+    bank.HandleFunc("POST /signal-bank/contributions", a.createContribution)
+    bank.HandleFunc("GET /signal-bank/contributions", a.listContributions)
+    bank.HandleFunc("POST /signal-bank/webhook", a.paymentWebhook)
+    bank.HandleFunc("GET /signal-bank/campaigns", a.listCampaigns)
+    bank.HandleFunc("POST /signal-bank/campaigns", a.admin(a.createCampaign))
+    bank.HandleFunc("PATCH /signal-bank/campaigns", a.admin(a.updateCampaign))
+    bank.HandleFunc("GET /signal-bank/balance", a.getBalance)
+    bank.HandleFunc("POST /signal-bank/refund-requests", a.requestRefund)
+    bank.HandleFunc("GET /signal-bank/admin/refunds", a.admin(a.listRefunds))
+    bank.HandleFunc("POST /signal-bank/admin/refunds", a.admin(a.resolveRefund))
+    bank.HandleFunc("GET /signal-bank/admin/payouts", a.admin(a.listPayouts))
+    bank.HandleFunc("POST /signal-bank/admin/payouts", a.admin(a.createPayout))
+    bank.HandleFunc("POST /signal-bank/admin/moderation", a.admin(a.moderateContribution))
+    bank.HandleFunc("GET /signal-bank/admin/contributions", a.admin(a.adminContributions))
+
+    reports := g.With(a.feature(a.features.SignalBank && a.features.Reports))
+    reports.HandleFunc("GET /signal-bank/reports", a.listReports)
+    reports.HandleFunc("POST /signal-bank/admin/reports", a.admin(a.publishReport))
+}
+
+// feature answers 404 for routes of a feature switched off in the
+// configuration.
+func (a *api) feature(enabled bool) router.Middleware {
+    return func(next http.Handler) http.Handler {
+        if enabled {
+            return next
+        }
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            writeError(w, http.StatusNotFound, "feature_disabled", "this feature is switched off")
+        })
+    }
+}
+
+// admin guards a handler with the admin token.
+func (a *api) admin(next http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if requireAdmin(w, r, a.adminToken) {
+            next(w, r)
+        }
+    }
+}
+
+// createAudition records an audition submission.
+func (a *api) createAudition(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        Name         string `json:"name"`
+        Country      string `json:"country"`
+        SocialHandle string `json:"socialHandle"`
+        VideoURL     string `json:"videoUrl"`
+        Message      string `json:"message"`
+    }
+
+    if !decodeBody(w, r, auditionRequest, &payload) {
+        return
+    }
+
+    created, err := a.submissions.add(submission{
+        Name:         payload.Name,
+        Country:      payload.Country,
+        SocialHandle: payload.SocialHandle,
+        VideoURL:     payload.VideoURL,
+        Message:      payload.Message,
+    })
+    if err != nil {
+        respondError(w, r, err, "failed to record submission")
+        return
+    }
+    auditionsReceived.Inc()
+
+    writeJSON(w, http.StatusCreated, created)
+}
+
+// listAuditions returns submissions, optionally filtered by country and capped by limit.
+func (a *api) listAuditions(w http.ResponseWriter, r *http.Request) {
+    submissions := a.submissions.list()
+
+    countryFilter := strings.TrimSpace(r.URL.Query().Get("country"))
+    if countryFilter != "" {
+        filtered := make([]submission, 0, len(submissions))
+        for _, sub := range submissions {
+            if strings.EqualFold(sub.Country, countryFilter) {
+                filtered = append(filtered, sub)
+            }
+        }
+        submissions = filtered
+    }
+
+    limitStr := strings.TrimSpace(r.URL.Query().Get("limit"))
+    if limitStr != "" {
+        if limit, err := strconv.Atoi(limitStr); err == nil && limit >= 0 && limit < len(submissions) {
+            submissions = submissions[:limit]
+        }
+    }
+
+    writeJSON(w, http.StatusOK, submissions)
+}
+
+// getBallot returns the active ballot with its vote counts.
+func (a *api) getBallot(w http.ResponseWriter, r *http.Request) {
+    state := a.ballots.activeBallot()
+    writeJSON(w, http.StatusOK, state)
+}
+
+// setBallot replaces the ballot with one built from existing submissions.
+func (a *api) setBallot(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        Title       string   `json:"title"`
+        Description string   `json:"description"`
+        ClosesAt    string   `json:"closesAt"`
+        NomineeIDs  []string `json:"nomineeIds"`
+        Active      *bool    `json:"active"`
+    }
+
+    if !decodeBody(w, r, ballotRequest, &payload) {
+        return
+    }
+
+    nominees := make([]ballotNominee, 0, len(payload.NomineeIDs))
+    for _, id := range payload.NomineeIDs {
+        sub, ok := a.submissions.getByID(id)
+        if !ok {
+            respondError(w, r, fmt.Errorf("%w: %s", ErrSubmissionNotFound, id), "invalid ballot")
+            return
+        }
+        nominees = append(nominees, ballotNominee{
+            ID:           sub.ID,
+            SubmissionID: sub.ID,
+            Name:         sub.Name,
+            Country:      sub.Country,
+            SocialHandle: sub.SocialHandle,
+            VideoURL:     sub.VideoURL,
+            Message:      sub.Message,
+            Votes:        0,
+        })
+    }
+
+    closesAt := time.Time{}
+    if payload.ClosesAt != "" {
+        closesAt, _ = time.Parse(time.RFC3339, payload.ClosesAt)
+    }
+
+    active := true
+    if payload.Active != nil {
+        active = *payload.Active
+    }
+
+    newState := ballotState{
+        ID:          fmt.Sprintf("ballot-%d", time.Now().UnixNano()),
+        Title:       payload.Title,
+        Description: payload.Description,
+        Nominees:    nominees,
+        Active:      active,
+        CreatedAt:   time.Now().UTC(),
+        ClosesAt:    closesAt,
+    }
+
+    if err := a.ballots.setBallot(newState); err != nil {
+        respondError(w, r, err, "failed to set ballot")
+        return
+    }
+
+    writeJSON(w, http.StatusCreated, newState)
+}
+
+// closeBallot ends voting on the active ballot. Unlike posting a new ballot
+// with active set to false, the votes are kept.
+func (a *api) closeBallot(w http.ResponseWriter, r *http.Request) {
+    closed, err := a.ballots.closeBallot()
+    if err != nil {
+        respondError(w, r, err, "failed to close ballot")
+        return
+    }
+    writeJSON(w, http.StatusOK, closed)
+}
+
+// castVote records one vote per email on the active ballot.
+func (a *api) castVote(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        NomineeID string `json:"nomineeId"`
+        Email     string `json:"email"`
+        Name      string `json:"name"`
+    }
+
+    if !decodeBody(w, r, voteRequest, &payload) {
+        return
+    }
+
+    updated, err := a.ballots.addVote(payload.Email, payload.NomineeID)
+    if err != nil {
+        respondError(w, r, err, "failed to record vote")
+        return
+    }
+    votesCast.Inc(updated.ID)
+
+    votes := 0
+    for _, nominee := range updated.Nominees {
+        if nominee.ID == payload.NomineeID {
+            votes = nominee.Votes
+            break
+        }
+    }
+
+    response := struct {
+        BallotID  string `json:"ballotId"`
+        NomineeID string `json:"nomineeId"`
+        Votes     int    `json:"votes"`
+        Message   string `json:"message"`
+    }{
+        BallotID:  updated.ID,
+        NomineeID: payload.NomineeID,
+        Votes:     votes,
+        Message:   "Vote recorded. Thank you for supporting the contenders!",
+    }
+
+    writeJSON(w, http.StatusOK, response)
+}
+
+// createContribution starts a payment and records the contribution as pending.
+func (a *api) createContribution(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        Name       string  `json:"name"`
+        Visibility string  `json:"visibility"`
+        Amount     float64 `json:"amount"`
+        Message    string  `json:"message"`
+        CampaignID string  `json:"campaignId"`
+    }
+
+    if !decodeBody(w, r, contributionRequest, &payload) {
+        return
+    }
+
+    shownAs := visibilityPublic
+    if payload.Visibility != "" {
+        shownAs = visibility(payload.Visibility)
+    }
+
+    hideMessage := false
+    moderationNote := ""
+    if word, ok := a.moderation.flagged(payload.Message); ok {
+        hideMessage = true
+        moderationNote = fmt.Sprintf("held by blocklist filter (%q)", word)
+    }
+
+    if a.provider == nil {
+        writeError(w, http.StatusServiceUnavailable, "payments_unavailable", "contributions are not being accepted right now")
+        return
+    }
+
+    if payload.CampaignID != "" {
+        // Serialise campaign pledges so two contributions cannot
+        // both squeeze under a capped goal.
+        a.pledgeMu.Lock()
+        defer a.pledgeMu.Unlock()
+
+        target, ok := a.campaigns.getByID(payload.CampaignID)
+        if !ok {
+            respondError(w, r, ErrCampaignNotFound, "invalid contribution")
+            return
+        }
+        if err := checkCampaignContribution(target, payload.Amount, a.bank.list(), time.Now()); err != nil {
+            respondError(w, r, err, "invalid contribution")
+            return
+        }
+    }
+
+    claimToken, claimHash, err := newClaimToken()
+    if err != nil {
+        respondError(w, r, err, "failed to record contribution")
+        return
+    }
+
+    intent, err := a.provider.CreateIntent(r.Context(), payments.IntentRequest{
+        AmountCents:    payments.ToCents(payload.Amount),
+        Currency:       "usd",
+        Description:    "Signal Bank contribution",
+        Metadata:       map[string]string{"ledger": "signal-bank", "campaign": payload.CampaignID},
+        IdempotencyKey: r.Header.Get("Idempotency-Key"),
+    })
+    if err != nil {
+        logging.FromContext(r.Context()).Error("failed to create payment intent", "amount", payload.Amount, "err", err)
+        writeError(w, http.StatusBadGateway, "payment_provider_error", "failed to start payment")
+        return
+    }
+
+    entry, err := a.bank.add(contribution{
+        Name:           payload.Name,
+        Visibility:     shownAs,
+        Amount:         payload.Amount,
+        Message:        payload.Message,
+        MessageHidden:  hideMessage,
+        ModerationNote: moderationNote,
+        CampaignID:     payload.CampaignID,
+        Status:         payments.StatusPending,
+        Provider:       a.provider.Name(),
+        PaymentID:      intent.ID,
+        ClaimHash:      claimHash,
+    })
+    if err != nil {
+        respondError(w, r, err, "failed to record contribution")
+        return
+    }
+
+    // The contributor gets the token; only its hash is kept, and that
+    // stays on the server.
+    response := struct {
+        contribution
+        ClientSecret string `json:"clientSecret"`
+        ClaimToken   string `json:"claimToken"`
+        RefundWindow string `json:"refundWindow"`
+    }{
+        contribution: entry,
+        ClientSecret: intent.ClientSecret,
+        ClaimToken:   claimToken,
+        RefundWindow: a.refundWindow.String(),
+    }
+
+    writeJSON(w, http.StatusCreated, response)
+}
+
+// listContributions is the public feed of confirmed contributions.
+func (a *api) listContributions(w http.ResponseWriter, r *http.Request) {
+    confirmed := a.bank.listByStatus(payments.StatusConfirmed)
+
+    limitStr := strings.TrimSpace(r.URL.Query().Get("limit"))
+    if limitStr != "" {
+        if limit, err := strconv.Atoi(limitStr); err == nil && limit >= 0 && limit < len(confirmed) {
+            confirmed = confirmed[:limit]
+        }
+    }
+
+    feed := make([]publicContribution, 0, len(confirmed))
+    for _, entry := range confirmed {
+        feed = append(feed, entry.public())
+    }
+
+    writeJSON(w, http.StatusOK, feed)
+}
+
+// paymentWebhook applies payment status changes pushed by the provider.
+func (a *api) paymentWebhook(w http.ResponseWriter, r *http.Request) {
+    if a.provider == nil {
+        writeError(w, http.StatusServiceUnavailable, "payments_unavailable", "payments not configured")
+        return
+    }
+
+    body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+    if err != nil {
+        writeError(w, http.StatusBadRequest, "invalid_body", "failed to read body")
+        return
+    }
+
+    event, err := a.provider.ParseWebhook(body, r.Header.Get(payments.SignatureHeader))
+    switch {
+    case errors.Is(err, payments.ErrInvalidSignature):
+        logging.FromContext(r.Context()).Warn("rejected webhook with bad signature")
+        writeError(w, http.StatusBadRequest, "invalid_signature", "invalid signature")
+        return
+    case errors.Is(err, payments.ErrUnhandledEvent):
+        // Acknowledge so the provider stops retrying events we ignore.
+        w.WriteHeader(http.StatusOK)
+        return
+    case err != nil:
+        logging.FromContext(r.Context()).Warn("failed to parse webhook", "err", err)
+        writeError(w, http.StatusBadRequest, "invalid_event", "invalid event")
+        return
+    }
+
+    entry, changed, err := a.bank.updateStatus(event.IntentID, event.Status)
+    if errors.Is(err, ErrUnknownPayment) {
+        logging.FromContext(r.Context()).Warn("webhook references unknown payment", "event", event.ID, "payment", event.IntentID)
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+    if err != nil {
+        respondError(w, r, err, "failed to apply event "+event.ID)
+        return
+    }
+
+    if changed {
+        logging.FromContext(r.Context()).Info("contribution status changed", "contribution", entry.ID, "status", entry.Status, "event", event.ID)
+    }
+    w.WriteHeader(http.StatusOK)
+}
+
+// listCampaigns returns every campaign with its progress.
+func (a *api) listCampaigns(w http.ResponseWriter, r *http.Request) {
+    contributions := a.bank.list()
+    now := time.Now()
+
+    campaigns := a.campaigns.list()
+    out := make([]campaignProgress, 0, len(campaigns))
+    for _, c := range campaigns {
+        out = append(out, campaignProgressFor(c, contributions, now))
+    }
+
+    writeJSON(w, http.StatusOK, out)
+}
+
+// createCampaign opens a fundraising campaign.
+func (a *api) createCampaign(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        Title       string  `json:"title"`
+        Description string  `json:"description"`
+        Goal        float64 `json:"goal"`
+        Deadline    string  `json:"deadline"`
+        Beneficiary string  `json:"beneficiary"`
+        Overfunding string  `json:"overfunding"`
+    }
+
+    if !decodeBody(w, r, campaignRequest, &payload) {
+        return
+    }
+
+    deadline := time.Time{}
+    if payload.Deadline != "" {
+        ts, _ := time.Parse(time.RFC3339, payload.Deadline)
+        deadline = ts.UTC()
+    }
+
+    policy := a.defaultOverfunding
+    if payload.Overfunding != "" {
+        policy = overfundingPolicy(payload.Overfunding)
+    }
+
+    created, err := a.campaigns.add(campaign{
+        Title:       payload.Title,
+        Description: payload.Description,
+        Goal:        payload.Goal,
+        Deadline:    deadline,
+        Beneficiary: payload.Beneficiary,
+        Overfunding: policy,
+    })
+    if err != nil {
+        respondError(w, r, err, "failed to create campaign")
+        return
+    }
+
+    writeJSON(w, http.StatusCreated, created)
+}
+
+// updateCampaign changes a campaign's status.
+func (a *api) updateCampaign(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        ID     string `json:"id"`
+        Status string `json:"status"`
+    }
+
+    if !decodeBody(w, r, campaignUpdateRequest, &payload) {
+        return
+    }
+
+    updated, err := a.campaigns.setStatus(payload.ID, campaignStatus(payload.Status))
+    if err != nil {
+        respondError(w, r, err, "failed to update campaign")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, updated)
+}
+
+// getBalance reports the ledger totals.
+func (a *api) getBalance(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, a.bank.balance(time.Now()))
+}
+
+// requestRefund lets a contributor ask for their money back with the claim token.
+func (a *api) requestRefund(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        ClaimToken string `json:"claimToken"`
+        Reason     string `json:"reason"`
+    }
+
+    if !decodeBody(w, r, refundRequestRequest, &payload) {
+        return
+    }
+
+    entry, err := a.bank.requestRefund(payload.ClaimToken, payload.Reason, time.Now())
+    if err != nil {
+        respondError(w, r, err, "failed to record refund request")
+        return
+    }
+
+    writeJSON(w, http.StatusAccepted, struct {
+        ContributionID string       `json:"contributionId"`
+        Status         refundStatus `json:"status"`
+        Message        string       `json:"message"`
+    }{
+        ContributionID: entry.ID,
+        Status:         entry.Refund.Status,
+        Message:        "Refund requested. An admin will process it shortly.",
+    })
+}
+
+// listRefunds returns contributions with a refund request.
+func (a *api) listRefunds(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, a.bank.refundRequests())
+}
+
+// resolveRefund approves or rejects a refund request, refunding through the provider on approval.
+func (a *api) resolveRefund(w http.ResponseWriter, r *http.Request) {
+    if a.provider == nil {
+        writeError(w, http.StatusServiceUnavailable, "payments_unavailable", "payments not configured")
+        return
+    }
+
+    var payload struct {
+        ContributionID string `json:"contributionId"`
+        Action         string `json:"action"`
+        Note           string `json:"note"`
+    }
+
+    if !decodeBody(w, r, refundActionRequest, &payload) {
+        return
+    }
+
+    entry, err := a.bank.resolveRefund(payload.ContributionID, payload.Action == "approve", payload.Note)
+    if err != nil {
+        respondError(w, r, err, "failed to resolve refund")
+        return
+    }
+
+    if payload.Action == "approve" {
+        refund, refundErr := a.provider.Refund(r.Context(), payments.RefundRequest{
+            IntentID:       entry.PaymentID,
+            AmountCents:    payments.ToCents(entry.Amount),
+            Reason:         entry.Refund.Reason,
+            IdempotencyKey: "refund-" + entry.ID,
+        })
+        if refundErr != nil {
+            logging.FromContext(r.Context()).Error("provider refused refund", "contribution", entry.ID, "err", refundErr)
+        }
+
+        entry, err = a.bank.recordRefund(entry.ID, refund, refundErr)
+        if err != nil {
+            respondError(w, r, err, "failed to record refund")
+            return
+        }
+        if refundErr != nil {
+            writeError(w, http.StatusBadGateway, "payment_provider_error", "payment provider rejected the refund")
+            return
+        }
+    }
+
+    writeJSON(w, http.StatusOK, entry)
+}
+
+// listPayouts returns recorded payouts.
+func (a *api) listPayouts(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, a.payouts.list())
+}
+
+// createPayout records money paid out of the bank.
+func (a *api) createPayout(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        Amount     float64  `json:"amount"`
+        Recipient  string   `json:"recipient"`
+        Reason     string   `json:"reason"`
+        ApprovedBy []string `json:"approvedBy"`
+        PaidAt     string   `json:"paidAt"`
+    }
+
+    if !decodeBody(w, r, payoutRequest, &payload) {
+        return
+    }
+
+    paidAt := time.Time{}
+    if payload.PaidAt != "" {
+        ts, _ := time.Parse(time.RFC3339, payload.PaidAt)
+        paidAt = ts.UTC()
+    }
+
+    entry, err := a.payouts.add(payout{
+        Amount:     payload.Amount,
+        Recipient:  payload.Recipient,
+        Reason:     payload.Reason,
+        ApprovedBy: payload.ApprovedBy,
+        PaidAt:     paidAt,
+    })
+    if err != nil {
+        respondError(w, r, err, "failed to record payout")
+        return
+    }
+
+    writeJSON(w, http.StatusCreated, entry)
+}
+
+// listReports returns the published transparency reports and the key that signs them.
+func (a *api) listReports(w http.ResponseWriter, r *http.Request) {
+    published, err := a.reports.list()
+    if err != nil {
+        respondError(w, r, err, "failed to list reports")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, struct {
+        PublicKey string            `json:"publicKey"`
+        Reports   []publishedReport `json:"reports"`
+    }{
+        PublicKey: a.reports.publicKey(),
+        Reports:   published,
+    })
+}
+
+// publishReport builds and signs a report for the period containing date.
+func (a *api) publishReport(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        Period string `json:"period"`
+        Date   string `json:"date"`
+    }
+
+    if !decodeBody(w, r, reportRequest, &payload) {
+        return
+    }
+
+    ref := time.Now()
+    if payload.Date != "" {
+        ref, _ = time.Parse("2006-01-02", payload.Date)
+    }
+
+    published, err := a.reports.publish(payload.Period, ref)
+    if err != nil {
+        respondError(w, r, err, "failed to publish report")
+        return
+    }
+
+    writeJSON(w, http.StatusCreated, published)
+}
+
+// moderateContribution hides or restores a contribution's message.
+func (a *api) moderateContribution(w http.ResponseWriter, r *http.Request) {
+    var payload struct {
+        ContributionID string `json:"contributionId"`
+        Hidden         bool   `json:"hidden"`
+        Note           string `json:"note"`
+    }
+
+    if !decodeBody(w, r, moderationRequest, &payload) {
+        return
+    }
+
+    entry, err := a.bank.setMessageHidden(payload.ContributionID, payload.Hidden, payload.Note)
+    if err != nil {
+        respondError(w, r, err, "failed to update contribution")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, entry)
+}
+
+// adminContributions lists every contribution, optionally by status or held messages only.
+func (a *api) adminContributions(w http.ResponseWriter, r *http.Request) {
+    contributions := a.bank.list()
+    if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+        contributions = a.bank.listByStatus(payments.Status(status))
+    }
+
+    if r.URL.Query().Get("hidden") == "true" {
+        filtered := make([]contribution, 0, len(contributions))
+        for _, entry := range contributions {
+            if entry.MessageHidden {
+                filtered = append(filtered, entry)
+            }
+        }
+        contributions = filtered
+    }
+
+    writeJSON(w, http.StatusOK, contributions)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math"
+    "os"
+    "sort"
+    "sync"
+    "time"
+
+    "digital-oracle-server/payments"
+)
+
+type campaignStatus string
+
+const (
+    campaignActive    campaignStatus = "active"
+    campaignClosed    campaignStatus = "closed"
+    campaignCancelled campaignStatus = "cancelled"
+)
+
+// overfundingPolicy decides what happens to money beyond a campaign's goal.
+type overfundingPolicy string
+
+const (
+    // overfundCap refuses contributions that would push past the goal.
+    overfundCap overfundingPolicy = "cap"
+    // overfundRollover accepts them and counts the excess toward the general pool.
+    overfundRollover overfundingPolicy = "rollover"
+)
+
+type campaign struct {
+    ID          string            `json:"id"`
+    Title       string            `json:"title"`
+    Description string            `json:"description"`
+    Goal        float64           `json:"goal"`
+    Deadline    time.Time         `json:"deadline"`
+    Beneficiary string            `json:"beneficiary"`
+    Status      campaignStatus    `json:"status"`
+    Overfunding overfundingPolicy `json:"overfunding"`
+    CreatedAt   time.Time         `json:"createdAt"`
+}
+
+type campaignProgress struct {
+    campaign
+    Raised       float64 `json:"raised"`
+    Pending      float64 `json:"pending"`
+    RolledOver   float64 `json:"rolledOver"`
+    Percent      float64 `json:"percent"`
+    Contributors int     `json:"contributors"`
+    State        string  `json:"state"`
+}
+
+type campaignStore struct {
+    path      string
+    mu        sync.Mutex
+    campaigns []campaign
+}
+
+func newCampaignStore(path string) (*campaignStore, error) {
+    store := &campaignStore{path: path}
+    if err := store.load(); err != nil {
+        return nil, err
+    }
+    return store, nil
+}
+
+func (s *campaignStore) load() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+        s.campaigns = []campaign{}
+        return nil
+    }
+
+    data, err := os.ReadFile(s.path)
+    if err != nil {
+        return err
+    }
+
+    if len(data) == 0 {
+        s.campaigns = []campaign{}
+        return nil
+    }
+
+    return json.Unmarshal(data, &s.campaigns)
+}
+
+func (s *campaignStore) saveLocked() error {
+    data, err := json.MarshalIndent(s.campaigns, "", "  ")
+    if err != nil {
+        return err
+    }
+
+    return writeStoreFile("campaigns", s.path, data)
+}
+
+func (s *campaignStore) add(c campaign) (campaign, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    c.ID = fmt.Sprintf("campaign-%d", time.Now().UnixNano())
+    c.CreatedAt = time.Now().UTC()
+    c.Status = campaignActive
+    s.campaigns = append([]campaign{c}, s.campaigns...)
+
+    if err := s.saveLocked(); err != nil {
+        return campaign{}, err
+    }
+    return c, nil
+}
+
+func (s *campaignStore) setStatus(id string, status campaignStatus) (campaign, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for i := range s.campaigns {
+        if s.campaigns[i].ID != id {
+            continue
+        }
+        s.campaigns[i].Status = status
+        if err := s.saveLocked(); err != nil {
+            return campaign{}, err
+        }
+        return s.campaigns[i], nil
+    }
+    return campaign{}, ErrCampaignNotFound
+}
+
+func (s *campaignStore) getByID(id string) (campaign, bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for _, c := range s.campaigns {
+        if c.ID == id {
+            return c, true
+        }
+    }
+    return campaign{}, false
+}
+
+func (s *campaignStore) list() []campaign {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    out := make([]campaign, len(s.campaigns))
+    copy(out, s.campaigns)
+    return out
+}
+
+// campaignProgressFor computes progress from the ledger. Confirmed money is
+// applied in confirmation order; under the rollover policy anything beyond
+// the goal is reported as rolled over to the general pool.
+func campaignProgressFor(c campaign, contributions []contribution, now time.Time) campaignProgress {
+    progress := campaignProgress{campaign: c}
+
+    confirmed := make([]contribution, 0)
+    for _, entry := range contributions {
+        if entry.CampaignID != c.ID {
+            continue
+        }
+        switch entry.Status {
+        case payments.StatusConfirmed:
+            confirmed = append(confirmed, entry)
+        case payments.StatusPending:
+            progress.Pending += entry.Amount
+        }
+    }
+    sort.SliceStable(confirmed, func(i, j int) bool {
+        return confirmedAt(confirmed[i]).Before(confirmedAt(confirmed[j]))
+    })
+
+    for _, entry := range confirmed {
+        progress.Contributors++
+        room := c.Goal - progress.Raised
+        if c.Overfunding == overfundRollover && entry.Amount > room {
+            progress.Raised += math.Max(room, 0)
+            progress.RolledOver += entry.Amount - math.Max(room, 0)
+            continue
+        }
+        progress.Raised += entry.Amount
+    }
+
+    progress.Raised = roundCents(progress.Raised)
+    progress.RolledOver = roundCents(progress.RolledOver)
+    progress.Pending = roundCents(progress.Pending)
+    if c.Goal > 0 {
+        progress.Percent = math.Min(100, math.Round(progress.Raised/c.Goal*1000)/10)
+    }
+
+    switch {
+    case c.Status != campaignActive:
+        progress.State = string(c.Status)
+    case progress.Raised >= c.Goal:
+        progress.State = "funded"
+    case !c.Deadline.IsZero() && now.After(c.Deadline):
+        progress.State = "expired"
+    default:
+        progress.State = "open"
+    }
+    return progress
+}
+
+// confirmedAt orders confirmed contributions. Records confirmed before the
+// time was kept have none, so they fall back to their last update.
+func confirmedAt(entry contribution) time.Time {
+    if entry.ConfirmedAt != nil {
+        return *entry.ConfirmedAt
+    }
+    return entry.UpdatedAt
+}
+
+// pendingHoldTTL is how long an unpaid contribution keeps its place under a
+// capped goal. Abandoned payment intents never get a failure webhook, so
+// without a limit they would fill the cap for good.
+const pendingHoldTTL = 30 * time.Minute
+
+// checkCampaignContribution decides whether amount may be pledged to c given
+// what is already confirmed, or pending and created within pendingHoldTTL.
+// A stale pending payment that confirms after all still counts once it does.
+func checkCampaignContribution(c campaign, amount float64, contributions []contribution, now time.Time) error {
+    if c.Status != campaignActive || (!c.Deadline.IsZero() && now.After(c.Deadline)) {
+        return ErrCampaignClosed
+    }
+    if c.Overfunding != overfundCap {
+        return nil
+    }
+
+    progress := campaignProgressFor(c, contributions, now)
+    held := 0.0
+    for _, entry := range contributions {
+        if entry.CampaignID == c.ID && entry.Status == payments.StatusPending && now.Sub(entry.CreatedAt) < pendingHoldTTL {
+            held += entry.Amount
+        }
+    }
+    if progress.Raised+held+amount > c.Goal+0.005 {
+        return ErrCampaignFull
+    }
+    return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "digital-oracle-server/payments"
+)
+
+func TestCheckCampaignContribution(t *testing.T) {
+    now := time.Date(2026, time.October, 10, 12, 0, 0, 0, time.UTC)
+    capped := campaign{ID: "c1", Goal: 100, Status: campaignActive, Overfunding: overfundCap, Deadline: now.Add(24 * time.Hour)}
+    paid := func(amount float64) contribution {
+        at := now.Add(-time.Hour)
+        return contribution{CampaignID: "c1", Amount: amount, Status: payments.StatusConfirmed, ConfirmedAt: &at}
+    }
+    pending := func(amount float64, age time.Duration) contribution {
+        return contribution{CampaignID: "c1", Amount: amount, Status: payments.StatusPending, CreatedAt: now.Add(-age)}
+    }
+
+    tests := []struct {
+        name          string
+        campaign      campaign
+        amount        float64
+        contributions []contribution
+        want          error
+    }{
+        {name: "fills the goal exactly", campaign: capped, amount: 40, contributions: []contribution{paid(60)}},
+        {name: "a cent over the goal", campaign: capped, amount: 40.01, contributions: []contribution{paid(60)}, want: ErrCampaignFull},
+        {name: "rounding inside half a cent", campaign: capped, amount: 40.004, contributions: []contribution{paid(60)}},
+        {name: "fresh hold counts", campaign: capped, amount: 10.01, contributions: []contribution{paid(60), pending(30, 10*time.Minute)}, want: ErrCampaignFull},
+        {name: "room beside a fresh hold", campaign: capped, amount: 10, contributions: []contribution{paid(60), pending(30, 10*time.Minute)}},
+        {name: "hold expires at the TTL", campaign: capped, amount: 40, contributions: []contribution{paid(60), pending(30, pendingHoldTTL)}},
+        {name: "stale hold is ignored", campaign: capped, amount: 40, contributions: []contribution{paid(60), pending(30, 2*time.Hour)}},
+        {name: "failed payments hold nothing", campaign: capped, amount: 40, contributions: []contribution{paid(60), {CampaignID: "c1", Amount: 30, Status: payments.StatusFailed, CreatedAt: now}}},
+        {name: "other campaigns do not count", campaign: capped, amount: 100, contributions: []contribution{{CampaignID: "c2", Amount: 90, Status: payments.StatusConfirmed}}},
+        {name: "already funded", campaign: capped, amount: 1, contributions: []contribution{paid(100)}, want: ErrCampaignFull},
+        {name: "rollover takes the excess", campaign: campaign{ID: "c1", Goal: 100, Status: campaignActive, Overfunding: overfundRollover}, amount: 500, contributions: []contribution{paid(100)}},
+        {name: "closed", campaign: campaign{ID: "c1", Goal: 100, Status: campaignClosed, Overfunding: overfundCap}, amount: 1, want: ErrCampaignClosed},
+        {name: "past the deadline", campaign: campaign{ID: "c1", Goal: 100, Status: campaignActive, Overfunding: overfundRollover, Deadline: now.Add(-time.Second)}, amount: 1, want: ErrCampaignClosed},
+    }
+    for _, tt := range tests {
+        if err := checkCampaignContribution(tt.campaign, tt.amount, tt.contributions, now); !errors.Is(err, tt.want) {
+            t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+        }
+    }
+}
+
+func TestCampaignProgressFor(t *testing.T) {
+    now := time.Date(2026, time.October, 10, 12, 0, 0, 0, time.UTC)
+    at := func(minutes int) *time.Time {
+        t := now.Add(time.Duration(minutes) * time.Minute)
+        return &t
+    }
+    // Listed newest first, as the store keeps them; progress applies them
+    // in confirmation order.
+    ledger := []contribution{
+        {CampaignID: "c1", Amount: 30, Status: payments.StatusConfirmed, ConfirmedAt: at(-10)},
+        {CampaignID: "c1", Amount: 20, Status: payments.StatusPending},
+        {CampaignID: "c1", Amount: 50, Status: payments.StatusConfirmed, ConfirmedAt: at(-20)},
+        {CampaignID: "c1", Amount: 15, Status: payments.StatusRefunded, ConfirmedAt: at(-25)},
+        {CampaignID: "c2", Amount: 1000, Status: payments.StatusConfirmed, ConfirmedAt: at(-5)},
+        {CampaignID: "c1", Amount: 70, Status: payments.StatusConfirmed, UpdatedAt: *at(-30)},
+    }
+
+    tests := []struct {
+        name       string
+        campaign   campaign
+        raised     float64
+        rolledOver float64
+        percent    float64
+        state      string
+    }{
+        // 70, then 50 of which 30 fits, then all 30 beyond the goal.
+        {"rollover", campaign{ID: "c1", Goal: 100, Status: campaignActive, Overfunding: overfundRollover}, 100, 50, 100, "funded"},
+        {"rollover fills exactly", campaign{ID: "c1", Goal: 150, Status: campaignActive, Overfunding: overfundRollover}, 150, 0, 100, "funded"},
+        {"cap keeps late confirmations", campaign{ID: "c1", Goal: 100, Status: campaignActive, Overfunding: overfundCap}, 150, 0, 100, "funded"},
+        {"open", campaign{ID: "c1", Goal: 200, Status: campaignActive, Overfunding: overfundCap, Deadline: now.Add(time.Hour)}, 150, 0, 75, "open"},
+        {"expired", campaign{ID: "c1", Goal: 200, Status: campaignActive, Overfunding: overfundCap, Deadline: now.Add(-time.Hour)}, 150, 0, 75, "expired"},
+        {"closed", campaign{ID: "c1", Goal: 100, Status: campaignClosed, Overfunding: overfundRollover}, 100, 50, 100, "closed"},
+    }
+    for _, tt := range tests {
+        got := campaignProgressFor(tt.campaign, ledger, now)
+        if got.Raised != tt.raised || got.RolledOver != tt.rolledOver || got.Percent != tt.percent || got.State != tt.state {
+            t.Errorf("%s: raised %v, rolled over %v, %v%%, %s; want %v, %v, %v%%, %s",
+                tt.name, got.Raised, got.RolledOver, got.Percent, got.State, tt.raised, tt.rolledOver, tt.percent, tt.state)
+        }
+        if got.Pending != 20 || got.Contributors != 3 {
+            t.Errorf("%s: pending %v from %d contributors, want 20 from 3", tt.name, got.Pending, got.Contributors)
+        }
+    }
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+    "flag"
+    "log"
+    "net/http"
+    "os"
+
+    "digital-oracle-server/payments"
+)
+
+// fakepay runs the fake payment provider on its own port so the server can be
+// exercised end to end without a Stripe account:
+//
+//    ORACLE_PAYMENT_API_BASE=http://localhost:8090 go run .
+//    go run ./cmd/fakepay -webhook http://localhost:8080/api/v1/signal-bank/webhook
+func main() {
+    addr := flag.String("addr", ":8090", "listen address")
+    webhookURL := flag.String("webhook", "http://localhost:8080/api/v1/signal-bank/webhook", "URL that receives signed webhooks")
+    secret := flag.String("secret", os.Getenv("ORACLE_PAYMENT_WEBHOOK_SECRET"), "webhook signing secret")
+    flag.Parse()
+
+    if *secret == "" {
+        log.Fatal("a webhook secret is required (-secret or ORACLE_PAYMENT_WEBHOOK_SECRET)")
+    }
+
+    log.Printf("fake payment provider listening on %s, delivering webhooks to %s", *addr, *webhookURL)
+    if err := http.ListenAndServe(*addr, payments.NewFakeServer(*webhookURL, *secret)); err != nil {
+        log.Fatalf("fake provider exited: %v", err)
+    }
+}
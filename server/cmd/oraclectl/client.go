@@ -0,0 +1,179 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+// The server's JSON shapes, as far as oraclectl reads them.
+type submission struct {
+    ID           string    `json:"id"`
+    Name         string    `json:"name"`
+    Country      string    `json:"country"`
+    SocialHandle string    `json:"socialHandle"`
+    VideoURL     string    `json:"videoUrl"`
+    Message      string    `json:"message"`
+    CreatedAt    time.Time `json:"createdAt"`
+}
+
+type nominee struct {
+    ID           string `json:"id"`
+    SubmissionID string `json:"submissionId"`
+    Name         string `json:"name"`
+    Country      string `json:"country"`
+    Votes        int    `json:"votes"`
+}
+
+type ballot struct {
+    ID          string    `json:"id"`
+    Title       string    `json:"title"`
+    Description string    `json:"description"`
+    Nominees    []nominee `json:"nominees"`
+    Active      bool      `json:"active"`
+    CreatedAt   time.Time `json:"createdAt"`
+    ClosesAt    time.Time `json:"closesAt"`
+}
+
+type payout struct {
+    ID         string    `json:"id"`
+    Amount     float64   `json:"amount"`
+    Recipient  string    `json:"recipient"`
+    Reason     string    `json:"reason"`
+    ApprovedBy []string  `json:"approvedBy"`
+    PaidAt     time.Time `json:"paidAt"`
+    CreatedAt  time.Time `json:"createdAt"`
+}
+
+// client calls the /api/v1 routes with the admin token.
+type client struct {
+    base  string
+    token string
+    http  *http.Client
+}
+
+func newClient(server, token string) *client {
+    return &client{
+        base:  strings.TrimSuffix(server, "/") + "/api/v1",
+        token: token,
+        http:  &http.Client{Timeout: 30 * time.Second},
+    }
+}
+
+// do sends body as JSON, if given, and decodes the response into out. The
+// server's error envelope becomes the returned error.
+func (c *client) do(method, path string, query url.Values, body, out interface{}) error {
+    if query == nil {
+        query = url.Values{}
+    }
+    if c.token != "" {
+        query.Set("token", c.token)
+    }
+    target := c.base + path
+    if len(query) > 0 {
+        target += "?" + query.Encode()
+    }
+
+    var reader io.Reader
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reader = bytes.NewReader(data)
+    }
+    req, err := http.NewRequest(method, target, reader)
+    if err != nil {
+        return err
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+
+    resp, err := c.http.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    data, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return err
+    }
+    if resp.StatusCode >= 300 {
+        var envelope struct {
+            Error struct {
+                Code    string `json:"code"`
+                Message string `json:"message"`
+            } `json:"error"`
+        }
+        if json.Unmarshal(data, &envelope) == nil && envelope.Error.Message != "" {
+            return fmt.Errorf("%s %s: %s (%s)", method, path, envelope.Error.Message, envelope.Error.Code)
+        }
+        return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+    }
+    if out == nil {
+        return nil
+    }
+    return json.Unmarshal(data, out)
+}
+
+// Reads work online or offline; each returns what the matching GET route
+// would.
+
+func (c *cli) loadSubmissions() ([]submission, error) {
+    subs := []submission{}
+    if c.api != nil {
+        err := c.api.do(http.MethodGet, "/auditions", nil, nil, &subs)
+        return subs, err
+    }
+    err := readDataFile(c.dir, "submissions.json", &subs)
+    return subs, err
+}
+
+func (c *cli) loadBallot() (ballot, error) {
+    var b ballot
+    if c.api != nil {
+        err := c.api.do(http.MethodGet, "/ballot", nil, nil, &b)
+        return b, err
+    }
+    var file struct {
+        State ballot `json:"state"`
+    }
+    err := readDataFile(c.dir, "ballot.json", &file)
+    return file.State, err
+}
+
+func (c *cli) loadPayouts() ([]payout, error) {
+    payouts := []payout{}
+    if c.api != nil {
+        err := c.api.do(http.MethodGet, "/signal-bank/admin/payouts", nil, nil, &payouts)
+        return payouts, err
+    }
+    err := readDataFile(c.dir, "signal_bank_payouts.json", &payouts)
+    return payouts, err
+}
+
+// readDataFile decodes one of the server's store files. A missing or empty
+// file is an empty store, as it is to the server.
+func readDataFile(dir, name string, out interface{}) error {
+    data, err := os.ReadFile(filepath.Join(dir, name))
+    if errors.Is(err, os.ErrNotExist) || (err == nil && len(bytes.TrimSpace(data)) == 0) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if err := json.Unmarshal(data, out); err != nil {
+        return fmt.Errorf("%s: %w", name, err)
+    }
+    return nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+    "time"
+)
+
+func (c *cli) auditions(args []string) error {
+    flags := flag.NewFlagSet("auditions", flag.ContinueOnError)
+    country := flags.String("country", "", "only submissions from this country")
+    limit := flags.Int("limit", 0, "show at most this many; 0 shows all")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+
+    subs, err := c.loadSubmissions()
+    if err != nil {
+        return err
+    }
+    filtered := make([]submission, 0, len(subs))
+    for _, sub := range subs {
+        if *country == "" || strings.EqualFold(sub.Country, *country) {
+            filtered = append(filtered, sub)
+        }
+    }
+    if *limit > 0 && *limit < len(filtered) {
+        filtered = filtered[:*limit]
+    }
+
+    return c.print(filtered, []string{"ID", "NAME", "COUNTRY", "HANDLE", "SUBMITTED"}, func(row func(...string)) {
+        for _, sub := range filtered {
+            row(sub.ID, sub.Name, sub.Country, sub.SocialHandle, formatTime(sub.CreatedAt))
+        }
+    })
+}
+
+func (c *cli) showBallot(args []string) error {
+    if len(args) > 0 {
+        return fmt.Errorf("unknown ballot subcommand %q; use create or close", args[0])
+    }
+    b, err := c.loadBallot()
+    if err != nil {
+        return err
+    }
+    return c.printBallot(b)
+}
+
+func (c *cli) createBallot(args []string) error {
+    flags := flag.NewFlagSet("ballot create", flag.ContinueOnError)
+    title := flags.String("title", "", "ballot title")
+    description := flags.String("description", "", "ballot description")
+    closes := flags.String("closes", "", "closing time, RFC3339")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() == 0 {
+        return fmt.Errorf("ballot create needs the shortlisted submission IDs")
+    }
+    if *closes != "" {
+        if _, err := time.Parse(time.RFC3339, *closes); err != nil {
+            return fmt.Errorf("-closes: %q is not an RFC3339 time", *closes)
+        }
+    }
+    api, err := c.online("ballot create")
+    if err != nil {
+        return err
+    }
+
+    body := map[string]interface{}{
+        "title":       *title,
+        "description": *description,
+        "nomineeIds":  flags.Args(),
+    }
+    if *closes != "" {
+        body["closesAt"] = *closes
+    }
+    var created ballot
+    if err := api.do(http.MethodPost, "/ballot", nil, body, &created); err != nil {
+        return err
+    }
+    return c.printBallot(created)
+}
+
+func (c *cli) closeBallot(args []string) error {
+    if len(args) > 0 {
+        return fmt.Errorf("ballot close takes no arguments")
+    }
+    api, err := c.online("ballot close")
+    if err != nil {
+        return err
+    }
+    var closed ballot
+    if err := api.do(http.MethodPost, "/ballot/close", nil, nil, &closed); err != nil {
+        return err
+    }
+    return c.printBallot(closed)
+}
+
+func (c *cli) printBallot(b ballot) error {
+    if c.format == "json" {
+        return c.print(b, nil, nil)
+    }
+    if b.ID == "" {
+        fmt.Fprintln(c.out, "No ballot has been set.")
+        return nil
+    }
+
+    status := "open"
+    if !b.Active {
+        status = "closed"
+    }
+    fmt.Fprintf(c.out, "%s  %s (%s)\n", b.ID, b.Title, status)
+    if !b.ClosesAt.IsZero() {
+        fmt.Fprintf(c.out, "closes %s\n", formatTime(b.ClosesAt))
+    }
+    fmt.Fprintln(c.out)
+
+    standings := rank(b)
+    return c.print(nil, []string{"RANK", "NOMINEE", "NAME", "COUNTRY", "VOTES", "SHARE"}, func(row func(...string)) {
+        for _, s := range standings {
+            row(strconv.Itoa(s.Rank), s.ID, s.Name, s.Country, strconv.Itoa(s.Votes), fmt.Sprintf("%.1f%%", s.Share))
+        }
+    })
+}
+
+type standing struct {
+    Rank    int     `json:"rank"`
+    ID      string  `json:"nomineeId"`
+    Name    string  `json:"name"`
+    Country string  `json:"country"`
+    Votes   int     `json:"votes"`
+    Share   float64 `json:"share"`
+}
+
+// rank orders nominees by votes. Tied nominees share a rank.
+func rank(b ballot) []standing {
+    total := 0
+    for _, n := range b.Nominees {
+        total += n.Votes
+    }
+
+    out := make([]standing, 0, len(b.Nominees))
+    for _, n := range b.Nominees {
+        share := 0.0
+        if total > 0 {
+            share = float64(n.Votes) * 100 / float64(total)
+        }
+        out = append(out, standing{ID: n.ID, Name: n.Name, Country: n.Country, Votes: n.Votes, Share: share})
+    }
+    sort.SliceStable(out, func(i, j int) bool { return out[i].Votes > out[j].Votes })
+    for i := range out {
+        out[i].Rank = i + 1
+        if i > 0 && out[i].Votes == out[i-1].Votes {
+            out[i].Rank = out[i-1].Rank
+        }
+    }
+    return out
+}
+
+// results exports the standings for publishing. It ignores -o; the export
+// format is chosen with -format.
+func (c *cli) results(args []string) error {
+    flags := flag.NewFlagSet("results", flag.ContinueOnError)
+    format := flags.String("format", "csv", "csv or json")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+
+    b, err := c.loadBallot()
+    if err != nil {
+        return err
+    }
+    if b.ID == "" {
+        return fmt.Errorf("no ballot has been set")
+    }
+    standings := rank(b)
+
+    switch *format {
+    case "json":
+        enc := json.NewEncoder(c.out)
+        enc.SetIndent("", "  ")
+        return enc.Encode(struct {
+            BallotID  string     `json:"ballotId"`
+            Title     string     `json:"title"`
+            Closed    bool       `json:"closed"`
+            Standings []standing `json:"standings"`
+        }{b.ID, b.Title, !b.Active, standings})
+    case "csv":
+        w := csv.NewWriter(c.out)
+        w.Write([]string{"rank", "nominee_id", "name", "country", "votes", "share_percent"})
+        for _, s := range standings {
+            w.Write([]string{strconv.Itoa(s.Rank), s.ID, s.Name, s.Country, strconv.Itoa(s.Votes), strconv.FormatFloat(s.Share, 'f', 2, 64)})
+        }
+        w.Flush()
+        return w.Error()
+    }
+    return fmt.Errorf("-format must be csv or json, not %q", *format)
+}
+
+func (c *cli) listPayouts(args []string) error {
+    if len(args) > 0 {
+        return fmt.Errorf("payouts takes no arguments; use payout to record one")
+    }
+    payouts, err := c.loadPayouts()
+    if err != nil {
+        return err
+    }
+    return c.print(payouts, []string{"ID", "PAID", "AMOUNT", "RECIPIENT", "APPROVED BY", "REASON"}, func(row func(...string)) {
+        for _, p := range payouts {
+            row(p.ID, formatTime(p.PaidAt), strconv.FormatFloat(p.Amount, 'f', 2, 64), p.Recipient, strings.Join(p.ApprovedBy, ", "), p.Reason)
+        }
+    })
+}
+
+func (c *cli) recordPayout(args []string) error {
+    flags := flag.NewFlagSet("payout", flag.ContinueOnError)
+    amount := flags.Float64("amount", 0, "amount paid out")
+    recipient := flags.String("recipient", "", "who received it")
+    reason := flags.String("reason", "", "what it was for")
+    approvedBy := flags.String("approved-by", "", "comma-separated approvers")
+    paidAt := flags.String("paid-at", "", "when it was paid, RFC3339; defaults to now")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+
+    var approvers []string
+    for _, name := range strings.Split(*approvedBy, ",") {
+        if name = strings.TrimSpace(name); name != "" {
+            approvers = append(approvers, name)
+        }
+    }
+    var missing []string
+    if *amount <= 0 {
+        missing = append(missing, "-amount")
+    }
+    if *recipient == "" {
+        missing = append(missing, "-recipient")
+    }
+    if *reason == "" {
+        missing = append(missing, "-reason")
+    }
+    if len(approvers) == 0 {
+        missing = append(missing, "-approved-by")
+    }
+    if len(missing) > 0 {
+        return fmt.Errorf("payout needs %s", strings.Join(missing, ", "))
+    }
+    api, err := c.online("payout")
+    if err != nil {
+        return err
+    }
+
+    body := map[string]interface{}{
+        "amount":     *amount,
+        "recipient":  *recipient,
+        "reason":     *reason,
+        "approvedBy": approvers,
+    }
+    if *paidAt != "" {
+        body["paidAt"] = *paidAt
+    }
+    var created payout
+    if err := api.do(http.MethodPost, "/signal-bank/admin/payouts", nil, body, &created); err != nil {
+        return err
+    }
+    if c.format == "json" {
+        return c.print(created, nil, nil)
+    }
+    fmt.Fprintf(c.out, "Recorded payout %s: %.2f to %s\n", created.ID, created.Amount, created.Recipient)
+    return nil
+}
+
+// print writes v as indented JSON with -o json, otherwise a table with the
+// given header whose rows come from fill.
+func (c *cli) print(v interface{}, header []string, fill func(row func(...string))) error {
+    if c.format == "json" && v != nil {
+        enc := json.NewEncoder(c.out)
+        enc.SetIndent("", "  ")
+        return enc.Encode(v)
+    }
+
+    tw := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, strings.Join(header, "\t"))
+    fill(func(cells ...string) {
+        for i := range cells {
+            cells[i] = strings.ReplaceAll(cells[i], "\t", " ")
+        }
+        fmt.Fprintln(tw, strings.Join(cells, "\t"))
+    })
+    return tw.Flush()
+}
+
+func formatTime(t time.Time) string {
+    if t.IsZero() {
+        return "-"
+    }
+    return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestRank(t *testing.T) {
+    nominees := func(votes ...int) ballot {
+        var b ballot
+        for i, v := range votes {
+            b.Nominees = append(b.Nominees, nominee{ID: string(rune('a' + i)), Votes: v})
+        }
+        return b
+    }
+    type place struct {
+        ID    string
+        Rank  int
+        Share float64
+    }
+
+    tests := []struct {
+        name   string
+        ballot ballot
+        want   []place
+    }{
+        {"no nominees", ballot{}, []place{}},
+        {"ordered by votes", nominees(1, 3), []place{{"b", 1, 75}, {"a", 2, 25}}},
+        {"ties share a rank", nominees(2, 5, 2, 1), []place{{"b", 1, 50}, {"a", 2, 20}, {"c", 2, 20}, {"d", 4, 10}}},
+        {"tie at the top", nominees(4, 4, 2), []place{{"a", 1, 40}, {"b", 1, 40}, {"c", 3, 20}}},
+        {"no votes yet", nominees(0, 0, 0), []place{{"a", 1, 0}, {"b", 1, 0}, {"c", 1, 0}}},
+    }
+    for _, tt := range tests {
+        got := []place{}
+        for _, s := range rank(tt.ballot) {
+            got = append(got, place{s.ID, s.Rank, s.Share})
+        }
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%s: rank = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
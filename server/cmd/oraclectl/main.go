@@ -0,0 +1,121 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+)
+
+// oraclectl runs the show from a terminal instead of curl. It talks to a
+// running server, or with -offline reads the data directory directly:
+//
+//    export ORACLE_URL=https://oracle.example ORACLE_ADMIN_TOKEN=...
+//    go run ./cmd/oraclectl auditions -country NG
+//    go run ./cmd/oraclectl ballot create -title "Week 3" 171... 172...
+//    go run ./cmd/oraclectl -offline -data-dir data verify
+const usage = `usage: oraclectl [flags] <command> [args]
+
+Commands:
+  auditions [-country C] [-limit N]     list audition submissions, newest first
+  ballot                                show the current ballot and live tallies
+  ballot create [-title T] [-description D] [-closes RFC3339] ID...
+                                        open a new ballot of submission IDs
+  ballot close                          stop voting, keeping the tallies
+  results [-format csv|json]            export the ballot's ranked results
+  payouts                               list recorded Signal Bank disbursements
+  payout -amount N -recipient R -reason T -approved-by A,B [-paid-at RFC3339]
+                                        record a disbursement
+  verify                                check the data directory's files (offline)
+
+Flags:
+`
+
+type cli struct {
+    out    io.Writer
+    format string
+    api    *client
+    dir    string
+}
+
+func main() {
+    flags := flag.NewFlagSet("oraclectl", flag.ContinueOnError)
+    flags.Usage = func() {
+        fmt.Fprint(flags.Output(), usage)
+        flags.PrintDefaults()
+    }
+    server := flags.String("server", envOr("ORACLE_URL", "http://localhost:8080"), "server base URL (ORACLE_URL)")
+    token := flags.String("token", os.Getenv("ORACLE_ADMIN_TOKEN"), "admin token (ORACLE_ADMIN_TOKEN)")
+    dataDir := flags.String("data-dir", envOr("ORACLE_DATA_DIR", "data"), "data directory read by -offline and verify (ORACLE_DATA_DIR)")
+    offline := flags.Bool("offline", false, "read the data directory instead of calling the server")
+    format := flags.String("o", "table", "output format: table or json")
+
+    if err := flags.Parse(os.Args[1:]); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return
+        }
+        os.Exit(2)
+    }
+    if *format != "table" && *format != "json" {
+        fmt.Fprintf(os.Stderr, "oraclectl: -o must be table or json, not %q\n", *format)
+        os.Exit(2)
+    }
+    if flags.NArg() == 0 {
+        flags.Usage()
+        os.Exit(2)
+    }
+
+    c := &cli{out: os.Stdout, format: *format, dir: *dataDir}
+    if !*offline {
+        c.api = newClient(*server, *token)
+    }
+    if err := c.run(flags.Arg(0), flags.Args()[1:]); err != nil {
+        fmt.Fprintf(os.Stderr, "oraclectl: %v\n", err)
+        os.Exit(1)
+    }
+}
+
+func (c *cli) run(command string, args []string) error {
+    switch command {
+    case "auditions":
+        return c.auditions(args)
+    case "ballot":
+        if len(args) > 0 {
+            switch args[0] {
+            case "create":
+                return c.createBallot(args[1:])
+            case "close":
+                return c.closeBallot(args[1:])
+            }
+        }
+        return c.showBallot(args)
+    case "results":
+        return c.results(args)
+    case "payouts":
+        return c.listPayouts(args)
+    case "payout":
+        return c.recordPayout(args)
+    case "verify":
+        return c.verify(args)
+    }
+    return fmt.Errorf("unknown command %q; run oraclectl -h for the list", command)
+}
+
+// online returns the API client, or an error naming the command when
+// running offline. Writes always go through the server, which holds the
+// stores in memory and would overwrite a file edited underneath it.
+func (c *cli) online(command string) (*client, error) {
+    if c.api == nil {
+        return nil, fmt.Errorf("%s changes data and needs the server; drop -offline", command)
+    }
+    return c.api, nil
+}
+
+func envOr(name, fallback string) string {
+    if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+        return v
+    }
+    return fallback
+}
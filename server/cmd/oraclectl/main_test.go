@@ -0,0 +1,124 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+// fakeServer answers the admin routes oraclectl writes to and records what
+// it was sent.
+type fakeServer struct {
+    requests []string
+    bodies   []map[string]interface{}
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    f.requests = append(f.requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+    var body map[string]interface{}
+    json.NewDecoder(r.Body).Decode(&body)
+    f.bodies = append(f.bodies, body)
+
+    w.Header().Set("Content-Type", "application/json")
+    switch r.Method + " " + r.URL.Path {
+    case "POST /api/v1/signal-bank/admin/payouts":
+        if body["recipient"] == "Nobody" {
+            w.WriteHeader(http.StatusBadRequest)
+            w.Write([]byte(`{"error":{"code":"validation_failed","message":"recipient is not known"}}`))
+            return
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"id": "p1", "amount": body["amount"], "recipient": body["recipient"]})
+    case "POST /api/v1/ballot/close":
+        w.Write([]byte(`{"id":"b1","title":"Week 3","active":false}`))
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+func TestRun(t *testing.T) {
+    payout := []string{"-amount", "12.5", "-recipient", "Clinic", "-reason", "Supplies", "-approved-by", " ada, ,bob "}
+
+    tests := []struct {
+        name         string
+        offline      bool
+        command      string
+        args         []string
+        wantErr      string
+        wantRequests []string
+        wantOut      string
+    }{
+        {name: "payout", command: "payout", args: payout,
+            wantRequests: []string{"POST /api/v1/signal-bank/admin/payouts?token=secret"}, wantOut: "Recorded payout p1: 12.50 to Clinic\n"},
+        {name: "payout without flags", command: "payout",
+            wantErr: "payout needs -amount, -recipient, -reason, -approved-by"},
+        {name: "payout with a zero amount", command: "payout", args: []string{"-amount", "0", "-recipient", "Clinic", "-reason", "Supplies", "-approved-by", "ada"},
+            wantErr: "payout needs -amount"},
+        {name: "payout with only blank approvers", command: "payout", args: []string{"-amount", "5", "-recipient", "Clinic", "-reason", "Supplies", "-approved-by", " , "},
+            wantErr: "payout needs -approved-by"},
+        {name: "payout with a bad flag", command: "payout", args: []string{"-amount", "lots"},
+            wantErr: "invalid value"},
+        {name: "payout refused by the server", command: "payout", args: []string{"-amount", "5", "-recipient", "Nobody", "-reason", "Supplies", "-approved-by", "ada"},
+            wantRequests: []string{"POST /api/v1/signal-bank/admin/payouts?token=secret"}, wantErr: "recipient is not known (validation_failed)"},
+        {name: "offline payout", offline: true, command: "payout", args: payout,
+            wantErr: "payout changes data and needs the server; drop -offline"},
+        {name: "offline ballot create", offline: true, command: "ballot", args: []string{"create", "-title", "Week 3", "s1"},
+            wantErr: "ballot create changes data and needs the server; drop -offline"},
+        {name: "offline ballot close", offline: true, command: "ballot", args: []string{"close"},
+            wantErr: "ballot close changes data and needs the server; drop -offline"},
+        {name: "ballot close", command: "ballot", args: []string{"close"},
+            wantRequests: []string{"POST /api/v1/ballot/close?token=secret"}, wantOut: "b1  Week 3 (closed)"},
+        {name: "ballot create without nominees", command: "ballot", args: []string{"create", "-title", "Week 3"},
+            wantErr: "needs the shortlisted submission IDs"},
+        {name: "ballot create with a bad closing time", command: "ballot", args: []string{"create", "-closes", "Friday", "s1"},
+            wantErr: `-closes: "Friday" is not an RFC3339 time`},
+        {name: "unknown command", command: "refund",
+            wantErr: `unknown command "refund"`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := &fakeServer{}
+            srv := httptest.NewServer(fake)
+            defer srv.Close()
+
+            var out bytes.Buffer
+            c := &cli{out: &out, format: "table", dir: t.TempDir()}
+            if !tt.offline {
+                c.api = newClient(srv.URL+"/", "secret")
+            }
+            err := c.run(tt.command, tt.args)
+
+            if tt.wantErr == "" && err != nil {
+                t.Fatalf("unexpected error: %v", err)
+            }
+            if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+                t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+            }
+            if strings.Join(fake.requests, "\n") != strings.Join(tt.wantRequests, "\n") {
+                t.Fatalf("server saw %q, want %q", fake.requests, tt.wantRequests)
+            }
+            if !strings.Contains(out.String(), tt.wantOut) {
+                t.Fatalf("output %q, want it to contain %q", out.String(), tt.wantOut)
+            }
+        })
+    }
+}
+
+func TestPayoutBody(t *testing.T) {
+    fake := &fakeServer{}
+    srv := httptest.NewServer(fake)
+    defer srv.Close()
+
+    c := &cli{out: &bytes.Buffer{}, format: "json", api: newClient(srv.URL, "secret")}
+    args := []string{"-amount", "12.5", "-recipient", "Clinic", "-reason", "Supplies", "-approved-by", " ada, ,bob ", "-paid-at", "2026-10-01T09:00:00Z"}
+    if err := c.run("payout", args); err != nil {
+        t.Fatal(err)
+    }
+    got, _ := json.Marshal(fake.bodies[0])
+    want := `{"amount":12.5,"approvedBy":["ada","bob"],"paidAt":"2026-10-01T09:00:00Z","reason":"Supplies","recipient":"Clinic"}`
+    if string(got) != want {
+        t.Fatalf("payout body %s, want %s", got, want)
+    }
+}
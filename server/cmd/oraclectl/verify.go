@@ -0,0 +1,197 @@
+package main
+
+import (
+    "crypto/ed25519"
+    "encoding/base64"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "digital-oracle-server/payments"
+)
+
+// problem is one integrity failure found by verify.
+type problem struct {
+    File   string `json:"file"`
+    Detail string `json:"detail"`
+}
+
+// verify checks the data directory the way the server would rely on it:
+// every store parses, IDs are unique, the ballot's tallies match its
+// recorded votes, references between stores resolve, and each published
+// report still matches its signature. It reads files only, so it works
+// whether or not the server is running.
+func (c *cli) verify(args []string) error {
+    if len(args) > 0 {
+        return fmt.Errorf("verify takes no arguments; set the directory with -data-dir")
+    }
+    if _, err := os.Stat(c.dir); err != nil {
+        return err
+    }
+
+    var problems []problem
+    report := func(file, format string, a ...interface{}) {
+        problems = append(problems, problem{File: file, Detail: fmt.Sprintf(format, a...)})
+    }
+
+    var subs []submission
+    if err := readDataFile(c.dir, "submissions.json", &subs); err != nil {
+        report("submissions.json", "%v", err)
+    }
+    submissionIDs := uniqueIDs("submissions.json", len(subs), func(i int) string { return subs[i].ID }, report)
+
+    var ballotFile struct {
+        State ballot            `json:"state"`
+        Votes map[string]string `json:"votes"`
+    }
+    if err := readDataFile(c.dir, "ballot.json", &ballotFile); err != nil {
+        report("ballot.json", "%v", err)
+    }
+    b := ballotFile.State
+    nominees := uniqueIDs("ballot.json", len(b.Nominees), func(i int) string { return b.Nominees[i].ID }, report)
+    counted := map[string]int{}
+    for voter, id := range ballotFile.Votes {
+        if !nominees[id] {
+            report("ballot.json", "vote from %s is for %q, which is not on the ballot", voter, id)
+        }
+        counted[id]++
+    }
+    for _, n := range b.Nominees {
+        if n.Votes != counted[n.ID] {
+            report("ballot.json", "nominee %s shows %d votes but %d are recorded", n.ID, n.Votes, counted[n.ID])
+        }
+        if n.SubmissionID != "" && !submissionIDs[n.SubmissionID] {
+            report("ballot.json", "nominee %s refers to missing submission %s", n.ID, n.SubmissionID)
+        }
+    }
+
+    var campaigns []struct {
+        ID string `json:"id"`
+    }
+    if err := readDataFile(c.dir, "signal_bank_campaigns.json", &campaigns); err != nil {
+        report("signal_bank_campaigns.json", "%v", err)
+    }
+    campaignIDs := uniqueIDs("signal_bank_campaigns.json", len(campaigns), func(i int) string { return campaigns[i].ID }, report)
+
+    var contributions []struct {
+        ID         string          `json:"id"`
+        Amount     float64         `json:"amount"`
+        Status     payments.Status `json:"status"`
+        CampaignID string          `json:"campaignId"`
+    }
+    if err := readDataFile(c.dir, "signal_bank.json", &contributions); err != nil {
+        report("signal_bank.json", "%v", err)
+    }
+    uniqueIDs("signal_bank.json", len(contributions), func(i int) string { return contributions[i].ID }, report)
+    for _, entry := range contributions {
+        switch entry.Status {
+        case "", payments.StatusPending, payments.StatusConfirmed, payments.StatusFailed, payments.StatusRefunded:
+        default:
+            report("signal_bank.json", "contribution %s has unknown status %q", entry.ID, entry.Status)
+        }
+        if entry.Amount <= 0 {
+            report("signal_bank.json", "contribution %s has amount %v", entry.ID, entry.Amount)
+        }
+        if entry.CampaignID != "" && !campaignIDs[entry.CampaignID] {
+            report("signal_bank.json", "contribution %s refers to missing campaign %s", entry.ID, entry.CampaignID)
+        }
+    }
+
+    var payouts []payout
+    if err := readDataFile(c.dir, "signal_bank_payouts.json", &payouts); err != nil {
+        report("signal_bank_payouts.json", "%v", err)
+    }
+    uniqueIDs("signal_bank_payouts.json", len(payouts), func(i int) string { return payouts[i].ID }, report)
+    for _, p := range payouts {
+        if p.Amount <= 0 {
+            report("signal_bank_payouts.json", "payout %s has amount %v", p.ID, p.Amount)
+        }
+        if len(p.ApprovedBy) == 0 {
+            report("signal_bank_payouts.json", "payout %s has no approvers", p.ID)
+        }
+    }
+
+    verifyReports(filepath.Join(c.dir, "reports"), report)
+
+    // Temporary files are renamed into place on success; one left behind
+    // means a write was interrupted.
+    leftovers, _ := filepath.Glob(filepath.Join(c.dir, ".*.tmp-*"))
+    for _, path := range leftovers {
+        report(filepath.Base(path), "interrupted write left a temporary file")
+    }
+
+    if c.format == "json" {
+        if err := c.print(append([]problem{}, problems...), nil, nil); err != nil {
+            return err
+        }
+    } else if len(problems) == 0 {
+        fmt.Fprintf(c.out, "%s: all checks passed\n", c.dir)
+    } else {
+        c.print(nil, []string{"FILE", "PROBLEM"}, func(row func(...string)) {
+            for _, p := range problems {
+                row(p.File, p.Detail)
+            }
+        })
+    }
+    if len(problems) > 0 {
+        return fmt.Errorf("%d problem(s) found in %s", len(problems), c.dir)
+    }
+    return nil
+}
+
+// uniqueIDs reports empty and repeated IDs and returns the set of IDs.
+func uniqueIDs(file string, n int, id func(int) string, report func(string, string, ...interface{})) map[string]bool {
+    seen := make(map[string]bool, n)
+    for i := 0; i < n; i++ {
+        switch key := id(i); {
+        case key == "":
+            report(file, "entry %d has no id", i)
+        case seen[key]:
+            report(file, "id %s appears more than once", key)
+        default:
+            seen[key] = true
+        }
+    }
+    return seen
+}
+
+// verifyReports checks every published report file against its .sig with
+// the public key published beside them.
+func verifyReports(dir string, report func(string, string, ...interface{})) {
+    sigs, _ := filepath.Glob(filepath.Join(dir, "*.sig"))
+    if len(sigs) == 0 {
+        return
+    }
+    sort.Strings(sigs)
+
+    raw, err := os.ReadFile(filepath.Join(dir, "public-key.txt"))
+    if err != nil {
+        report("reports/public-key.txt", "%v", err)
+        return
+    }
+    key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+    if err != nil || len(key) != ed25519.PublicKeySize {
+        report("reports/public-key.txt", "not a base64 Ed25519 public key")
+        return
+    }
+
+    for _, sigPath := range sigs {
+        name := "reports/" + strings.TrimSuffix(filepath.Base(sigPath), ".sig")
+        data, err := os.ReadFile(strings.TrimSuffix(sigPath, ".sig"))
+        if err != nil {
+            report(name, "%v", err)
+            continue
+        }
+        rawSig, err := os.ReadFile(sigPath)
+        if err != nil {
+            report(name+".sig", "%v", err)
+            continue
+        }
+        sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(rawSig)))
+        if err != nil || !ed25519.Verify(ed25519.PublicKey(key), data, sig) {
+            report(name, "does not match its signature")
+        }
+    }
+}
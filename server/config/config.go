@@ -0,0 +1,363 @@
+// Package config loads the Digital Oracle server settings from defaults, an
+// optional YAML or TOML file, ORACLE_* environment variables and command-line
+// flags, in that order of precedence.
+package config
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "net"
+    "net/url"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/logging"
+)
+
+// Config is the effective server configuration. Each field's key tag is its
+// name in the config file (nested under the section key), env names the
+// environment variable and flag the command-line flag that override it.
+type Config struct {
+    Server     Server     `key:"server"`
+    Storage    Storage    `key:"storage"`
+    Admin      Admin      `key:"admin"`
+    Log        Log        `key:"log"`
+    RateLimit  RateLimit  `key:"rate_limit"`
+    CORS       CORS       `key:"cors"`
+    Features   Features   `key:"features"`
+    Payments   Payments   `key:"payments"`
+    SignalBank SignalBank `key:"signal_bank"`
+}
+
+type Server struct {
+    Addr    string `key:"addr" env:"ORACLE_ADDR" flag:"addr" usage:"listen address (PORT is honoured when this is unset)"`
+    DataDir string `key:"data_dir" env:"ORACLE_DATA_DIR" flag:"data-dir" usage:"directory holding the JSON data files"`
+    WebDir  string `key:"web_dir" env:"ORACLE_WEB_DIR" flag:"web-dir" usage:"serve the website from this directory instead of the copy built into the binary"`
+
+    ReadTimeout     time.Duration `key:"read_timeout" env:"ORACLE_READ_TIMEOUT" flag:"read-timeout" usage:"time allowed to read a whole request"`
+    WriteTimeout    time.Duration `key:"write_timeout" env:"ORACLE_WRITE_TIMEOUT" flag:"write-timeout" usage:"time allowed to write a response"`
+    IdleTimeout     time.Duration `key:"idle_timeout" env:"ORACLE_IDLE_TIMEOUT" flag:"idle-timeout" usage:"how long keep-alive connections may sit idle"`
+    ShutdownTimeout time.Duration `key:"shutdown_timeout" env:"ORACLE_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"how long to wait for in-flight requests on SIGTERM"`
+    MaxBodyBytes    int           `key:"max_body_bytes" env:"ORACLE_MAX_BODY_BYTES" flag:"max-body-bytes" usage:"largest request body accepted"`
+}
+
+type Storage struct {
+    Backend string `key:"backend" env:"ORACLE_STORAGE_BACKEND" flag:"storage" usage:"storage backend (file)"`
+}
+
+type Admin struct {
+    Token string `key:"token" env:"ORACLE_ADMIN_TOKEN" flag:"admin-token" usage:"token required on admin routes; required while payments or the signal bank are on" secret:"true"`
+}
+
+type Log struct {
+    Level  string `key:"level" env:"ORACLE_LOG_LEVEL" flag:"log-level" usage:"log level (debug, info, warn or error)"`
+    Format string `key:"format" env:"ORACLE_LOG_FORMAT" flag:"log-format" usage:"log output format (text or json)"`
+}
+
+type RateLimit struct {
+    RequestsPerMinute int `key:"requests_per_minute" env:"ORACLE_RATE_LIMIT_RPM" flag:"rate-limit" usage:"API requests allowed per client per minute; 0 disables limiting"`
+    Burst             int `key:"burst" env:"ORACLE_RATE_LIMIT_BURST" flag:"rate-limit-burst" usage:"requests a client may make at once before the limit applies"`
+}
+
+type CORS struct {
+    AllowedOrigins   []string `key:"allowed_origins" env:"ORACLE_CORS_ORIGINS" flag:"cors-origins" usage:"comma-separated origins allowed to call the API from a browser"`
+    AllowCredentials bool     `key:"allow_credentials" env:"ORACLE_CORS_CREDENTIALS" flag:"cors-credentials" usage:"let allowed origins send cookies with API requests"`
+}
+
+type Features struct {
+    Auditions  bool `key:"auditions" env:"ORACLE_FEATURE_AUDITIONS" flag:"feature-auditions" usage:"accept and list audition submissions"`
+    Voting     bool `key:"voting" env:"ORACLE_FEATURE_VOTING" flag:"feature-voting" usage:"serve the ballot and accept votes"`
+    SignalBank bool `key:"signal_bank" env:"ORACLE_FEATURE_SIGNAL_BANK" flag:"feature-signal-bank" usage:"serve the Signal Bank endpoints"`
+    Reports    bool `key:"reports" env:"ORACLE_FEATURE_REPORTS" flag:"feature-reports" usage:"publish signed transparency reports"`
+}
+
+type Payments struct {
+    SecretKey     string `key:"secret_key" env:"ORACLE_PAYMENT_SECRET_KEY" flag:"payment-secret-key" usage:"payment provider secret key" secret:"true"`
+    WebhookSecret string `key:"webhook_secret" env:"ORACLE_PAYMENT_WEBHOOK_SECRET" flag:"payment-webhook-secret" usage:"payment provider webhook signing secret" secret:"true"`
+    APIBase       string `key:"api_base" env:"ORACLE_PAYMENT_API_BASE" flag:"payment-api-base" usage:"payment provider API base URL"`
+}
+
+type SignalBank struct {
+    RefundWindow        time.Duration `key:"refund_window" env:"ORACLE_REFUND_WINDOW" flag:"refund-window" usage:"cooling-off period in which contributions can be refunded"`
+    Overfunding         string        `key:"overfunding" env:"ORACLE_CAMPAIGN_OVERFUNDING" flag:"overfunding" usage:"default campaign overfunding policy (cap or rollover)"`
+    ReportSigningKey    string        `key:"report_signing_key" env:"ORACLE_REPORT_SIGNING_KEY" flag:"report-signing-key" usage:"base64 ed25519 seed for signing reports" secret:"true"`
+    ModerationBlocklist string        `key:"moderation_blocklist" env:"ORACLE_MODERATION_BLOCKLIST" flag:"moderation-blocklist" usage:"file of extra words to hide in contribution messages"`
+}
+
+// Default returns the configuration used when nothing overrides it.
+func Default() Config {
+    return Config{
+        Server: Server{
+            Addr:    ":8080",
+            DataDir: "data",
+
+            ReadTimeout:     30 * time.Second,
+            WriteTimeout:    30 * time.Second,
+            IdleTimeout:     120 * time.Second,
+            ShutdownTimeout: 20 * time.Second,
+            MaxBodyBytes:    1 << 20,
+        },
+        Storage: Storage{Backend: "file"},
+        Log:     Log{Level: "info", Format: "text"},
+        RateLimit: RateLimit{
+            RequestsPerMinute: 0,
+            Burst:             20,
+        },
+        Features: Features{
+            Auditions:  true,
+            Voting:     true,
+            SignalBank: true,
+            Reports:    true,
+        },
+        SignalBank: SignalBank{
+            RefundWindow: 72 * time.Hour,
+            Overfunding:  "rollover",
+        },
+    }
+}
+
+// Loaded is the result of Load: the configuration plus where each value
+// came from, and whether the caller asked for it to be printed.
+type Loaded struct {
+    Config
+    Sources     map[string]string
+    PrintConfig bool
+}
+
+// Load builds the configuration for the given command-line arguments
+// (without the program name). The config file is taken from --config or
+// ORACLE_CONFIG. Validation errors are joined into one error.
+func Load(args []string, getenv func(string) string) (*Loaded, error) {
+    cfg := Default()
+    fields := settings(&cfg)
+    loaded := &Loaded{Sources: make(map[string]string)}
+
+    fs := flag.NewFlagSet("digital-oracle-server", flag.ContinueOnError)
+    configPath := fs.String("config", getenv("ORACLE_CONFIG"), "path to a YAML or TOML config file")
+    fs.BoolVar(&loaded.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+
+    // Flags are recorded during parsing and applied last so that they win
+    // over the file and the environment.
+    flagValues := make(map[string]string)
+    for _, s := range fields {
+        if s.flag == "" {
+            continue
+        }
+        fs.Var(&pendingFlag{setting: s, values: flagValues}, s.flag, s.usage)
+    }
+    if err := fs.Parse(args); err != nil {
+        return nil, err
+    }
+    if fs.NArg() > 0 {
+        return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+    }
+
+    if *configPath != "" {
+        values, err := parseFile(*configPath)
+        if err != nil {
+            return nil, err
+        }
+        byKey := make(map[string]*setting, len(fields))
+        for _, s := range fields {
+            byKey[s.key] = s
+        }
+        for key, raw := range values {
+            s, ok := byKey[key]
+            if !ok {
+                return nil, fmt.Errorf("%s: unknown setting %q", *configPath, key)
+            }
+            if err := s.setRaw(raw); err != nil {
+                return nil, fmt.Errorf("%s: %s: %w", *configPath, key, err)
+            }
+            loaded.Sources[key] = "file"
+        }
+    }
+
+    // PORT is what hosting platforms such as Render provide.
+    if port := strings.TrimSpace(getenv("PORT")); port != "" {
+        cfg.Server.Addr = ":" + port
+        loaded.Sources["server.addr"] = "env"
+    }
+    for _, s := range fields {
+        if s.env == "" {
+            continue
+        }
+        raw, ok := lookup(getenv, s.env)
+        if !ok {
+            continue
+        }
+        if err := s.setString(raw); err != nil {
+            return nil, fmt.Errorf("%s: %w", s.env, err)
+        }
+        loaded.Sources[s.key] = "env"
+    }
+
+    for _, s := range fields {
+        raw, ok := flagValues[s.flag]
+        if !ok {
+            continue
+        }
+        if err := s.setString(raw); err != nil {
+            return nil, fmt.Errorf("-%s: %w", s.flag, err)
+        }
+        loaded.Sources[s.key] = "flag"
+    }
+
+    if err := cfg.resolvePaths(); err != nil {
+        return nil, err
+    }
+    if err := cfg.Validate(); err != nil {
+        return nil, err
+    }
+
+    loaded.Config = cfg
+    return loaded, nil
+}
+
+func lookup(getenv func(string) string, name string) (string, bool) {
+    raw := strings.TrimSpace(getenv(name))
+    return raw, raw != ""
+}
+
+func (c *Config) resolvePaths() error {
+    for _, dir := range []*string{&c.Server.DataDir, &c.Server.WebDir} {
+        if *dir == "" {
+            continue
+        }
+        abs, err := filepath.Abs(*dir)
+        if err != nil {
+            return err
+        }
+        *dir = abs
+    }
+    return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+    var errs []error
+    fail := func(format string, args ...interface{}) {
+        errs = append(errs, fmt.Errorf(format, args...))
+    }
+
+    if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+        fail("server.addr: %q is not a host:port address", c.Server.Addr)
+    }
+    if c.Server.DataDir == "" {
+        fail("server.data_dir: is required")
+    }
+    if c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.IdleTimeout <= 0 {
+        fail("server: read_timeout, write_timeout and idle_timeout must be positive")
+    }
+    if c.Server.ShutdownTimeout <= 0 {
+        fail("server.shutdown_timeout: must be positive")
+    }
+    if c.Server.MaxBodyBytes < 1024 {
+        fail("server.max_body_bytes: must be at least 1024")
+    }
+    if c.Storage.Backend != "file" {
+        fail("storage.backend: %q is not supported (want file)", c.Storage.Backend)
+    }
+    if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+        fail("log.level: %v", err)
+    }
+    if !logging.ValidFormat(c.Log.Format) {
+        fail("log.format: must be text or json")
+    }
+    if c.RateLimit.RequestsPerMinute < 0 {
+        fail("rate_limit.requests_per_minute: must not be negative")
+    }
+    if c.RateLimit.Burst < 0 {
+        fail("rate_limit.burst: must not be negative")
+    }
+    for _, origin := range c.CORS.AllowedOrigins {
+        if origin == "*" {
+            if c.CORS.AllowCredentials {
+                fail("cors.allowed_origins: \"*\" cannot be combined with allow_credentials; list the origins")
+            }
+            continue
+        }
+        u, err := url.Parse(origin)
+        if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+            fail("cors.allowed_origins: %q is not an origin such as https://example.com", origin)
+        }
+    }
+    if (c.Payments.SecretKey == "") != (c.Payments.WebhookSecret == "") {
+        fail("payments: secret_key and webhook_secret must be set together")
+    }
+    if c.Payments.APIBase != "" {
+        if u, err := url.Parse(c.Payments.APIBase); err != nil || u.Scheme == "" || u.Host == "" {
+            fail("payments.api_base: %q is not a URL", c.Payments.APIBase)
+        }
+    }
+    if c.Admin.Token == "" && (c.Features.SignalBank || c.Payments.SecretKey != "") {
+        fail("admin.token: is required while payments or the signal bank are enabled")
+    }
+    if c.SignalBank.RefundWindow < 0 {
+        fail("signal_bank.refund_window: must not be negative")
+    }
+    if c.SignalBank.Overfunding != "cap" && c.SignalBank.Overfunding != "rollover" {
+        fail("signal_bank.overfunding: must be cap or rollover")
+    }
+    if c.Features.Reports && !c.Features.SignalBank {
+        fail("features.reports: requires features.signal_bank")
+    }
+
+    return errors.Join(errs...)
+}
+
+// Print writes the configuration as YAML that Load accepts, with secrets
+// redacted and each value annotated with where it came from.
+func (l *Loaded) Print(w io.Writer) {
+    cfg := l.Config
+    section := ""
+    for _, s := range settings(&cfg) {
+        parts := strings.SplitN(s.key, ".", 2)
+        if parts[0] != section {
+            if section != "" {
+                fmt.Fprintln(w)
+            }
+            section = parts[0]
+            fmt.Fprintf(w, "%s:\n", section)
+        }
+
+        value := s.yaml()
+        if s.secret && !s.isZero() {
+            value = `"[redacted]"`
+        }
+        source := l.Sources[s.key]
+        if source == "" {
+            source = "default"
+        }
+        fmt.Fprintf(w, "  %s: %s # %s\n", parts[1], value, source)
+    }
+}
+
+// pendingFlag records a flag's raw value so Load can apply it after the file
+// and environment.
+type pendingFlag struct {
+    *setting
+    values map[string]string
+}
+
+func (f *pendingFlag) String() string {
+    if f == nil || f.setting == nil {
+        return ""
+    }
+    return f.values[f.flag]
+}
+
+func (f *pendingFlag) Set(raw string) error {
+    if err := f.check(raw); err != nil {
+        return err
+    }
+    f.values[f.flag] = raw
+    return nil
+}
+
+func (f *pendingFlag) IsBoolFlag() bool {
+    return f.setting != nil && f.isBool()
+}
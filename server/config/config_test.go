@@ -0,0 +1,275 @@
+package config
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// env returns a getenv over vars. ORACLE_ADMIN_TOKEN defaults to a test
+// token, since the signal bank is on by default and needs one.
+func env(vars map[string]string) func(string) string {
+    return func(name string) string {
+        if v, ok := vars[name]; ok || name != "ORACLE_ADMIN_TOKEN" {
+            return v
+        }
+        return "test-admin"
+    }
+}
+
+// writeConfig writes content to a file with the given name in a temporary
+// directory and returns its path.
+func writeConfig(t *testing.T, name, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), name)
+    if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+    path := writeConfig(t, "oracle.yaml", "log:\n  level: debug\n  format: json\n")
+
+    tests := []struct {
+        name       string
+        env        map[string]string
+        args       []string
+        wantLevel  string
+        wantSource string
+    }{
+        {"default", nil, nil, "info", ""},
+        {"file", map[string]string{"ORACLE_CONFIG": path}, nil, "debug", "file"},
+        {"env over file", map[string]string{"ORACLE_CONFIG": path, "ORACLE_LOG_LEVEL": "warn"}, nil, "warn", "env"},
+        {"flag over env", map[string]string{"ORACLE_CONFIG": path, "ORACLE_LOG_LEVEL": "warn"}, []string{"--log-level", "error"}, "error", "flag"},
+        {"flag over file", nil, []string{"--config", path, "--log-level=error"}, "error", "flag"},
+        {"blank env is unset", map[string]string{"ORACLE_CONFIG": path, "ORACLE_LOG_LEVEL": "  "}, nil, "debug", "file"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            loaded, err := Load(tt.args, env(tt.env))
+            if err != nil {
+                t.Fatalf("Load: %v", err)
+            }
+            if loaded.Log.Level != tt.wantLevel || loaded.Sources["log.level"] != tt.wantSource {
+                t.Fatalf("log.level = %q from %q, want %q from %q", loaded.Log.Level, loaded.Sources["log.level"], tt.wantLevel, tt.wantSource)
+            }
+        })
+    }
+}
+
+func TestLoadPortFallback(t *testing.T) {
+    loaded, err := Load(nil, env(map[string]string{"PORT": "10000"}))
+    if err != nil {
+        t.Fatalf("Load: %v", err)
+    }
+    if loaded.Server.Addr != ":10000" {
+        t.Fatalf("server.addr = %q, want :10000 from PORT", loaded.Server.Addr)
+    }
+
+    loaded, err = Load([]string{"--addr", "127.0.0.1:9000"}, env(map[string]string{"PORT": "10000"}))
+    if err != nil {
+        t.Fatalf("Load: %v", err)
+    }
+    if loaded.Server.Addr != "127.0.0.1:9000" {
+        t.Fatalf("server.addr = %q, want the flag to win over PORT", loaded.Server.Addr)
+    }
+}
+
+func TestParseYAML(t *testing.T) {
+    tests := []struct {
+        name    string
+        input   string
+        want    map[string]value
+        wantErr string
+    }{
+        {
+            name:  "scalars and comments",
+            input: "# leading comment\nlog:\n  level: debug   # trailing comment\n  format: json\n",
+            want:  map[string]value{"log.level": {scalar: "debug"}, "log.format": {scalar: "json"}},
+        },
+        {
+            name:  "quoting",
+            input: "admin:\n  token: \"a # not a comment\"\n  other: 'single # too'\n  escaped: \"tab\\there\"\n  hash: abc#def\n",
+            want: map[string]value{
+                "admin.token":   {scalar: "a # not a comment"},
+                "admin.other":   {scalar: "single # too"},
+                "admin.escaped": {scalar: "tab\there"},
+                "admin.hash":    {scalar: "abc#def"},
+            },
+        },
+        {
+            name:  "inline list",
+            input: "cors:\n  allowed_origins: [https://a.example, \"https://b.example\", 'x,y']\n",
+            want:  map[string]value{"cors.allowed_origins": {list: []string{"https://a.example", "https://b.example", "x,y"}}},
+        },
+        {
+            name:  "block list",
+            input: "cors:\n  allowed_origins:\n    - https://a.example   # first\n    - \"https://b.example\"\n  allow_credentials: false\n",
+            want: map[string]value{
+                "cors.allowed_origins":   {list: []string{"https://a.example", "https://b.example"}},
+                "cors.allow_credentials": {scalar: "false"},
+            },
+        },
+        {
+            name:  "empty lists",
+            input: "cors:\n  allowed_origins: []\nfeatures:\n  none:\n",
+            want:  map[string]value{"cors.allowed_origins": {list: []string{}}, "features.none": {list: []string{}}},
+        },
+        {name: "list item outside a list", input: "cors:\n  - https://a.example\n", wantErr: "line 2: list item outside a list"},
+        {name: "value at the top level", input: "addr: :8080\n", wantErr: "line 1: addr must be a section"},
+        {name: "key outside a section", input: "  addr: :8080\n", wantErr: "line 1: addr is not inside a section"},
+        {name: "missing colon", input: "log:\n  level\n", wantErr: "line 2: expected key: value"},
+        {name: "bad quoting", input: "log:\n  level: \"debug\n", wantErr: "line 2: bad quoted string"},
+        {name: "unterminated list", input: "cors:\n  allowed_origins: [a, b\n", wantErr: "line 2: unterminated list"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := parseYAML(strings.NewReader(tt.input))
+            checkParse(t, got, err, tt.want, tt.wantErr)
+        })
+    }
+}
+
+func TestParseTOML(t *testing.T) {
+    tests := []struct {
+        name    string
+        input   string
+        want    map[string]value
+        wantErr string
+    }{
+        {
+            name:  "sections, quoting and lists",
+            input: "# comment\n[log]\nlevel = \"debug\" # trailing\n\n[cors]\nallowed_origins = [\"https://a.example\", 'https://b.example']\nallow_credentials = true\n",
+            want: map[string]value{
+                "log.level":              {scalar: "debug"},
+                "cors.allowed_origins":   {list: []string{"https://a.example", "https://b.example"}},
+                "cors.allow_credentials": {scalar: "true"},
+            },
+        },
+        {name: "key outside a section", input: "level = \"debug\"\n", wantErr: "line 1: level is not inside a section"},
+        {name: "unterminated section", input: "[log\n", wantErr: "line 1: unterminated section header"},
+        {name: "missing equals", input: "[log]\nlevel\n", wantErr: "line 2: expected key = value"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := parseTOML(strings.NewReader(tt.input))
+            checkParse(t, got, err, tt.want, tt.wantErr)
+        })
+    }
+}
+
+func checkParse(t *testing.T, got map[string]value, err error, want map[string]value, wantErr string) {
+    t.Helper()
+    if wantErr != "" {
+        if err == nil || !strings.Contains(err.Error(), wantErr) {
+            t.Fatalf("error = %v, want it to contain %q", err, wantErr)
+        }
+        return
+    }
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Fatalf("got %+v, want %+v", got, want)
+    }
+}
+
+func TestLoadRejects(t *testing.T) {
+    tests := []struct {
+        name    string
+        file    string
+        content string
+        args    []string
+        env     map[string]string
+        wantErr string
+    }{
+        {name: "unknown key", file: "oracle.yaml", content: "log:\n  colour: blue\n", wantErr: `unknown setting "log.colour"`},
+        {name: "unknown section", file: "oracle.toml", content: "[database]\nurl = \"x\"\n", wantErr: `unknown setting "database.url"`},
+        {name: "list for a scalar", file: "oracle.yaml", content: "log:\n  level: [debug]\n", wantErr: "log.level: expected a single value"},
+        {name: "bad duration in file", file: "oracle.yaml", content: "signal_bank:\n  refund_window: three days\n", wantErr: "is not a duration"},
+        {name: "unsupported extension", file: "oracle.json", content: "{}", wantErr: "must end in .yaml, .yml or .toml"},
+        {name: "bad env value", env: map[string]string{"ORACLE_RATE_LIMIT_RPM": "lots"}, wantErr: "ORACLE_RATE_LIMIT_RPM"},
+        {name: "bad flag value", args: []string{"--rate-limit", "lots"}, wantErr: "is not a whole number"},
+        {name: "unknown flag", args: []string{"--colour"}, wantErr: "flag provided but not defined"},
+        {name: "stray argument", args: []string{"serve"}, wantErr: "unexpected arguments: serve"},
+        {name: "other storage backend", args: []string{"--storage", "postgres"}, wantErr: `storage.backend: "postgres" is not supported`},
+        {name: "wildcard origin with credentials", args: []string{"--cors-origins", "*", "--cors-credentials"}, wantErr: "cannot be combined with allow_credentials"},
+        {name: "not an origin", args: []string{"--cors-origins", "https://a.example/app"}, wantErr: "is not an origin"},
+        {name: "half the payment secrets", env: map[string]string{"ORACLE_PAYMENT_SECRET_KEY": "sk_test"}, wantErr: "must be set together"},
+        {name: "signal bank without an admin token", env: map[string]string{"ORACLE_ADMIN_TOKEN": ""}, wantErr: "admin.token: is required"},
+        {name: "payments without an admin token", env: map[string]string{"ORACLE_ADMIN_TOKEN": "", "ORACLE_FEATURE_SIGNAL_BANK": "false", "ORACLE_FEATURE_REPORTS": "false", "ORACLE_PAYMENT_SECRET_KEY": "sk_test", "ORACLE_PAYMENT_WEBHOOK_SECRET": "whsec_test"}, wantErr: "admin.token: is required"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            args := tt.args
+            if tt.file != "" {
+                args = append([]string{"--config", writeConfig(t, tt.file, tt.content)}, args...)
+            }
+            _, err := Load(args, env(tt.env))
+            if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                t.Fatalf("Load error = %v, want it to contain %q", err, tt.wantErr)
+            }
+        })
+    }
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+    vars := map[string]string{
+        "ORACLE_ADMIN_TOKEN":            "admin-secret",
+        "ORACLE_PAYMENT_SECRET_KEY":     "sk_live_secret",
+        "ORACLE_PAYMENT_WEBHOOK_SECRET": "whsec_secret",
+    }
+    loaded, err := Load([]string{"--log-level", "debug"}, env(vars))
+    if err != nil {
+        t.Fatalf("Load: %v", err)
+    }
+
+    var buf bytes.Buffer
+    loaded.Print(&buf)
+    out := buf.String()
+
+    for _, secret := range vars {
+        if strings.Contains(out, secret) {
+            t.Errorf("printed config contains the secret %q", secret)
+        }
+    }
+    for _, line := range []string{
+        `  token: "[redacted]" # env`,
+        `  secret_key: "[redacted]" # env`,
+        `  report_signing_key: "" # default`,
+        `  level: "debug" # flag`,
+        `  backend: "file" # default`,
+    } {
+        if !strings.Contains(out, line+"\n") {
+            t.Errorf("printed config lacks %q:\n%s", line, out)
+        }
+    }
+}
+
+func TestPrintOutputLoads(t *testing.T) {
+    loaded, err := Load([]string{"--cors-origins", "https://a.example, https://b.example", "--refund-window", "24h"}, env(nil))
+    if err != nil {
+        t.Fatalf("Load: %v", err)
+    }
+    var buf bytes.Buffer
+    loaded.Print(&buf)
+
+    again, err := Load([]string{"--config", writeConfig(t, "printed.yaml", buf.String())}, env(nil))
+    if err != nil {
+        t.Fatalf("loading printed config: %v\n%s", err, buf.String())
+    }
+    if !reflect.DeepEqual(again.Config, loaded.Config) {
+        t.Fatalf("printed config loads as %+v, want %+v", again.Config, loaded.Config)
+    }
+}
+
+func TestLoadWithoutAdminToken(t *testing.T) {
+    vars := map[string]string{"ORACLE_ADMIN_TOKEN": "", "ORACLE_FEATURE_SIGNAL_BANK": "false", "ORACLE_FEATURE_REPORTS": "false"}
+    if _, err := Load(nil, env(vars)); err != nil {
+        t.Fatalf("Load with only auditions and voting: %v", err)
+    }
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+)
+
+// value is a config file entry: a scalar, or a list when list is non-nil.
+type value struct {
+    scalar string
+    list   []string
+}
+
+// parseFile reads a config file and returns its entries keyed
+// "section.name". Only the subset of YAML and TOML needed for Config is
+// understood: one level of sections holding scalars and lists of strings.
+func parseFile(path string) (map[string]value, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    var values map[string]value
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+        values, err = parseYAML(file)
+    case ".toml":
+        values, err = parseTOML(file)
+    default:
+        return nil, fmt.Errorf("%s: config file must end in .yaml, .yml or .toml", path)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    return values, nil
+}
+
+func parseYAML(r io.Reader) (map[string]value, error) {
+    values := make(map[string]value)
+    section := ""
+    listKey := ""
+
+    scanner := bufio.NewScanner(r)
+    for lineNo := 1; scanner.Scan(); lineNo++ {
+        raw := stripComment(scanner.Text())
+        line := strings.TrimSpace(raw)
+        if line == "" || line == "---" {
+            continue
+        }
+        indented := raw[0] == ' ' || raw[0] == '\t'
+
+        if strings.HasPrefix(line, "- ") || line == "-" {
+            if listKey == "" {
+                return nil, fmt.Errorf("line %d: list item outside a list", lineNo)
+            }
+            item, err := unquote(strings.TrimSpace(strings.TrimPrefix(line, "-")))
+            if err != nil {
+                return nil, fmt.Errorf("line %d: %w", lineNo, err)
+            }
+            entry := values[listKey]
+            entry.list = append(entry.list, item)
+            values[listKey] = entry
+            continue
+        }
+        listKey = ""
+
+        name, rest, ok := strings.Cut(line, ":")
+        if !ok {
+            return nil, fmt.Errorf("line %d: expected key: value", lineNo)
+        }
+        name = strings.TrimSpace(name)
+        rest = strings.TrimSpace(rest)
+
+        if !indented {
+            if rest != "" {
+                return nil, fmt.Errorf("line %d: %s must be a section", lineNo, name)
+            }
+            section = name
+            continue
+        }
+        if section == "" {
+            return nil, fmt.Errorf("line %d: %s is not inside a section", lineNo, name)
+        }
+
+        key := section + "." + name
+        if rest == "" {
+            // A block list follows; an empty one stays an empty list.
+            values[key] = value{list: []string{}}
+            listKey = key
+            continue
+        }
+        parsed, err := parseScalarOrList(rest)
+        if err != nil {
+            return nil, fmt.Errorf("line %d: %w", lineNo, err)
+        }
+        values[key] = parsed
+    }
+    return values, scanner.Err()
+}
+
+func parseTOML(r io.Reader) (map[string]value, error) {
+    values := make(map[string]value)
+    section := ""
+
+    scanner := bufio.NewScanner(r)
+    for lineNo := 1; scanner.Scan(); lineNo++ {
+        line := strings.TrimSpace(stripComment(scanner.Text()))
+        if line == "" {
+            continue
+        }
+
+        if strings.HasPrefix(line, "[") {
+            if !strings.HasSuffix(line, "]") {
+                return nil, fmt.Errorf("line %d: unterminated section header", lineNo)
+            }
+            section = strings.TrimSpace(line[1 : len(line)-1])
+            continue
+        }
+
+        name, rest, ok := strings.Cut(line, "=")
+        if !ok {
+            return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+        }
+        if section == "" {
+            return nil, fmt.Errorf("line %d: %s is not inside a section", lineNo, strings.TrimSpace(name))
+        }
+        parsed, err := parseScalarOrList(strings.TrimSpace(rest))
+        if err != nil {
+            return nil, fmt.Errorf("line %d: %w", lineNo, err)
+        }
+        values[section+"."+strings.TrimSpace(name)] = parsed
+    }
+    return values, scanner.Err()
+}
+
+// parseScalarOrList handles the value syntax both formats share: quoted or
+// bare scalars and inline [a, b] lists.
+func parseScalarOrList(raw string) (value, error) {
+    if !strings.HasPrefix(raw, "[") {
+        s, err := unquote(raw)
+        return value{scalar: s}, err
+    }
+    if !strings.HasSuffix(raw, "]") {
+        return value{}, fmt.Errorf("unterminated list %s", raw)
+    }
+
+    items := make([]string, 0)
+    inner := strings.TrimSpace(raw[1 : len(raw)-1])
+    if inner == "" {
+        return value{list: items}, nil
+    }
+    for _, part := range splitList(inner) {
+        item, err := unquote(strings.TrimSpace(part))
+        if err != nil {
+            return value{}, err
+        }
+        items = append(items, item)
+    }
+    return value{list: items}, nil
+}
+
+// splitList splits on commas that are not inside quotes.
+func splitList(s string) []string {
+    var parts []string
+    var quote rune
+    start := 0
+    for i, r := range s {
+        switch {
+        case quote != 0:
+            if r == quote {
+                quote = 0
+            }
+        case r == '"' || r == '\'':
+            quote = r
+        case r == ',':
+            parts = append(parts, s[start:i])
+            start = i + 1
+        }
+    }
+    return append(parts, s[start:])
+}
+
+func unquote(s string) (string, error) {
+    if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+        return s[1 : len(s)-1], nil
+    }
+    if strings.HasPrefix(s, `"`) {
+        out, err := strconv.Unquote(s)
+        if err != nil {
+            return "", fmt.Errorf("bad quoted string %s", s)
+        }
+        return out, nil
+    }
+    return s, nil
+}
+
+// stripComment drops a trailing # comment that is not inside quotes.
+func stripComment(line string) string {
+    var quote rune
+    for i, r := range line {
+        switch {
+        case quote != 0:
+            if r == quote {
+                quote = 0
+            }
+        case r == '"' || r == '\'':
+            quote = r
+        case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+            return line[:i]
+        }
+    }
+    return line
+}
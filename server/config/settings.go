@@ -0,0 +1,127 @@
+package config
+
+import (
+    "fmt"
+    "reflect"
+    "strconv"
+    "strings"
+    "time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setting is one leaf field of Config, addressed by its dotted file key.
+type setting struct {
+    key    string
+    env    string
+    flag   string
+    usage  string
+    secret bool
+    field  reflect.Value
+}
+
+// settings walks the tagged fields of cfg in declaration order.
+func settings(cfg *Config) []*setting {
+    var out []*setting
+    root := reflect.ValueOf(cfg).Elem()
+    for i := 0; i < root.NumField(); i++ {
+        section := root.Type().Field(i)
+        group := root.Field(i)
+        for j := 0; j < group.NumField(); j++ {
+            f := group.Type().Field(j)
+            out = append(out, &setting{
+                key:    section.Tag.Get("key") + "." + f.Tag.Get("key"),
+                env:    f.Tag.Get("env"),
+                flag:   f.Tag.Get("flag"),
+                usage:  f.Tag.Get("usage"),
+                secret: f.Tag.Get("secret") == "true",
+                field:  group.Field(j),
+            })
+        }
+    }
+    return out
+}
+
+func (s *setting) isBool() bool {
+    return s.field.Kind() == reflect.Bool
+}
+
+func (s *setting) isZero() bool {
+    return s.field.IsZero()
+}
+
+// check reports whether raw would be accepted by setString.
+func (s *setting) check(raw string) error {
+    scratch := &setting{field: reflect.New(s.field.Type()).Elem()}
+    return scratch.setString(raw)
+}
+
+// setString parses a value given on the command line or in the environment.
+// Lists are comma-separated.
+func (s *setting) setString(raw string) error {
+    raw = strings.TrimSpace(raw)
+    switch {
+    case s.field.Type() == durationType:
+        d, err := time.ParseDuration(raw)
+        if err != nil {
+            return fmt.Errorf("%q is not a duration such as 72h", raw)
+        }
+        s.field.SetInt(int64(d))
+    case s.field.Kind() == reflect.String:
+        s.field.SetString(raw)
+    case s.field.Kind() == reflect.Int:
+        n, err := strconv.Atoi(raw)
+        if err != nil {
+            return fmt.Errorf("%q is not a whole number", raw)
+        }
+        s.field.SetInt(int64(n))
+    case s.field.Kind() == reflect.Bool:
+        b, err := strconv.ParseBool(raw)
+        if err != nil {
+            return fmt.Errorf("%q is not true or false", raw)
+        }
+        s.field.SetBool(b)
+    case s.field.Kind() == reflect.Slice:
+        items := make([]string, 0)
+        for _, item := range strings.Split(raw, ",") {
+            if item = strings.TrimSpace(item); item != "" {
+                items = append(items, item)
+            }
+        }
+        s.field.Set(reflect.ValueOf(items))
+    default:
+        return fmt.Errorf("unsupported setting type %s", s.field.Type())
+    }
+    return nil
+}
+
+// setRaw applies a value read from a config file, which is either a scalar
+// string or a list.
+func (s *setting) setRaw(raw value) error {
+    if raw.list == nil {
+        return s.setString(raw.scalar)
+    }
+    if s.field.Kind() != reflect.Slice {
+        return fmt.Errorf("expected a single value, got a list")
+    }
+    s.field.Set(reflect.ValueOf(append([]string{}, raw.list...)))
+    return nil
+}
+
+// yaml formats the current value for Print.
+func (s *setting) yaml() string {
+    switch {
+    case s.field.Type() == durationType:
+        return time.Duration(s.field.Int()).String()
+    case s.field.Kind() == reflect.String:
+        return strconv.Quote(s.field.String())
+    case s.field.Kind() == reflect.Slice:
+        items := s.field.Interface().([]string)
+        quoted := make([]string, len(items))
+        for i, item := range items {
+            quoted[i] = strconv.Quote(item)
+        }
+        return "[" + strings.Join(quoted, ", ") + "]"
+    }
+    return fmt.Sprint(s.field.Interface())
+}
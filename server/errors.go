@@ -0,0 +1,191 @@
+package main
+
+import (
+    "crypto/subtle"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "strings"
+
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/logging"
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/openapi"
+)
+
+// Domain errors returned by the stores. Handlers never compare messages;
+// respondError maps each of these to a status and a stable error code.
+var (
+    ErrNoActiveBallot     = errors.New("no active ballot")
+    ErrAlreadyVoted       = errors.New("email already voted")
+    ErrNomineeNotFound    = errors.New("nominee not found")
+    ErrSubmissionNotFound = errors.New("submission not found")
+
+    ErrUnknownPayment       = errors.New("no contribution for payment")
+    ErrContributionNotFound = errors.New("contribution not found")
+    ErrInvalidClaimToken    = errors.New("claim token not recognised")
+    ErrRefundWindowClosed   = errors.New("refund window has closed")
+    ErrNotRefundable        = errors.New("contribution is not eligible for a refund")
+    ErrRefundExists         = errors.New("refund already requested")
+    ErrNoRefundRequested    = errors.New("no pending refund request")
+    ErrNoMessage            = errors.New("contribution has no message")
+
+    ErrCampaignNotFound = errors.New("campaign not found")
+    ErrCampaignClosed   = errors.New("campaign is not accepting contributions")
+    ErrCampaignFull     = errors.New("contribution exceeds the campaign's remaining goal")
+
+    ErrReportsClosed = errors.New("report publisher is shut down")
+)
+
+type errorMapping struct {
+    status int
+    code   string
+}
+
+var domainErrors = map[error]errorMapping{
+    ErrNoActiveBallot:     {http.StatusBadRequest, "no_active_ballot"},
+    ErrAlreadyVoted:       {http.StatusConflict, "already_voted"},
+    ErrNomineeNotFound:    {http.StatusBadRequest, "nominee_not_found"},
+    ErrSubmissionNotFound: {http.StatusBadRequest, "submission_not_found"},
+
+    ErrContributionNotFound: {http.StatusNotFound, "contribution_not_found"},
+    ErrInvalidClaimToken:    {http.StatusNotFound, "invalid_claim_token"},
+    ErrRefundWindowClosed:   {http.StatusUnprocessableEntity, "refund_window_closed"},
+    ErrNotRefundable:        {http.StatusUnprocessableEntity, "not_refundable"},
+    ErrRefundExists:         {http.StatusConflict, "refund_exists"},
+    ErrNoRefundRequested:    {http.StatusConflict, "no_refund_requested"},
+    ErrNoMessage:            {http.StatusBadRequest, "no_message"},
+
+    ErrCampaignNotFound: {http.StatusNotFound, "campaign_not_found"},
+    ErrCampaignClosed:   {http.StatusConflict, "campaign_closed"},
+    ErrCampaignFull:     {http.StatusConflict, "campaign_full"},
+
+    ErrReportsClosed: {http.StatusServiceUnavailable, "reports_unavailable"},
+}
+
+// FieldError describes a single invalid field in a request body.
+type FieldError struct {
+    Field   string `json:"field"`
+    Message string `json:"message"`
+}
+
+// ValidationError collects every field problem in a request so the client
+// can show them all at once.
+type ValidationError struct {
+    Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+    parts := make([]string, 0, len(e.Fields))
+    for _, f := range e.Fields {
+        parts = append(parts, f.Field+" "+f.Message)
+    }
+    return strings.Join(parts, "; ")
+}
+
+// Add records a problem with field.
+func (e *ValidationError) Add(field, message string) {
+    e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err returns the error if any field was invalid, or nil.
+func (e *ValidationError) Err() error {
+    if len(e.Fields) == 0 {
+        return nil
+    }
+    return e
+}
+
+type errorBody struct {
+    Code    string       `json:"code"`
+    Message string       `json:"message"`
+    Fields  []FieldError `json:"fields,omitempty"`
+}
+
+type errorEnvelope struct {
+    Error errorBody `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends the standard error envelope:
+//
+//    {"error": {"code": "already_voted", "message": "email already voted"}}
+func writeError(w http.ResponseWriter, status int, code, message string) {
+    writeJSON(w, status, errorEnvelope{Error: errorBody{Code: code, Message: message}})
+}
+
+// respondError writes the envelope for a domain or validation error. Any
+// other error is logged against the request and reported as a 500 with the
+// fallback message.
+func respondError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+    var verr *ValidationError
+    if errors.As(err, &verr) {
+        writeJSON(w, http.StatusBadRequest, errorEnvelope{Error: errorBody{
+            Code:    "validation_failed",
+            Message: verr.Error(),
+            Fields:  verr.Fields,
+        }})
+        return
+    }
+
+    for target, mapping := range domainErrors {
+        if errors.Is(err, target) {
+            writeError(w, mapping.status, mapping.code, target.Error())
+            return
+        }
+    }
+
+    logging.FromContext(r.Context()).Error(fallback, "err", err)
+    writeError(w, http.StatusInternalServerError, "internal_error", fallback)
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+    writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+}
+
+func invalidJSON(w http.ResponseWriter) {
+    writeError(w, http.StatusBadRequest, "invalid_json", "invalid JSON")
+}
+
+// decodeBody reads the request body into dst after checking it against the
+// route's published schema. It writes the 400 itself and returns false when
+// the body is not JSON or breaks the schema, or the 413 when it is over
+// the size limit.
+func decodeBody(w http.ResponseWriter, r *http.Request, schema *openapi.Schema, dst interface{}) bool {
+    fields, err := openapi.Decode(r.Body, schema, dst)
+    if errors.Is(err, openapi.ErrBodyTooLarge) {
+        writeError(w, http.StatusRequestEntityTooLarge, "body_too_large", "request body too large")
+        return false
+    }
+    if err != nil {
+        invalidJSON(w)
+        return false
+    }
+    if len(fields) > 0 {
+        verr := &ValidationError{}
+        for _, f := range fields {
+            verr.Add(f.Field, f.Message)
+        }
+        respondError(w, r, verr, "invalid request")
+        return false
+    }
+    return true
+}
+
+// requireAdmin checks the ?token= admin credential, writing a 401 when it
+// does not match. With no adminToken configured admin routes answer 503
+// rather than opening up.
+func requireAdmin(w http.ResponseWriter, r *http.Request, adminToken string) bool {
+    if adminToken == "" {
+        writeError(w, http.StatusServiceUnavailable, "admin_disabled", "no admin token is configured")
+        return false
+    }
+    if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(adminToken)) != 1 {
+        writeError(w, http.StatusUnauthorized, "unauthorized", "unauthorized")
+        return false
+    }
+    return true
+}
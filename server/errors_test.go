@@ -0,0 +1,106 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestRespondErrorEnvelope(t *testing.T) {
+    verr := &ValidationError{}
+    verr.Add("amount", "must be positive")
+
+    tests := []struct {
+        name       string
+        err        error
+        wantStatus int
+        wantCode   string
+    }{
+        {"validation", verr, http.StatusBadRequest, "validation_failed"},
+        {"wrapped validation", fmt.Errorf("create: %w", verr), http.StatusBadRequest, "validation_failed"},
+        {"wrapped sentinel", fmt.Errorf("refund %s: %w", "c1", ErrRefundExists), http.StatusConflict, "refund_exists"},
+        {"reports shut down", ErrReportsClosed, http.StatusServiceUnavailable, "reports_unavailable"},
+        {"unexpected", errors.New("disk full"), http.StatusInternalServerError, "internal_error"},
+    }
+    for sentinel, mapping := range domainErrors {
+        tests = append(tests, struct {
+            name       string
+            err        error
+            wantStatus int
+            wantCode   string
+        }{sentinel.Error(), sentinel, mapping.status, mapping.code})
+    }
+
+    for _, tt := range tests {
+        rec := httptest.NewRecorder()
+        respondError(rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.err, "fallback")
+        if rec.Code != tt.wantStatus || errorCode(t, rec) != tt.wantCode {
+            t.Errorf("%s: got %d %s, want %d %s", tt.name, rec.Code, rec.Body.String(), tt.wantStatus, tt.wantCode)
+        }
+    }
+}
+
+func TestDomainErrorCodesAreUnique(t *testing.T) {
+    seen := make(map[string]error)
+    for sentinel, mapping := range domainErrors {
+        if mapping.status < 400 || mapping.status >= 600 {
+            t.Errorf("%v maps to status %d", sentinel, mapping.status)
+        }
+        if other, ok := seen[mapping.code]; ok {
+            t.Errorf("%v and %v share the code %s", sentinel, other, mapping.code)
+        }
+        seen[mapping.code] = sentinel
+    }
+}
+
+func TestPublishReportAfterShutdown(t *testing.T) {
+    app, mux, _ := newTestAPI(t)
+    app.reports.close()
+
+    rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/admin/reports?token="+testAdminToken, map[string]string{"period": periodWeekly}, nil)
+    if rec.Code != http.StatusServiceUnavailable || errorCode(t, rec) != "reports_unavailable" {
+        t.Fatalf("publish after shutdown returned %d %s, want 503 reports_unavailable", rec.Code, rec.Body.String())
+    }
+}
+
+func TestRequireAdmin(t *testing.T) {
+    tests := []struct {
+        name       string
+        configured string
+        query      string
+        wantStatus int
+        wantCode   string
+    }{
+        {"no token configured", "", "", http.StatusServiceUnavailable, "admin_disabled"},
+        {"no token configured, one sent", "", "?token=anything", http.StatusServiceUnavailable, "admin_disabled"},
+        {"missing", testAdminToken, "", http.StatusUnauthorized, "unauthorized"},
+        {"wrong", testAdminToken, "?token=guess", http.StatusUnauthorized, "unauthorized"},
+    }
+    for _, tt := range tests {
+        app, mux, _ := newTestAPI(t)
+        app.adminToken = tt.configured
+        for _, route := range []string{"/api/v1/auditions", "/api/v1/signal-bank/admin/refunds", "/api/v1/signal-bank/admin/payouts"} {
+            rec := do(t, mux, http.MethodGet, route+tt.query, nil, nil)
+            if rec.Code != tt.wantStatus || errorCode(t, rec) != tt.wantCode {
+                t.Errorf("%s: GET %s returned %d %s, want %d %s", tt.name, route, rec.Code, rec.Body.String(), tt.wantStatus, tt.wantCode)
+            }
+        }
+    }
+}
+
+func TestDecodeBodyTooLarge(t *testing.T) {
+    _, mux, _ := newTestAPI(t)
+    limited := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        r.Body = http.MaxBytesReader(w, r.Body, 64)
+        mux.ServeHTTP(w, r)
+    })
+
+    body := map[string]interface{}{"name": "Ada", "amount": 10, "message": strings.Repeat("a", 200)}
+    rec := do(t, limited, http.MethodPost, "/api/v1/signal-bank/contributions", body, nil)
+    if rec.Code != http.StatusRequestEntityTooLarge || errorCode(t, rec) != "body_too_large" {
+        t.Fatalf("oversized body returned %d %s, want 413 body_too_large", rec.Code, rec.Body.String())
+    }
+}
@@ -1,17 +1,26 @@
 package main
 
 import (
+    "context"
     "encoding/json"
     "errors"
+    "flag"
     "fmt"
     "log"
+    "log/slog"
     "net/http"
     "os"
     "path/filepath"
-    "strconv"
     "strings"
     "sync"
     "time"
+
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/cors"
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/logging"
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/serve"
+
+    "digital-oracle-server/config"
+    "digital-oracle-server/payments"
 )
 
 type submission struct {
@@ -52,15 +61,35 @@ type fileStore struct {
 }
 
 type contribution struct {
-    ID        string    `json:"id"`
-    Name      string    `json:"name"`
-    Amount    float64   `json:"amount"`
-    Message   string    `json:"message"`
-    CreatedAt time.Time `json:"createdAt"`
+    ID             string          `json:"id"`
+    Name           string          `json:"name"`
+    Visibility     visibility      `json:"visibility"`
+    Amount         float64         `json:"amount"`
+    Message        string          `json:"message"`
+    MessageHidden  bool            `json:"messageHidden,omitempty"`
+    ModerationNote string          `json:"moderationNote,omitempty"`
+    CampaignID     string          `json:"campaignId,omitempty"`
+    Status         payments.Status `json:"status"`
+    Provider       string          `json:"provider,omitempty"`
+    PaymentID      string          `json:"paymentId,omitempty"`
+    ClaimHash      string          `json:"-"`
+    CreatedAt      time.Time       `json:"createdAt"`
+    ConfirmedAt    *time.Time      `json:"confirmedAt,omitempty"`
+    ReservedUntil  *time.Time      `json:"reservedUntil,omitempty"`
+    Refund         *refundRequest  `json:"refund,omitempty"`
+    UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// storedContribution is a contribution as written to disk. The claim hash
+// is kept out of the contribution's own JSON so no response can carry it.
+type storedContribution struct {
+    contribution
+    ClaimHash string `json:"claimHash,omitempty"`
 }
 
 type contributionStore struct {
     path          string
+    refundWindow  time.Duration
     mu            sync.Mutex
     contributions []contribution
 }
@@ -114,7 +143,7 @@ func (s *fileStore) save() error {
         return err
     }
 
-    if err := os.WriteFile(s.path, data, 0o644); err != nil {
+    if err := writeStoreFile("submissions", s.path, data); err != nil {
         return err
     }
     return nil
@@ -133,7 +162,7 @@ func (s *fileStore) add(sub submission) (submission, error) {
         return submission{}, err
     }
 
-    if err := os.WriteFile(s.path, data, 0o644); err != nil {
+    if err := writeStoreFile("submissions", s.path, data); err != nil {
         return submission{}, err
     }
 
@@ -149,8 +178,8 @@ func (s *fileStore) list() []submission {
     return out
 }
 
-func newContributionStore(path string) (*contributionStore, error) {
-    store := &contributionStore{path: path}
+func newContributionStore(path string, refundWindow time.Duration) (*contributionStore, error) {
+    store := &contributionStore{path: path, refundWindow: refundWindow}
     if err := store.load(); err != nil {
         return nil, err
     }
@@ -176,9 +205,26 @@ func (s *contributionStore) load() error {
         return nil
     }
 
-    if err := json.Unmarshal(data, &s.contributions); err != nil {
+    var stored []storedContribution
+    if err := json.Unmarshal(data, &stored); err != nil {
         return err
     }
+    s.contributions = make([]contribution, len(stored))
+    for i, entry := range stored {
+        s.contributions[i] = entry.contribution
+        s.contributions[i].ClaimHash = entry.ClaimHash
+    }
+
+    // Entries recorded before payments were wired up were never verified,
+    // so they wait for an admin instead of counting toward the total.
+    for i := range s.contributions {
+        if s.contributions[i].Status == "" {
+            s.contributions[i].Status = payments.StatusPending
+        }
+        if s.contributions[i].Visibility == "" {
+            s.contributions[i].Visibility = visibilityPublic
+        }
+    }
     return nil
 }
 
@@ -188,18 +234,92 @@ func (s *contributionStore) add(entry contribution) (contribution, error) {
 
     entry.ID = fmt.Sprintf("%d", time.Now().UnixNano())
     entry.CreatedAt = time.Now().UTC()
+    entry.UpdatedAt = entry.CreatedAt
+    if entry.Status == "" {
+        entry.Status = payments.StatusPending
+    }
     s.contributions = append([]contribution{entry}, s.contributions...)
 
-    data, err := json.MarshalIndent(s.contributions, "", "  ")
-    if err != nil {
+    if err := s.saveLocked(); err != nil {
         return contribution{}, err
     }
 
-    if err := os.WriteFile(s.path, data, 0o644); err != nil {
-        return contribution{}, err
+    return entry, nil
+}
+
+// updateStatus applies a provider-confirmed status to the contribution paid
+// through paymentID. Replayed or out-of-order events leave the entry as is.
+func (s *contributionStore) updateStatus(paymentID string, status payments.Status) (contribution, bool, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for i := range s.contributions {
+        entry := &s.contributions[i]
+        if entry.PaymentID != paymentID {
+            continue
+        }
+
+        if !validPaymentTransition(entry.Status, status) {
+            return *entry, false, nil
+        }
+
+        now := time.Now().UTC()
+        entry.Status = status
+        entry.UpdatedAt = now
+        switch status {
+        case payments.StatusConfirmed:
+            reservedUntil := now.Add(s.refundWindow)
+            entry.ConfirmedAt = &now
+            entry.ReservedUntil = &reservedUntil
+        case payments.StatusRefunded:
+            // Refunds issued straight from the provider dashboard arrive
+            // without a request on file.
+            if entry.Refund == nil {
+                entry.Refund = &refundRequest{RequestedAt: now}
+            }
+            entry.Refund.Status = refundCompleted
+            entry.Refund.ProcessedAt = &now
+        }
+
+        if err := s.saveLocked(); err != nil {
+            return contribution{}, false, err
+        }
+        return *entry, true, nil
     }
+    return contribution{}, false, ErrUnknownPayment
+}
 
-    return entry, nil
+// validPaymentTransition reports whether a webhook may move a contribution
+// from one payment status to another. Anything else is a duplicate or an
+// event delivered out of order, and is ignored.
+func validPaymentTransition(from, to payments.Status) bool {
+    switch from {
+    case payments.StatusPending:
+        return to == payments.StatusConfirmed || to == payments.StatusFailed
+    case payments.StatusFailed:
+        // Stripe sends payment_intent.payment_failed for each declined
+        // attempt, but the intent goes back to requires_payment_method
+        // and stays open: the contributor can try another card on the
+        // same intent, and payment_intent.succeeded follows. A canceled
+        // intent can never succeed, so this only happens after a decline.
+        return to == payments.StatusConfirmed
+    case payments.StatusConfirmed:
+        return to == payments.StatusRefunded
+    }
+    return false
+}
+
+func (s *contributionStore) saveLocked() error {
+    stored := make([]storedContribution, len(s.contributions))
+    for i, entry := range s.contributions {
+        stored[i] = storedContribution{contribution: entry, ClaimHash: entry.ClaimHash}
+    }
+    data, err := json.MarshalIndent(stored, "", "  ")
+    if err != nil {
+        return err
+    }
+
+    return writeStoreFile("signal_bank", s.path, data)
 }
 
 func (s *contributionStore) list() []contribution {
@@ -211,6 +331,19 @@ func (s *contributionStore) list() []contribution {
     return out
 }
 
+func (s *contributionStore) listByStatus(status payments.Status) []contribution {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    out := make([]contribution, 0, len(s.contributions))
+    for _, entry := range s.contributions {
+        if entry.Status == status {
+            out = append(out, entry)
+        }
+    }
+    return out
+}
+
 func (s *fileStore) getByID(id string) (submission, bool) {
     s.mu.Lock()
     defer s.mu.Unlock()
@@ -290,7 +423,7 @@ func (b *ballotStore) save() error {
         return err
     }
 
-    return os.WriteFile(b.path, data, 0o644)
+    return writeStoreFile("ballot", b.path, data)
 }
 
 func (b *ballotStore) activeBallot() ballotState {
@@ -308,21 +441,38 @@ func (b *ballotStore) setBallot(state ballotState) error {
     return b.save()
 }
 
+// closeBallot stops voting on the active ballot, keeping its tallies.
+func (b *ballotStore) closeBallot() (ballotState, error) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if !b.state.Active {
+        return ballotState{}, ErrNoActiveBallot
+    }
+    b.state.Active = false
+    if err := b.saveLocked(); err != nil {
+        return ballotState{}, err
+    }
+    return b.state, nil
+}
+
 func (b *ballotStore) addVote(email, nomineeID string) (ballotState, error) {
     email = strings.ToLower(strings.TrimSpace(email))
     if email == "" {
-        return ballotState{}, errors.New("email required")
+        verr := &ValidationError{}
+        verr.Add("email", "is required")
+        return ballotState{}, verr
     }
 
     b.mu.Lock()
     defer b.mu.Unlock()
 
     if !b.state.Active {
-        return ballotState{}, errors.New("no active ballot")
+        return ballotState{}, ErrNoActiveBallot
     }
 
     if _, exists := b.votes[email]; exists {
-        return ballotState{}, errors.New("email already voted")
+        return ballotState{}, ErrAlreadyVoted
     }
 
     found := false
@@ -334,7 +484,7 @@ func (b *ballotStore) addVote(email, nomineeID string) (ballotState, error) {
         }
     }
     if !found {
-        return ballotState{}, errors.New("nominee not found")
+        return ballotState{}, ErrNomineeNotFound
     }
 
     b.votes[email] = nomineeID
@@ -360,368 +510,144 @@ func (b *ballotStore) saveLocked() error {
         return err
     }
 
-    return os.WriteFile(b.path, data, 0o644)
+    return writeStoreFile("ballot", b.path, data)
 }
 
 func main() {
-    baseDir, err := os.Getwd()
+    cfg, err := config.Load(os.Args[1:], os.Getenv)
+    if errors.Is(err, flag.ErrHelp) {
+        return
+    }
     if err != nil {
-        log.Fatalf("failed to determine working directory: %v", err)
+        log.Fatalf("invalid configuration:\n%v", err)
+    }
+    if cfg.PrintConfig {
+        cfg.Print(os.Stdout)
+        return
+    }
+    if _, err := logging.Setup(logging.Options{Service: "digital-oracle", Level: cfg.Log.Level, Format: cfg.Log.Format}); err != nil {
+        log.Fatalf("invalid logging configuration: %v", err)
     }
 
-    dataDir := filepath.Join(baseDir, "data")
+    dataDir := cfg.Server.DataDir
     if err := os.MkdirAll(dataDir, 0o755); err != nil {
-        log.Fatalf("failed to create data directory: %v", err)
+        logging.Fatal("failed to create data directory", "err", err)
     }
 
     store, err := newFileStore(filepath.Join(dataDir, "submissions.json"))
     if err != nil {
-        log.Fatalf("failed to initialize store: %v", err)
+        logging.Fatal("failed to initialize submission store", "err", err)
     }
 
     ballotStore, err := newBallotStore(filepath.Join(dataDir, "ballot.json"))
     if err != nil {
-        log.Fatalf("failed to initialize ballot store: %v", err)
+        logging.Fatal("failed to initialize ballot store", "err", err)
     }
 
-    bankStore, err := newContributionStore(filepath.Join(dataDir, "signal_bank.json"))
+    bankStore, err := newContributionStore(filepath.Join(dataDir, "signal_bank.json"), cfg.SignalBank.RefundWindow)
     if err != nil {
-        log.Fatalf("failed to initialize contribution store: %v", err)
+        logging.Fatal("failed to initialize contribution store", "err", err)
     }
 
-    adminToken := strings.TrimSpace(os.Getenv("ORACLE_ADMIN_TOKEN"))
-
-    mux := http.NewServeMux()
-
-    mux.HandleFunc("/api/auditions", func(w http.ResponseWriter, r *http.Request) {
-        switch r.Method {
-        case http.MethodPost:
-            var payload struct {
-                Name         string `json:"name"`
-                Country      string `json:"country"`
-                SocialHandle string `json:"socialHandle"`
-                VideoURL     string `json:"videoUrl"`
-                Message      string `json:"message"`
-            }
-
-            if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-                http.Error(w, "invalid JSON", http.StatusBadRequest)
-                return
-            }
-
-            payload.Name = strings.TrimSpace(payload.Name)
-            payload.Country = strings.TrimSpace(payload.Country)
-            payload.SocialHandle = strings.TrimSpace(payload.SocialHandle)
-            payload.VideoURL = strings.TrimSpace(payload.VideoURL)
-            payload.Message = strings.TrimSpace(payload.Message)
-
-            if payload.Name == "" || payload.Country == "" || payload.VideoURL == "" {
-                http.Error(w, "name, country, and videoUrl are required", http.StatusBadRequest)
-                return
-            }
-
-            if !(strings.HasPrefix(payload.VideoURL, "http://") || strings.HasPrefix(payload.VideoURL, "https://")) {
-                http.Error(w, "videoUrl must be a valid http(s) link", http.StatusBadRequest)
-                return
-            }
-
-            created, err := store.add(submission{
-                Name:         payload.Name,
-                Country:      payload.Country,
-                SocialHandle: payload.SocialHandle,
-                VideoURL:     payload.VideoURL,
-                Message:      payload.Message,
-            })
-            if err != nil {
-                log.Printf("failed to store submission: %v", err)
-                http.Error(w, "failed to record submission", http.StatusInternalServerError)
-                return
-            }
-
-            w.Header().Set("Content-Type", "application/json")
-            w.WriteHeader(http.StatusCreated)
-            json.NewEncoder(w).Encode(created)
-
-        case http.MethodGet:
-            if adminToken != "" {
-                token := r.URL.Query().Get("token")
-                if token != adminToken {
-                    http.Error(w, "unauthorized", http.StatusUnauthorized)
-                    return
-                }
-            }
-
-            submissions := store.list()
-
-            countryFilter := strings.TrimSpace(r.URL.Query().Get("country"))
-            if countryFilter != "" {
-                filtered := make([]submission, 0, len(submissions))
-                for _, sub := range submissions {
-                    if strings.EqualFold(sub.Country, countryFilter) {
-                        filtered = append(filtered, sub)
-                    }
-                }
-                submissions = filtered
-            }
-
-            limitStr := strings.TrimSpace(r.URL.Query().Get("limit"))
-            if limitStr != "" {
-                if limit, err := strconv.Atoi(limitStr); err == nil && limit >= 0 && limit < len(submissions) {
-                    submissions = submissions[:limit]
-                }
-            }
-
-            w.Header().Set("Content-Type", "application/json")
-            json.NewEncoder(w).Encode(submissions)
-
-        default:
-            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-        }
-    })
-
-    mux.HandleFunc("/api/ballot", func(w http.ResponseWriter, r *http.Request) {
-        switch r.Method {
-        case http.MethodGet:
-            state := ballotStore.activeBallot()
-            w.Header().Set("Content-Type", "application/json")
-            json.NewEncoder(w).Encode(state)
-        case http.MethodPost:
-            if adminToken != "" {
-                token := r.URL.Query().Get("token")
-                if token != adminToken {
-                    http.Error(w, "unauthorized", http.StatusUnauthorized)
-                    return
-                }
-            }
-
-            var payload struct {
-                Title       string   `json:"title"`
-                Description string   `json:"description"`
-                ClosesAt    string   `json:"closesAt"`
-                NomineeIDs  []string `json:"nomineeIds"`
-                Active      *bool    `json:"active"`
-            }
-
-            if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-                http.Error(w, "invalid JSON", http.StatusBadRequest)
-                return
-            }
-
-            if len(payload.NomineeIDs) == 0 {
-                http.Error(w, "nomineeIds required", http.StatusBadRequest)
-                return
-            }
-
-            nominees := make([]ballotNominee, 0, len(payload.NomineeIDs))
-            for _, id := range payload.NomineeIDs {
-                sub, ok := store.getByID(strings.TrimSpace(id))
-                if !ok {
-                    http.Error(w, fmt.Sprintf("submission %s not found", id), http.StatusBadRequest)
-                    return
-                }
-                nominees = append(nominees, ballotNominee{
-                    ID:           sub.ID,
-                    SubmissionID: sub.ID,
-                    Name:         sub.Name,
-                    Country:      sub.Country,
-                    SocialHandle: sub.SocialHandle,
-                    VideoURL:     sub.VideoURL,
-                    Message:      sub.Message,
-                    Votes:        0,
-                })
-            }
-
-            closesAt := time.Time{}
-            if payload.ClosesAt != "" {
-                ts, err := time.Parse(time.RFC3339, payload.ClosesAt)
-                if err != nil {
-                    http.Error(w, "closesAt must be RFC3339 timestamp", http.StatusBadRequest)
-                    return
-                }
-                closesAt = ts
-            }
-
-            active := true
-            if payload.Active != nil {
-                active = *payload.Active
-            }
-
-            newState := ballotState{
-                ID:          fmt.Sprintf("ballot-%d", time.Now().UnixNano()),
-                Title:       strings.TrimSpace(payload.Title),
-                Description: strings.TrimSpace(payload.Description),
-                Nominees:    nominees,
-                Active:      active,
-                CreatedAt:   time.Now().UTC(),
-                ClosesAt:    closesAt,
-            }
-
-            if err := ballotStore.setBallot(newState); err != nil {
-                log.Printf("failed to set ballot: %v", err)
-                http.Error(w, "failed to set ballot", http.StatusInternalServerError)
-                return
-            }
-
-            w.Header().Set("Content-Type", "application/json")
-            w.WriteHeader(http.StatusCreated)
-            json.NewEncoder(w).Encode(newState)
-
-        default:
-            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-        }
-    })
-
-    mux.HandleFunc("/api/vote", func(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodPost {
-            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-            return
-        }
-
-        var payload struct {
-            NomineeID string `json:"nomineeId"`
-            Email     string `json:"email"`
-            Name      string `json:"name"`
-        }
-
-        if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-            http.Error(w, "invalid JSON", http.StatusBadRequest)
-            return
-        }
-
-        payload.NomineeID = strings.TrimSpace(payload.NomineeID)
-        payload.Email = strings.TrimSpace(payload.Email)
-
-        if payload.NomineeID == "" || payload.Email == "" {
-            http.Error(w, "nomineeId and email are required", http.StatusBadRequest)
-            return
-        }
-
-        if !strings.Contains(payload.Email, "@") {
-            http.Error(w, "email must be valid", http.StatusBadRequest)
-            return
-        }
-
-        updated, err := ballotStore.addVote(payload.Email, payload.NomineeID)
-        if err != nil {
-            switch err.Error() {
-            case "email already voted":
-                http.Error(w, err.Error(), http.StatusConflict)
-                return
-            case "no active ballot":
-                http.Error(w, err.Error(), http.StatusBadRequest)
-                return
-            case "nominee not found":
-                http.Error(w, err.Error(), http.StatusBadRequest)
-                return
-            default:
-                log.Printf("failed to record vote: %v", err)
-                http.Error(w, "failed to record vote", http.StatusInternalServerError)
-                return
-            }
-        }
-
-        votes := 0
-        for _, nominee := range updated.Nominees {
-            if nominee.ID == payload.NomineeID {
-                votes = nominee.Votes
-                break
-            }
-        }
-
-        response := struct {
-            BallotID  string `json:"ballotId"`
-            NomineeID string `json:"nomineeId"`
-            Votes     int    `json:"votes"`
-            Message   string `json:"message"`
-        }{
-            BallotID:  updated.ID,
-            NomineeID: payload.NomineeID,
-            Votes:     votes,
-            Message:   "Vote recorded. Thank you for supporting the contenders!",
-        }
-
-        w.Header().Set("Content-Type", "application/json")
-        json.NewEncoder(w).Encode(response)
-    })
-
-    mux.HandleFunc("/api/signal-bank/contributions", func(w http.ResponseWriter, r *http.Request) {
-        switch r.Method {
-        case http.MethodPost:
-            var payload struct {
-                Name    string  `json:"name"`
-                Amount  float64 `json:"amount"`
-                Message string  `json:"message"`
-            }
-
-            if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-                http.Error(w, "invalid JSON", http.StatusBadRequest)
-                return
-            }
+    payoutStore, err := newPayoutStore(filepath.Join(dataDir, "signal_bank_payouts.json"))
+    if err != nil {
+        logging.Fatal("failed to initialize payout store", "err", err)
+    }
 
-            payload.Name = strings.TrimSpace(payload.Name)
-            payload.Message = strings.TrimSpace(payload.Message)
+    signingKey, err := loadSigningKey(cfg.SignalBank.ReportSigningKey, filepath.Join(dataDir, "report_signing.key"))
+    if err != nil {
+        logging.Fatal("failed to load report signing key", "err", err)
+    }
 
-            if payload.Amount <= 0 {
-                http.Error(w, "amount must be greater than zero", http.StatusBadRequest)
-                return
-            }
+    campaignStore, err := newCampaignStore(filepath.Join(dataDir, "signal_bank_campaigns.json"))
+    if err != nil {
+        logging.Fatal("failed to initialize campaign store", "err", err)
+    }
 
-            entry, err := bankStore.add(contribution{
-                Name:    payload.Name,
-                Amount:  payload.Amount,
-                Message: payload.Message,
-            })
-            if err != nil {
-                log.Printf("failed to store contribution: %v", err)
-                http.Error(w, "failed to record contribution", http.StatusInternalServerError)
-                return
-            }
+    moderation, err := newModerator(cfg.SignalBank.ModerationBlocklist)
+    if err != nil {
+        logging.Fatal("failed to load moderation blocklist", "err", err)
+    }
 
-            w.Header().Set("Content-Type", "application/json")
-            w.WriteHeader(http.StatusCreated)
-            json.NewEncoder(w).Encode(entry)
+    site, err := webHandler(cfg.Server.WebDir)
+    if err != nil {
+        logging.Fatal("failed to load website", "err", err)
+    }
 
-        case http.MethodGet:
-            contributions := bankStore.list()
+    reportsDir := filepath.Join(dataDir, "reports")
+    reports := &reportPublisher{
+        dir:     reportsDir,
+        key:     signingKey,
+        bank:    bankStore,
+        payouts: payoutStore,
+    }
+    if cfg.Features.Reports {
+        go reports.run()
+    }
 
-            limitStr := strings.TrimSpace(r.URL.Query().Get("limit"))
-            if limitStr != "" {
-                if limit, err := strconv.Atoi(limitStr); err == nil && limit >= 0 && limit < len(contributions) {
-                    contributions = contributions[:limit]
-                }
-            }
+    var paymentProvider payments.Provider
+    if cfg.Payments.SecretKey != "" {
+        paymentProvider = payments.NewStripe(cfg.Payments.SecretKey, cfg.Payments.WebhookSecret, cfg.Payments.APIBase)
+    } else {
+        slog.Warn("payments disabled: set ORACLE_PAYMENT_SECRET_KEY and ORACLE_PAYMENT_WEBHOOK_SECRET to accept contributions")
+    }
 
-            w.Header().Set("Content-Type", "application/json")
-            json.NewEncoder(w).Encode(contributions)
+    mux := http.NewServeMux()
 
-        default:
-            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-        }
-    })
+    app := &api{
+        features:           cfg.Features,
+        adminToken:         cfg.Admin.Token,
+        submissions:        store,
+        ballots:            ballotStore,
+        bank:               bankStore,
+        campaigns:          campaignStore,
+        payouts:            payoutStore,
+        reports:            reports,
+        moderation:         moderation,
+        provider:           paymentProvider,
+        refundWindow:       cfg.SignalBank.RefundWindow,
+        defaultOverfunding: overfundingPolicy(cfg.SignalBank.Overfunding),
+        spec:               apiDocument().Handler(),
+    }
+    app.mount(mux)
 
     mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
         w.WriteHeader(http.StatusOK)
         w.Write([]byte("ok"))
     })
 
-    webDir := filepath.Join(baseDir, "web")
-    mux.Handle("/", http.FileServer(http.Dir(webDir)))
-
-    port := os.Getenv("PORT")
-    if port == "" {
-        port = "8080"
-    }
-
-    log.Printf("Digital Oracle server listening on :%s", port)
-    if err := http.ListenAndServe(":"+port, loggingMiddleware(mux)); err != nil {
-        log.Fatalf("server exited: %v", err)
-    }
-}
-
-func loggingMiddleware(next http.Handler) http.Handler {
-    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        start := time.Now()
-        next.ServeHTTP(w, r)
-        log.Printf("%s %s %s", r.Method, r.URL.Path, time.Since(start))
+    mux.Handle("/metrics", registry.Handler())
+    mux.Handle("/reports/", http.StripPrefix("/reports/", http.FileServer(http.Dir(reportsDir))))
+    mux.Handle("/", site)
+
+    registerSignalBankMetrics(bankStore)
+
+    var handler http.Handler = mux
+    if cfg.RateLimit.RequestsPerMinute > 0 {
+        handler = newRateLimiter(cfg.RateLimit.RequestsPerMinute, cfg.RateLimit.Burst).middleware(handler)
+    }
+    handler = cors.New(cors.Options{
+        AllowedOrigins:   cfg.CORS.AllowedOrigins,
+        AllowCredentials: cfg.CORS.AllowCredentials,
+    }).Middleware(handler)
+
+    err = serve.Run(serve.Options{
+        Name:            "Digital Oracle server",
+        Addr:            cfg.Server.Addr,
+        Handler:         logging.Middleware(httpMetrics.Instrument(mux, handler)),
+        ReadTimeout:     cfg.Server.ReadTimeout,
+        WriteTimeout:    cfg.Server.WriteTimeout,
+        IdleTimeout:     cfg.Server.IdleTimeout,
+        MaxBodyBytes:    int64(cfg.Server.MaxBodyBytes),
+        ShutdownTimeout: cfg.Server.ShutdownTimeout,
+        // Stores write through on every change; once requests have drained
+        // only a report being published can still be touching disk.
+        Cleanup: func(ctx context.Context) error {
+            reports.close()
+            return nil
+        },
     })
+    if err != nil {
+        logging.Fatal("server exited", "err", err)
+    }
 }
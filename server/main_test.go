@@ -0,0 +1,140 @@
+package main
+
+import (
+    "bytes"
+    "crypto/ed25519"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "digital-oracle-server/config"
+    "digital-oracle-server/payments"
+)
+
+const (
+    testAdminToken    = "test-admin"
+    testWebhookSecret = "whsec_test"
+)
+
+// newTestAPI builds the API over fresh stores in a temporary directory,
+// with every feature on and payments going to a FakeServer whose webhooks
+// come back to the API. It returns the mux the API is mounted on and the
+// fake provider's URL.
+func newTestAPI(t *testing.T) (*api, *http.ServeMux, string) {
+    t.Helper()
+    dir := t.TempDir()
+
+    submissions, err := newFileStore(filepath.Join(dir, "submissions.json"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    ballots, err := newBallotStore(filepath.Join(dir, "ballot.json"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    bank, err := newContributionStore(filepath.Join(dir, "signal_bank.json"), 72*time.Hour)
+    if err != nil {
+        t.Fatal(err)
+    }
+    campaigns, err := newCampaignStore(filepath.Join(dir, "signal_bank_campaigns.json"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    payouts, err := newPayoutStore(filepath.Join(dir, "signal_bank_payouts.json"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    moderation, err := newModerator("")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    mux := http.NewServeMux()
+    hooks := httptest.NewServer(mux)
+    t.Cleanup(hooks.Close)
+    fake := httptest.NewServer(payments.NewFakeServer(hooks.URL+"/api/v1/signal-bank/webhook", testWebhookSecret))
+    t.Cleanup(fake.Close)
+
+    app := &api{
+        features:    config.Features{Auditions: true, Voting: true, SignalBank: true, Reports: true},
+        adminToken:  testAdminToken,
+        submissions: submissions,
+        ballots:     ballots,
+        bank:        bank,
+        campaigns:   campaigns,
+        payouts:     payouts,
+        reports: &reportPublisher{
+            dir:     filepath.Join(dir, "reports"),
+            key:     ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)),
+            bank:    bank,
+            payouts: payouts,
+        },
+        moderation:         moderation,
+        provider:           payments.NewStripe("sk_test", testWebhookSecret, fake.URL),
+        refundWindow:       72 * time.Hour,
+        defaultOverfunding: overfundCap,
+        spec:               apiDocument().Handler(),
+    }
+    app.mount(mux)
+    return app, mux, fake.URL
+}
+
+// settlePayment drives the fake provider, which signs and delivers the
+// resulting webhook before it answers. action is confirm or cancel.
+func settlePayment(t *testing.T, fakeURL, intentID, action string) {
+    t.Helper()
+    req, err := http.NewRequest(http.MethodPost, fakeURL+"/v1/payment_intents/"+intentID+"/"+action, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    req.Header.Set("Authorization", "Bearer sk_test")
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        t.Fatalf("fake provider %s of %s returned %s", action, intentID, resp.Status)
+    }
+}
+
+// do sends a JSON request to mux and decodes the response into out, if
+// given. It returns the recorder for status and header checks.
+func do(t *testing.T, mux http.Handler, method, path string, body interface{}, out interface{}) *httptest.ResponseRecorder {
+    t.Helper()
+    var buf bytes.Buffer
+    if body != nil {
+        if raw, ok := body.([]byte); ok {
+            buf.Write(raw)
+        } else if err := json.NewEncoder(&buf).Encode(body); err != nil {
+            t.Fatal(err)
+        }
+    }
+    req := httptest.NewRequest(method, path, &buf)
+    req.Header.Set("Content-Type", "application/json")
+    rec := httptest.NewRecorder()
+    mux.ServeHTTP(rec, req)
+    if out != nil {
+        if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+            t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+        }
+    }
+    return rec
+}
+
+// errorCode returns the code from a JSON error envelope.
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+    t.Helper()
+    var envelope struct {
+        Error struct {
+            Code string `json:"code"`
+        } `json:"error"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+        t.Fatalf("response %q is not an error envelope: %v", rec.Body.String(), err)
+    }
+    return envelope.Error.Code
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+    "time"
+
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/atomicfile"
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/metrics"
+
+    "digital-oracle-server/payments"
+)
+
+// registry backs /metrics. Counters live here so the stores can record
+// without each being handed a metrics object.
+var (
+    registry    = metrics.NewRegistry()
+    httpMetrics = registry.NewHTTPMetrics()
+
+    votesCast         = registry.NewCounter("oracle_votes_cast_total", "Votes recorded, by ballot.", "ballot")
+    auditionsReceived = registry.NewCounter("oracle_auditions_received_total", "Audition submissions accepted.")
+    storeWriteSeconds = registry.NewHistogram("oracle_store_write_duration_seconds", "Time taken to persist a data file, by store.", nil, "store")
+)
+
+// writeStoreFile persists a store's data file and records how long it took.
+func writeStoreFile(store, path string, data []byte) error {
+    start := time.Now()
+    err := atomicfile.WriteFile(path, data, 0o644)
+    storeWriteSeconds.Observe(metrics.Since(start), store)
+    return err
+}
+
+// registerSignalBankMetrics exposes the ledger totals, read from the store
+// at scrape time.
+func registerSignalBankMetrics(bank *contributionStore) {
+    registry.NewGaugeFunc("oracle_signal_bank_amount", "Signal Bank money by balance kind.", []string{"kind"}, func(emit func(float64, ...string)) {
+        balance := bank.balance(time.Now())
+        emit(balance.Confirmed, "confirmed")
+        emit(balance.Reserved, "reserved")
+        emit(balance.Available, "available")
+        emit(balance.Refunded, "refunded")
+    })
+
+    registry.NewGaugeFunc("oracle_signal_bank_contributions", "Signal Bank contributions by payment status.", []string{"status"}, func(emit func(float64, ...string)) {
+        counts := map[payments.Status]int{
+            payments.StatusPending:   0,
+            payments.StatusConfirmed: 0,
+            payments.StatusFailed:    0,
+            payments.StatusRefunded:  0,
+        }
+        for _, entry := range bank.list() {
+            counts[entry.Status]++
+        }
+        for status, n := range counts {
+            emit(float64(n), string(status))
+        }
+    })
+}
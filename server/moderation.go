@@ -0,0 +1,136 @@
+package main
+
+import (
+    "bufio"
+    "os"
+    "strings"
+    "time"
+    "unicode"
+)
+
+type visibility string
+
+const (
+    visibilityPublic    visibility = "public"
+    visibilityInitials  visibility = "initials"
+    visibilityAnonymous visibility = "anonymous"
+)
+
+// defaultBlocklist covers common profanity; deployments extend it with
+// ORACLE_MODERATION_BLOCKLIST.
+var defaultBlocklist = []string{
+    "asshole", "bastard", "bitch", "bullshit", "cunt", "dickhead",
+    "fuck", "fucker", "fucking", "motherfucker", "shit", "slut", "whore",
+}
+
+type moderator struct {
+    words map[string]bool
+}
+
+// newModerator builds the blocklist filter, adding one word per line from
+// path when it is set. Lines starting with # are ignored.
+func newModerator(path string) (*moderator, error) {
+    m := &moderator{words: make(map[string]bool)}
+    for _, word := range defaultBlocklist {
+        m.words[word] = true
+    }
+
+    if path == "" {
+        return m, nil
+    }
+
+    file, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        m.words[line] = true
+    }
+    return m, scanner.Err()
+}
+
+// flagged returns the first blocklisted word found in text.
+func (m *moderator) flagged(text string) (string, bool) {
+    fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+    for _, word := range fields {
+        if m.words[word] {
+            return word, true
+        }
+    }
+    return "", false
+}
+
+// publicContribution is the only shape of a contribution served to the
+// public feed: the name is reduced per the contributor's visibility choice
+// and hidden messages are dropped.
+type publicContribution struct {
+    ID            string     `json:"id"`
+    Name          string     `json:"name"`
+    Amount        float64    `json:"amount"`
+    Message       string     `json:"message,omitempty"`
+    CampaignID    string     `json:"campaignId,omitempty"`
+    CreatedAt     time.Time  `json:"createdAt"`
+    ConfirmedAt   *time.Time `json:"confirmedAt,omitempty"`
+    ReservedUntil *time.Time `json:"reservedUntil,omitempty"`
+}
+
+func (c contribution) displayName() string {
+    switch {
+    case c.Visibility == visibilityAnonymous || strings.TrimSpace(c.Name) == "":
+        return "Anonymous"
+    case c.Visibility == visibilityInitials:
+        return anonymizeName(c.Name)
+    }
+    return c.Name
+}
+
+func (c contribution) public() publicContribution {
+    out := publicContribution{
+        ID:            c.ID,
+        Name:          c.displayName(),
+        Amount:        c.Amount,
+        CampaignID:    c.CampaignID,
+        CreatedAt:     c.CreatedAt,
+        ConfirmedAt:   c.ConfirmedAt,
+        ReservedUntil: c.ReservedUntil,
+    }
+    if !c.MessageHidden {
+        out.Message = c.Message
+    }
+    return out
+}
+
+// setMessageHidden lets an admin hide or restore a contribution message.
+func (s *contributionStore) setMessageHidden(id string, hidden bool, note string) (contribution, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for i := range s.contributions {
+        entry := &s.contributions[i]
+        if entry.ID != id {
+            continue
+        }
+        if entry.Message == "" {
+            return contribution{}, ErrNoMessage
+        }
+
+        entry.MessageHidden = hidden
+        entry.ModerationNote = note
+        entry.UpdatedAt = time.Now().UTC()
+
+        if err := s.saveLocked(); err != nil {
+            return contribution{}, err
+        }
+        return *entry, nil
+    }
+    return contribution{}, ErrContributionNotFound
+}
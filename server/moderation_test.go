@@ -0,0 +1,141 @@
+package main
+
+import (
+    "net/http"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "digital-oracle-server/payments"
+)
+
+func TestModeratorFlagged(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "blocklist.txt")
+    if err := os.WriteFile(path, []byte("# local additions\n\n  Spoiler  \n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    m, err := newModerator(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        text     string
+        wantWord string
+    }{
+        {"Go team!", ""},
+        {"What the SHIT, go team", "shit"},
+        {"bull-shit refereeing", "shit"},
+        {"greetings from Scunthorpe", ""},
+        {"no spoilers please", ""},
+        {"SPOILER: they win", "spoiler"},
+        {"# local additions", ""},
+    }
+    for _, tt := range tests {
+        word, ok := m.flagged(tt.text)
+        if word != tt.wantWord || ok != (tt.wantWord != "") {
+            t.Errorf("flagged(%q) = %q, %v; want %q", tt.text, word, ok, tt.wantWord)
+        }
+    }
+}
+
+func TestDisplayName(t *testing.T) {
+    tests := []struct {
+        name  string
+        shown visibility
+        want  string
+    }{
+        {"Ada Lovelace", visibilityPublic, "Ada Lovelace"},
+        {"Grace brewster Hopper", visibilityInitials, "G.B.H."},
+        {"Alan Turing", visibilityAnonymous, "Anonymous"},
+        {"   ", visibilityPublic, "Anonymous"},
+        {"", visibilityInitials, "Anonymous"},
+    }
+    for _, tt := range tests {
+        if got := (contribution{Name: tt.name, Visibility: tt.shown}).displayName(); got != tt.want {
+            t.Errorf("displayName(%q, %s) = %q, want %q", tt.name, tt.shown, got, tt.want)
+        }
+    }
+}
+
+func TestPublicFeedHidesContributors(t *testing.T) {
+    app, mux, fakeURL := newTestAPI(t)
+
+    ids := make(map[string]string)
+    for _, c := range []map[string]interface{}{
+        {"name": "Ada Lovelace", "amount": 10, "message": "Go team"},
+        {"name": "Grace Hopper", "visibility": "initials", "amount": 20, "message": "From Arlington"},
+        {"name": "Alan Turing", "visibility": "anonymous", "amount": 30, "message": "Bletchley sends its best"},
+        {"name": "Rude Person", "amount": 40, "message": "this is bullshit"},
+    } {
+        var created contribution
+        rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/contributions", c, &created)
+        if rec.Code != http.StatusCreated {
+            t.Fatalf("create contribution returned %d: %s", rec.Code, rec.Body.String())
+        }
+        settlePayment(t, fakeURL, created.PaymentID, "confirm")
+        ids[c["name"].(string)] = created.ID
+    }
+
+    feed := func() (map[string]publicContribution, string) {
+        var entries []publicContribution
+        rec := do(t, mux, http.MethodGet, "/api/v1/signal-bank/contributions", nil, &entries)
+        byID := make(map[string]publicContribution)
+        for _, e := range entries {
+            byID[e.ID] = e
+        }
+        return byID, rec.Body.String()
+    }
+
+    byID, body := feed()
+    for _, leak := range []string{"Grace", "Hopper", "Alan", "Turing", "bullshit", "visibility", "moderationNote", "paymentId"} {
+        if strings.Contains(body, leak) {
+            t.Errorf("public feed contains %q: %s", leak, body)
+        }
+    }
+    for name, want := range map[string]publicContribution{
+        "Ada Lovelace": {Name: "Ada Lovelace", Message: "Go team"},
+        "Grace Hopper": {Name: "G.H.", Message: "From Arlington"},
+        "Alan Turing":  {Name: "Anonymous", Message: "Bletchley sends its best"},
+        "Rude Person":  {Name: "Rude Person"},
+    } {
+        got := byID[ids[name]]
+        if got.Name != want.Name || got.Message != want.Message {
+            t.Errorf("%s is shown as %q saying %q, want %q saying %q", name, got.Name, got.Message, want.Name, want.Message)
+        }
+    }
+
+    // Admins still see who gave and why a message was held.
+    var all []contribution
+    do(t, mux, http.MethodGet, "/api/v1/signal-bank/admin/contributions?token="+testAdminToken, nil, &all)
+    for _, c := range all {
+        if c.ID == ids["Rude Person"] && (!c.MessageHidden || !strings.Contains(c.ModerationNote, "bullshit")) {
+            t.Errorf("admin view of the held message: %+v", c)
+        }
+        if c.ID == ids["Alan Turing"] && c.Name != "Alan Turing" {
+            t.Errorf("admin view of the anonymous contribution is named %q", c.Name)
+        }
+    }
+
+    moderate := func(id string, hidden bool) {
+        t.Helper()
+        rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/admin/moderation?token="+testAdminToken, map[string]interface{}{"contributionId": id, "hidden": hidden}, nil)
+        if rec.Code != http.StatusOK {
+            t.Fatalf("moderation returned %d: %s", rec.Code, rec.Body.String())
+        }
+    }
+    moderate(ids["Ada Lovelace"], true)
+    moderate(ids["Rude Person"], false)
+    byID, _ = feed()
+    if got := byID[ids["Ada Lovelace"]].Message; got != "" {
+        t.Errorf("hidden message still shown: %q", got)
+    }
+    if got := byID[ids["Rude Person"]].Message; got != "this is bullshit" {
+        t.Errorf("restored message shown as %q", got)
+    }
+
+    if len(app.bank.listByStatus(payments.StatusConfirmed)) != 4 {
+        t.Fatalf("moderation changed which contributions count")
+    }
+}
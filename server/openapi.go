@@ -0,0 +1,509 @@
+package main
+
+import (
+    "net/http"
+
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/openapi"
+
+    "digital-oracle-server/payments"
+)
+
+// Request bodies. The handlers decode through these with decodeBody, and
+// apiDocument publishes the same values, so /api/v1/openapi.json is always
+// the contract the server actually enforces.
+var (
+    auditionRequest = openapi.Object(map[string]*openapi.Schema{
+        "name":         openapi.String().Len(1, 120),
+        "country":      openapi.String().Len(1, 80),
+        "socialHandle": openapi.String().Len(0, 80),
+        "videoUrl":     openapi.String().Len(1, 500).WithFormat(openapi.FormatURI),
+        "message":      openapi.String().Len(0, 1000),
+    }, "name", "country", "videoUrl")
+
+    ballotRequest = openapi.Object(map[string]*openapi.Schema{
+        "title":       openapi.String().Len(0, 200),
+        "description": openapi.String().Len(0, 2000),
+        "closesAt":    openapi.String().WithFormat(openapi.FormatDateTime),
+        "nomineeIds":  openapi.Array(openapi.String().Len(1, 100)).Size(1, 50).Describe("Audition submission IDs to put on the ballot."),
+        "active":      openapi.Boolean().Describe("Defaults to true."),
+    }, "nomineeIds")
+
+    voteRequest = openapi.Object(map[string]*openapi.Schema{
+        "nomineeId": openapi.String().Len(1, 100),
+        "email":     openapi.String().Len(1, 254).WithFormat(openapi.FormatEmail).Describe("One vote per address per ballot."),
+        "name":      openapi.String().Len(0, 120),
+    }, "nomineeId", "email")
+
+    contributionRequest = openapi.Object(map[string]*openapi.Schema{
+        "name":       openapi.String().Len(0, 120),
+        "visibility": openapi.String().OneOf(string(visibilityPublic), string(visibilityInitials), string(visibilityAnonymous)).Describe("Defaults to public."),
+        "amount":     openapi.Number().Above(0).AtMost(1_000_000),
+        "message":    openapi.String().Len(0, 500),
+        "campaignId": openapi.String().Len(0, 100),
+    }, "amount")
+
+    campaignRequest = openapi.Object(map[string]*openapi.Schema{
+        "title":       openapi.String().Len(1, 200),
+        "description": openapi.String().Len(0, 2000),
+        "goal":        openapi.Number().Above(0),
+        "deadline":    openapi.String().WithFormat(openapi.FormatDateTime),
+        "beneficiary": openapi.String().Len(1, 200),
+        "overfunding": openapi.String().OneOf(string(overfundCap), string(overfundRollover)).Describe("Defaults to the server's configured policy."),
+    }, "title", "goal", "beneficiary")
+
+    campaignUpdateRequest = openapi.Object(map[string]*openapi.Schema{
+        "id":     openapi.String().Len(1, 100),
+        "status": openapi.String().OneOf(string(campaignActive), string(campaignClosed), string(campaignCancelled)),
+    }, "id", "status")
+
+    refundRequestRequest = openapi.Object(map[string]*openapi.Schema{
+        "claimToken": openapi.String().Len(1, 200).Describe("Returned once when the contribution was made."),
+        "reason":     openapi.String().Len(0, 500),
+    }, "claimToken")
+
+    refundActionRequest = openapi.Object(map[string]*openapi.Schema{
+        "contributionId": openapi.String().Len(1, 100),
+        "action":         openapi.String().OneOf("approve", "reject"),
+        "note":           openapi.String().Len(0, 500),
+    }, "contributionId", "action")
+
+    payoutRequest = openapi.Object(map[string]*openapi.Schema{
+        "amount":     openapi.Number().Above(0),
+        "recipient":  openapi.String().Len(1, 200),
+        "reason":     openapi.String().Len(1, 500),
+        "approvedBy": openapi.Array(openapi.String().Len(1, 120)).Size(1, 10),
+        "paidAt":     openapi.String().WithFormat(openapi.FormatDateTime).Describe("Defaults to now."),
+    }, "amount", "recipient", "reason", "approvedBy")
+
+    reportRequest = openapi.Object(map[string]*openapi.Schema{
+        "period": openapi.String().OneOf(periodWeekly, periodMonthly),
+        "date":   openapi.String().WithFormat(openapi.FormatDate).Describe("Any day inside the period; defaults to today."),
+    }, "period")
+
+    moderationRequest = openapi.Object(map[string]*openapi.Schema{
+        "contributionId": openapi.String().Len(1, 100),
+        "hidden":         openapi.Boolean(),
+        "note":           openapi.String().Len(0, 500),
+    }, "contributionId", "hidden")
+)
+
+// apiDocument describes the v1 routes registered by api.routesV1. Paths
+// are relative to the /api/v1 server; the deprecated /api aliases are not
+// listed.
+func apiDocument() *openapi.Document {
+    doc := openapi.New("Digital Oracle API", "1.0.0",
+        "Auditions, ballots and the Signal Bank ledger. Admin operations take the admin token as ?token=. "+
+            "String fields are trimmed before validation. Errors use the envelope in #/components/schemas/Error.")
+
+    doc.Servers = []openapi.Server{{URL: "/api/v1"}}
+
+    doc.SecurityScheme("adminToken", openapi.SecurityScheme{
+        Type:        "apiKey",
+        In:          "query",
+        Name:        "token",
+        Description: "The server's admin token. Admin routes answer 503 while none is configured.",
+    })
+    admin := []map[string][]string{{"adminToken": {}}}
+
+    timestamp := func() *openapi.Schema { return openapi.String().WithFormat(openapi.FormatDateTime) }
+
+    errorRef := doc.Schema("Error", openapi.Object(map[string]*openapi.Schema{
+        "error": openapi.Object(map[string]*openapi.Schema{
+            "code":    openapi.String(),
+            "message": openapi.String(),
+            "fields": openapi.Array(openapi.Object(map[string]*openapi.Schema{
+                "field":   openapi.String(),
+                "message": openapi.String(),
+            }, "field", "message")),
+        }, "code", "message"),
+    }, "error"))
+
+    submissionRef := doc.Schema("Submission", openapi.Object(map[string]*openapi.Schema{
+        "id":           openapi.String(),
+        "name":         openapi.String(),
+        "country":      openapi.String(),
+        "socialHandle": openapi.String(),
+        "videoUrl":     openapi.String(),
+        "message":      openapi.String(),
+        "createdAt":    timestamp(),
+    }))
+
+    ballotRef := doc.Schema("Ballot", openapi.Object(map[string]*openapi.Schema{
+        "id":          openapi.String(),
+        "title":       openapi.String(),
+        "description": openapi.String(),
+        "nominees": openapi.Array(openapi.Object(map[string]*openapi.Schema{
+            "id":           openapi.String(),
+            "submissionId": openapi.String(),
+            "name":         openapi.String(),
+            "country":      openapi.String(),
+            "socialHandle": openapi.String(),
+            "videoUrl":     openapi.String(),
+            "message":      openapi.String(),
+            "votes":        openapi.Integer(),
+        })),
+        "active":    openapi.Boolean(),
+        "createdAt": timestamp(),
+        "closesAt":  timestamp(),
+    }))
+
+    paymentStatus := openapi.String().OneOf(string(payments.StatusPending), string(payments.StatusConfirmed), string(payments.StatusFailed), string(payments.StatusRefunded))
+    refundRef := doc.Schema("RefundRequest", openapi.Object(map[string]*openapi.Schema{
+        "status":      openapi.String().OneOf(string(refundRequested), string(refundProcessing), string(refundCompleted), string(refundRejected)),
+        "reason":      openapi.String(),
+        "requestedAt": timestamp(),
+        "processedAt": timestamp(),
+        "refundId":    openapi.String(),
+        "note":        openapi.String(),
+    }))
+
+    contributionFields := map[string]*openapi.Schema{
+        "id":             openapi.String(),
+        "name":           openapi.String(),
+        "visibility":     openapi.String(),
+        "amount":         openapi.Number(),
+        "message":        openapi.String(),
+        "messageHidden":  openapi.Boolean(),
+        "moderationNote": openapi.String(),
+        "campaignId":     openapi.String(),
+        "status":         paymentStatus,
+        "provider":       openapi.String(),
+        "paymentId":      openapi.String(),
+        "createdAt":      timestamp(),
+        "confirmedAt":    timestamp(),
+        "reservedUntil":  timestamp(),
+        "refund":         refundRef,
+        "updatedAt":      timestamp(),
+    }
+    contributionRef := doc.Schema("Contribution", openapi.Object(contributionFields))
+
+    receiptFields := map[string]*openapi.Schema{
+        "clientSecret": openapi.String().Describe("Hand to the payment provider's client library to complete payment."),
+        "claimToken":   openapi.String().Describe("Needed to request a refund. Only returned here."),
+        "refundWindow": openapi.String(),
+    }
+    for name, field := range contributionFields {
+        receiptFields[name] = field
+    }
+    receiptRef := doc.Schema("ContributionReceipt", openapi.Object(receiptFields))
+
+    publicContributionRef := doc.Schema("PublicContribution", openapi.Object(map[string]*openapi.Schema{
+        "id":            openapi.String(),
+        "name":          openapi.String().Describe("Shown according to the contributor's visibility choice."),
+        "amount":        openapi.Number(),
+        "message":       openapi.String(),
+        "campaignId":    openapi.String(),
+        "createdAt":     timestamp(),
+        "confirmedAt":   timestamp(),
+        "reservedUntil": timestamp(),
+    }))
+
+    campaignRef := doc.Schema("Campaign", openapi.Object(map[string]*openapi.Schema{
+        "id":           openapi.String(),
+        "title":        openapi.String(),
+        "description":  openapi.String(),
+        "goal":         openapi.Number(),
+        "deadline":     timestamp(),
+        "beneficiary":  openapi.String(),
+        "status":       openapi.String(),
+        "overfunding":  openapi.String(),
+        "createdAt":    timestamp(),
+        "raised":       openapi.Number(),
+        "pending":      openapi.Number(),
+        "rolledOver":   openapi.Number(),
+        "percent":      openapi.Number(),
+        "contributors": openapi.Integer(),
+        "state":        openapi.String(),
+    }))
+
+    balanceRef := doc.Schema("Balance", openapi.Object(map[string]*openapi.Schema{
+        "confirmed": openapi.Number(),
+        "reserved":  openapi.Number(),
+        "available": openapi.Number(),
+        "refunded":  openapi.Number(),
+    }))
+
+    payoutRef := doc.Schema("Payout", openapi.Object(map[string]*openapi.Schema{
+        "id":         openapi.String(),
+        "amount":     openapi.Number(),
+        "recipient":  openapi.String(),
+        "reason":     openapi.String(),
+        "approvedBy": openapi.Array(openapi.String()),
+        "paidAt":     timestamp(),
+        "createdAt":  timestamp(),
+    }))
+
+    reportRef := doc.Schema("PublishedReport", openapi.Object(map[string]*openapi.Schema{
+        "name":  openapi.String(),
+        "files": openapi.Object(nil).Describe("File kind to download path."),
+    }))
+
+    invalid := openapi.JSON("Validation failed or the body was not JSON.", errorRef)
+    unauthorized := openapi.JSON("Missing or wrong admin token.", errorRef)
+    adminDisabled := openapi.JSON("No admin token is configured.", errorRef)
+    notFound := openapi.JSON("The referenced record does not exist.", errorRef)
+    conflict := openapi.JSON("The request conflicts with the record's current state.", errorRef)
+    unavailable := openapi.JSON("Payments are not configured.", errorRef)
+    limit := openapi.Parameter{Name: "limit", In: "query", Description: "Return at most this many entries.", Schema: openapi.Integer().AtLeast(0)}
+
+    doc.Add(http.MethodPost, "/auditions", &openapi.Operation{
+        Summary:     "Submit an audition",
+        Tags:        []string{"auditions"},
+        RequestBody: openapi.JSONBody(auditionRequest),
+        Responses: map[string]openapi.Response{
+            "201": openapi.JSON("Submission recorded.", submissionRef),
+            "400": invalid,
+        },
+    })
+    doc.Add(http.MethodGet, "/auditions", &openapi.Operation{
+        Summary:  "List audition submissions",
+        Tags:     []string{"auditions"},
+        Security: admin,
+        Parameters: []openapi.Parameter{
+            {Name: "country", In: "query", Description: "Only submissions from this country (case-insensitive).", Schema: openapi.String()},
+            limit,
+        },
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Submissions, newest first.", openapi.Array(submissionRef)),
+            "401": unauthorized,
+            "503": adminDisabled,
+        },
+    })
+
+    doc.Add(http.MethodGet, "/ballot", &openapi.Operation{
+        Summary:   "Current ballot with vote counts",
+        Tags:      []string{"voting"},
+        Responses: map[string]openapi.Response{"200": openapi.JSON("The active ballot.", ballotRef)},
+    })
+    doc.Add(http.MethodPost, "/ballot", &openapi.Operation{
+        Summary:     "Replace the ballot",
+        Tags:        []string{"voting"},
+        Security:    admin,
+        RequestBody: openapi.JSONBody(ballotRequest),
+        Responses: map[string]openapi.Response{
+            "201": openapi.JSON("Ballot created.", ballotRef),
+            "400": openapi.JSON("Validation failed, or a nominee ID is not a submission.", errorRef),
+            "401": unauthorized,
+            "503": adminDisabled,
+        },
+    })
+    doc.Add(http.MethodPost, "/ballot/close", &openapi.Operation{
+        Summary:  "Close the ballot",
+        Tags:     []string{"voting"},
+        Security: admin,
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Voting stopped; the tallies are kept.", ballotRef),
+            "400": openapi.JSON("No ballot is open.", errorRef),
+            "401": unauthorized,
+            "503": adminDisabled,
+        },
+    })
+
+    doc.Add(http.MethodPost, "/vote", &openapi.Operation{
+        Summary:     "Cast a vote",
+        Tags:        []string{"voting"},
+        RequestBody: openapi.JSONBody(voteRequest),
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Vote recorded.", openapi.Object(map[string]*openapi.Schema{
+                "ballotId":  openapi.String(),
+                "nomineeId": openapi.String(),
+                "votes":     openapi.Integer(),
+                "message":   openapi.String(),
+            })),
+            "400": openapi.JSON("Validation failed, no ballot is open, or the nominee is not on it.", errorRef),
+            "409": openapi.JSON("This email has already voted on the ballot.", errorRef),
+        },
+    })
+
+    doc.Add(http.MethodPost, "/signal-bank/contributions", &openapi.Operation{
+        Summary:     "Start a contribution",
+        Description: "Creates a pending contribution and a payment intent. The claim token is only ever returned here.",
+        Tags:        []string{"signal-bank"},
+        Parameters: []openapi.Parameter{
+            {Name: "Idempotency-Key", In: "header", Description: "Passed to the payment provider.", Schema: openapi.String()},
+        },
+        RequestBody: openapi.JSONBody(contributionRequest),
+        Responses: map[string]openapi.Response{
+            "201": openapi.JSON("Contribution pending payment.", receiptRef),
+            "400": invalid,
+            "404": notFound,
+            "409": conflict,
+            "502": openapi.JSON("The payment provider failed.", errorRef),
+            "503": unavailable,
+        },
+    })
+    doc.Add(http.MethodGet, "/signal-bank/contributions", &openapi.Operation{
+        Summary:    "Public feed of confirmed contributions",
+        Tags:       []string{"signal-bank"},
+        Parameters: []openapi.Parameter{limit},
+        Responses:  map[string]openapi.Response{"200": openapi.JSON("Confirmed contributions.", openapi.Array(publicContributionRef))},
+    })
+
+    doc.Add(http.MethodPost, "/signal-bank/webhook", &openapi.Operation{
+        Summary:     "Payment provider webhook",
+        Description: "Signed with the configured webhook secret in the " + payments.SignatureHeader + " header.",
+        Tags:        []string{"signal-bank"},
+        Responses: map[string]openapi.Response{
+            "200": openapi.Status("Event applied or ignored."),
+            "400": openapi.JSON("Bad signature or unreadable event.", errorRef),
+            "503": unavailable,
+        },
+    })
+
+    doc.Add(http.MethodGet, "/signal-bank/campaigns", &openapi.Operation{
+        Summary:   "Campaigns with progress",
+        Tags:      []string{"signal-bank"},
+        Responses: map[string]openapi.Response{"200": openapi.JSON("All campaigns.", openapi.Array(campaignRef))},
+    })
+    doc.Add(http.MethodPost, "/signal-bank/campaigns", &openapi.Operation{
+        Summary:     "Create a campaign",
+        Tags:        []string{"signal-bank"},
+        Security:    admin,
+        RequestBody: openapi.JSONBody(campaignRequest),
+        Responses: map[string]openapi.Response{
+            "201": openapi.JSON("Campaign created.", campaignRef),
+            "400": invalid,
+            "401": unauthorized,
+            "503": adminDisabled,
+        },
+    })
+    doc.Add(http.MethodPatch, "/signal-bank/campaigns", &openapi.Operation{
+        Summary:     "Change a campaign's status",
+        Tags:        []string{"signal-bank"},
+        Security:    admin,
+        RequestBody: openapi.JSONBody(campaignUpdateRequest),
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Campaign updated.", campaignRef),
+            "400": invalid,
+            "401": unauthorized,
+            "404": notFound,
+            "503": adminDisabled,
+        },
+    })
+
+    doc.Add(http.MethodGet, "/signal-bank/balance", &openapi.Operation{
+        Summary:   "Ledger balance",
+        Tags:      []string{"signal-bank"},
+        Responses: map[string]openapi.Response{"200": openapi.JSON("Current balance.", balanceRef)},
+    })
+
+    doc.Add(http.MethodPost, "/signal-bank/refund-requests", &openapi.Operation{
+        Summary:     "Request a refund with a claim token",
+        Tags:        []string{"signal-bank"},
+        RequestBody: openapi.JSONBody(refundRequestRequest),
+        Responses: map[string]openapi.Response{
+            "202": openapi.JSON("Refund requested.", openapi.Object(map[string]*openapi.Schema{
+                "contributionId": openapi.String(),
+                "status":         openapi.String(),
+                "message":        openapi.String(),
+            })),
+            "400": invalid,
+            "404": openapi.JSON("The claim token does not match a contribution.", errorRef),
+            "409": openapi.JSON("A refund was already requested.", errorRef),
+            "422": openapi.JSON("The contribution is not refundable or the refund window has closed.", errorRef),
+        },
+    })
+
+    doc.Add(http.MethodGet, "/signal-bank/admin/refunds", &openapi.Operation{
+        Summary:  "Contributions with refund requests",
+        Tags:     []string{"signal-bank admin"},
+        Security: admin,
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Refund queue.", openapi.Array(contributionRef)),
+            "401": unauthorized,
+            "503": adminDisabled,
+        },
+    })
+    doc.Add(http.MethodPost, "/signal-bank/admin/refunds", &openapi.Operation{
+        Summary:     "Approve or reject a refund",
+        Tags:        []string{"signal-bank admin"},
+        Security:    admin,
+        RequestBody: openapi.JSONBody(refundActionRequest),
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Refund resolved.", contributionRef),
+            "400": invalid,
+            "401": unauthorized,
+            "404": notFound,
+            "409": conflict,
+            "502": openapi.JSON("The payment provider rejected the refund.", errorRef),
+            "503": openapi.JSON("Payments or the admin token are not configured.", errorRef),
+        },
+    })
+
+    doc.Add(http.MethodGet, "/signal-bank/admin/payouts", &openapi.Operation{
+        Summary:  "Recorded payouts",
+        Tags:     []string{"signal-bank admin"},
+        Security: admin,
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Payouts.", openapi.Array(payoutRef)),
+            "401": unauthorized,
+            "503": adminDisabled,
+        },
+    })
+    doc.Add(http.MethodPost, "/signal-bank/admin/payouts", &openapi.Operation{
+        Summary:     "Record a payout",
+        Tags:        []string{"signal-bank admin"},
+        Security:    admin,
+        RequestBody: openapi.JSONBody(payoutRequest),
+        Responses: map[string]openapi.Response{
+            "201": openapi.JSON("Payout recorded.", payoutRef),
+            "400": invalid,
+            "401": unauthorized,
+            "503": adminDisabled,
+        },
+    })
+
+    doc.Add(http.MethodGet, "/signal-bank/reports", &openapi.Operation{
+        Summary: "Published transparency reports",
+        Tags:    []string{"signal-bank"},
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Reports and the key that signs them.", openapi.Object(map[string]*openapi.Schema{
+                "publicKey": openapi.String(),
+                "reports":   openapi.Array(reportRef),
+            })),
+        },
+    })
+    doc.Add(http.MethodPost, "/signal-bank/admin/reports", &openapi.Operation{
+        Summary:     "Publish a transparency report",
+        Tags:        []string{"signal-bank admin"},
+        Security:    admin,
+        RequestBody: openapi.JSONBody(reportRequest),
+        Responses: map[string]openapi.Response{
+            "201": openapi.JSON("Report published.", reportRef),
+            "400": invalid,
+            "401": unauthorized,
+            "503": openapi.JSON("The server is shutting down, or no admin token is configured.", errorRef),
+        },
+    })
+
+    doc.Add(http.MethodPost, "/signal-bank/admin/moderation", &openapi.Operation{
+        Summary:     "Hide or show a contribution message",
+        Tags:        []string{"signal-bank admin"},
+        Security:    admin,
+        RequestBody: openapi.JSONBody(moderationRequest),
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Contribution updated.", contributionRef),
+            "400": invalid,
+            "401": unauthorized,
+            "404": notFound,
+            "503": adminDisabled,
+        },
+    })
+
+    doc.Add(http.MethodGet, "/signal-bank/admin/contributions", &openapi.Operation{
+        Summary:  "All contributions",
+        Tags:     []string{"signal-bank admin"},
+        Security: admin,
+        Parameters: []openapi.Parameter{
+            {Name: "status", In: "query", Schema: paymentStatus},
+            {Name: "hidden", In: "query", Description: "true to list only held messages.", Schema: openapi.Boolean()},
+        },
+        Responses: map[string]openapi.Response{
+            "200": openapi.JSON("Contributions.", openapi.Array(contributionRef)),
+            "401": unauthorized,
+            "503": adminDisabled,
+        },
+    })
+
+    return doc
+}
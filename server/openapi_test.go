@@ -0,0 +1,60 @@
+package main
+
+import (
+    "net/http"
+    "reflect"
+    "testing"
+
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/router"
+)
+
+// TestRoutesMatchDocument keeps the published API description and the
+// mounted routes in step. The document does not describe itself.
+func TestRoutesMatchDocument(t *testing.T) {
+    app, _, _ := newTestAPI(t)
+
+    var mounted []string
+    for _, route := range router.Routes(app.routesV1) {
+        if route != "GET /openapi.json" {
+            mounted = append(mounted, route)
+        }
+    }
+    if documented := apiDocument().Routes(); !reflect.DeepEqual(mounted, documented) {
+        t.Fatalf("mounted routes\n  %v\ndo not match the document\n  %v", mounted, documented)
+    }
+}
+
+func TestRequestBodiesAreValidated(t *testing.T) {
+    _, mux, _ := newTestAPI(t)
+
+    tests := []struct {
+        name       string
+        body       interface{}
+        wantCode   string
+        wantFields []FieldError
+    }{
+        {
+            name:     "every problem at once",
+            body:     map[string]interface{}{"amount": 0, "visibility": "secret"},
+            wantCode: "validation_failed",
+            wantFields: []FieldError{
+                {Field: "amount", Message: "must be greater than zero"},
+                {Field: "visibility", Message: "must be public, initials or anonymous"},
+            },
+        },
+        {
+            name:       "whitespace is trimmed first",
+            body:       map[string]interface{}{"amount": 5, "visibility": "  "},
+            wantCode:   "validation_failed",
+            wantFields: []FieldError{{Field: "visibility", Message: "must be public, initials or anonymous"}},
+        },
+        {name: "not JSON", body: []byte(`{"amount":`), wantCode: "invalid_json"},
+    }
+    for _, tt := range tests {
+        var envelope errorEnvelope
+        rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/contributions", tt.body, &envelope)
+        if rec.Code != http.StatusBadRequest || envelope.Error.Code != tt.wantCode || !reflect.DeepEqual(envelope.Error.Fields, tt.wantFields) {
+            t.Errorf("%s: got %d %s, want 400 %s with %v", tt.name, rec.Code, rec.Body.String(), tt.wantCode, tt.wantFields)
+        }
+    }
+}
@@ -0,0 +1,234 @@
+package payments
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// FakeServer is a local stand-in for the Stripe API. It accepts the same
+// payment intent calls the Stripe provider makes and, when an intent is
+// settled, delivers a signed webhook to WebhookURL exactly like Stripe would.
+type FakeServer struct {
+    WebhookURL    string
+    WebhookSecret string
+
+    mu      sync.Mutex
+    seq     int
+    intents map[string]*stripeIntent
+    client  *http.Client
+    mux     *http.ServeMux
+}
+
+// NewFakeServer returns a fake provider that signs webhooks with webhookSecret.
+func NewFakeServer(webhookURL, webhookSecret string) *FakeServer {
+    f := &FakeServer{
+        WebhookURL:    webhookURL,
+        WebhookSecret: webhookSecret,
+        intents:       make(map[string]*stripeIntent),
+        client:        &http.Client{Timeout: 10 * time.Second},
+        mux:           http.NewServeMux(),
+    }
+
+    f.mux.HandleFunc("POST /v1/payment_intents", f.handleCreate)
+    f.mux.HandleFunc("GET /v1/payment_intents/{id}", f.handleGet)
+    f.mux.HandleFunc("POST /v1/payment_intents/{id}/confirm", f.settle("payment_intent.succeeded", "succeeded"))
+    f.mux.HandleFunc("POST /v1/payment_intents/{id}/cancel", f.settle("payment_intent.canceled", "canceled"))
+    f.mux.HandleFunc("POST /v1/refunds", f.handleRefund)
+    // Not part of the Stripe API: lets a test drive the failure path.
+    f.mux.HandleFunc("POST /v1/test_helpers/payment_intents/{id}/fail", f.settle("payment_intent.payment_failed", "requires_payment_method"))
+    return f
+}
+
+func (f *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if r.Header.Get("Authorization") == "" {
+        writeFakeError(w, http.StatusUnauthorized, "authentication_error", "missing API key")
+        return
+    }
+    f.mux.ServeHTTP(w, r)
+}
+
+func (f *FakeServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+    if err := r.ParseForm(); err != nil {
+        writeFakeError(w, http.StatusBadRequest, "invalid_request_error", "malformed form body")
+        return
+    }
+
+    amount, err := strconv.ParseInt(r.PostForm.Get("amount"), 10, 64)
+    if err != nil || amount <= 0 {
+        writeFakeError(w, http.StatusBadRequest, "invalid_request_error", "amount must be a positive integer")
+        return
+    }
+
+    metadata := make(map[string]string)
+    for key, values := range r.PostForm {
+        if name, ok := strings.CutPrefix(key, "metadata["); ok && strings.HasSuffix(name, "]") {
+            metadata[strings.TrimSuffix(name, "]")] = values[0]
+        }
+    }
+
+    f.mu.Lock()
+    f.seq++
+    id := fmt.Sprintf("pi_fake_%d", f.seq)
+    intent := &stripeIntent{
+        ID:           id,
+        Object:       "payment_intent",
+        Amount:       amount,
+        Currency:     r.PostForm.Get("currency"),
+        Status:       "requires_payment_method",
+        ClientSecret: id + "_secret_fake",
+        Metadata:     metadata,
+    }
+    f.intents[id] = intent
+    out := *intent
+    f.mu.Unlock()
+
+    writeFakeJSON(w, http.StatusOK, out)
+}
+
+func (f *FakeServer) handleGet(w http.ResponseWriter, r *http.Request) {
+    f.mu.Lock()
+    intent, ok := f.intents[r.PathValue("id")]
+    var out stripeIntent
+    if ok {
+        out = *intent
+    }
+    f.mu.Unlock()
+
+    if !ok {
+        writeFakeError(w, http.StatusNotFound, "invalid_request_error", "no such payment_intent")
+        return
+    }
+    writeFakeJSON(w, http.StatusOK, out)
+}
+
+func (f *FakeServer) handleRefund(w http.ResponseWriter, r *http.Request) {
+    if err := r.ParseForm(); err != nil {
+        writeFakeError(w, http.StatusBadRequest, "invalid_request_error", "malformed form body")
+        return
+    }
+
+    f.mu.Lock()
+    intent, ok := f.intents[r.PostForm.Get("payment_intent")]
+    var charge stripeIntent
+    var refundID string
+    if ok && intent.Status == "succeeded" {
+        f.seq++
+        refundID = fmt.Sprintf("re_fake_%d", f.seq)
+        charge = stripeIntent{
+            ID:            fmt.Sprintf("ch_fake_%d", f.seq),
+            Object:        "charge",
+            Amount:        intent.Amount,
+            Currency:      intent.Currency,
+            Status:        "succeeded",
+            PaymentIntent: intent.ID,
+            Metadata:      intent.Metadata,
+        }
+    }
+    f.mu.Unlock()
+
+    if !ok {
+        writeFakeError(w, http.StatusNotFound, "invalid_request_error", "no such payment_intent")
+        return
+    }
+    if refundID == "" {
+        writeFakeError(w, http.StatusBadRequest, "invalid_request_error", "payment_intent has not succeeded")
+        return
+    }
+
+    if err := f.deliver("charge.refunded", charge); err != nil {
+        writeFakeError(w, http.StatusBadGateway, "api_error", err.Error())
+        return
+    }
+
+    writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+        "id":             refundID,
+        "object":         "refund",
+        "amount":         charge.Amount,
+        "payment_intent": charge.PaymentIntent,
+        "status":         "succeeded",
+    })
+}
+
+func (f *FakeServer) settle(eventType, status string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        f.mu.Lock()
+        intent, ok := f.intents[r.PathValue("id")]
+        var out stripeIntent
+        if ok {
+            intent.Status = status
+            out = *intent
+        }
+        f.mu.Unlock()
+
+        if !ok {
+            writeFakeError(w, http.StatusNotFound, "invalid_request_error", "no such payment_intent")
+            return
+        }
+
+        if err := f.deliver(eventType, out); err != nil {
+            writeFakeError(w, http.StatusBadGateway, "api_error", err.Error())
+            return
+        }
+        writeFakeJSON(w, http.StatusOK, out)
+    }
+}
+
+// deliver posts a signed event for object to the configured webhook URL.
+func (f *FakeServer) deliver(eventType string, object stripeIntent) error {
+    if f.WebhookURL == "" {
+        return nil
+    }
+
+    f.mu.Lock()
+    f.seq++
+    eventID := fmt.Sprintf("evt_fake_%d", f.seq)
+    f.mu.Unlock()
+
+    payload, err := json.Marshal(map[string]interface{}{
+        "id":      eventID,
+        "object":  "event",
+        "type":    eventType,
+        "created": time.Now().Unix(),
+        "data":    map[string]interface{}{"object": object},
+    })
+    if err != nil {
+        return err
+    }
+
+    req, err := http.NewRequest(http.MethodPost, f.WebhookURL, bytes.NewReader(payload))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set(SignatureHeader, Sign(payload, f.WebhookSecret, time.Now()))
+
+    resp, err := f.client.Do(req)
+    if err != nil {
+        return fmt.Errorf("webhook delivery failed: %w", err)
+    }
+    resp.Body.Close()
+
+    if resp.StatusCode >= 300 {
+        return fmt.Errorf("webhook endpoint returned %s", resp.Status)
+    }
+    return nil
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, errType, message string) {
+    var body stripeError
+    body.Error.Type = errType
+    body.Error.Message = message
+    writeFakeJSON(w, status, body)
+}
@@ -0,0 +1,157 @@
+package payments
+
+import (
+    "context"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+)
+
+// webhookRecorder is a webhook endpoint that parses each delivery with the
+// Stripe provider, as the server does.
+type webhookRecorder struct {
+    provider *Stripe
+
+    mu     sync.Mutex
+    events []Event
+}
+
+func (rec *webhookRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    body, _ := io.ReadAll(r.Body)
+    event, err := rec.provider.ParseWebhook(body, r.Header.Get(SignatureHeader))
+    if err != nil && err != ErrUnhandledEvent {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    rec.mu.Lock()
+    rec.events = append(rec.events, event)
+    rec.mu.Unlock()
+}
+
+func (rec *webhookRecorder) last(t *testing.T) Event {
+    t.Helper()
+    rec.mu.Lock()
+    defer rec.mu.Unlock()
+    if len(rec.events) == 0 {
+        t.Fatal("no webhook delivered")
+    }
+    return rec.events[len(rec.events)-1]
+}
+
+// newFakeStripe starts a FakeServer and a webhook endpoint and returns a
+// Stripe provider pointed at the fake.
+func newFakeStripe(t *testing.T, webhookSecret string) (*Stripe, *webhookRecorder) {
+    t.Helper()
+    rec := &webhookRecorder{}
+    hooks := httptest.NewServer(rec)
+    t.Cleanup(hooks.Close)
+
+    api := httptest.NewServer(NewFakeServer(hooks.URL, "whsec_fake"))
+    t.Cleanup(api.Close)
+
+    rec.provider = NewStripe("sk_test", webhookSecret, api.URL)
+    return rec.provider, rec
+}
+
+func settleIntent(t *testing.T, baseURL, path string) int {
+    t.Helper()
+    req, err := http.NewRequest(http.MethodPost, baseURL+path, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    req.Header.Set("Authorization", "Bearer sk_test")
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp.Body.Close()
+    return resp.StatusCode
+}
+
+func TestFakeServerSettlesThroughSignedWebhooks(t *testing.T) {
+    stripe, rec := newFakeStripe(t, "whsec_fake")
+    ctx := context.Background()
+
+    tests := []struct {
+        path string
+        want Status
+    }{
+        {"/confirm", StatusConfirmed},
+        {"/cancel", StatusFailed},
+    }
+    for _, tt := range tests {
+        intent, err := stripe.CreateIntent(ctx, IntentRequest{AmountCents: 2500, Currency: "usd"})
+        if err != nil {
+            t.Fatalf("CreateIntent: %v", err)
+        }
+        if code := settleIntent(t, stripe.baseURL, "/v1/payment_intents/"+intent.ID+tt.path); code != http.StatusOK {
+            t.Fatalf("%s returned %d", tt.path, code)
+        }
+        if event := rec.last(t); event.IntentID != intent.ID || event.Status != tt.want {
+            t.Fatalf("%s delivered %+v, want %s for %s", tt.path, event, tt.want, intent.ID)
+        }
+    }
+}
+
+func TestFakeServerFailAndRefund(t *testing.T) {
+    stripe, rec := newFakeStripe(t, "whsec_fake")
+    ctx := context.Background()
+
+    intent, err := stripe.CreateIntent(ctx, IntentRequest{AmountCents: 1000})
+    if err != nil {
+        t.Fatalf("CreateIntent: %v", err)
+    }
+    if _, err := stripe.Refund(ctx, RefundRequest{IntentID: intent.ID}); err == nil {
+        t.Fatal("refund of an unpaid intent succeeded")
+    }
+
+    settleIntent(t, stripe.baseURL, "/v1/test_helpers/payment_intents/"+intent.ID+"/fail")
+    if event := rec.last(t); event.Status != StatusFailed {
+        t.Fatalf("fail delivered %s, want failed", event.Status)
+    }
+
+    // A declined card can be retried on the same intent.
+    settleIntent(t, stripe.baseURL, "/v1/payment_intents/"+intent.ID+"/confirm")
+    if event := rec.last(t); event.Status != StatusConfirmed {
+        t.Fatalf("retry delivered %s, want confirmed", event.Status)
+    }
+
+    refund, err := stripe.Refund(ctx, RefundRequest{IntentID: intent.ID, Reason: "changed my mind"})
+    if err != nil {
+        t.Fatalf("Refund: %v", err)
+    }
+    if refund.IntentID != intent.ID {
+        t.Fatalf("refund for %q, want %q", refund.IntentID, intent.ID)
+    }
+    if event := rec.last(t); event.IntentID != intent.ID || event.Status != StatusRefunded {
+        t.Fatalf("refund delivered %+v, want refunded for %s", event, intent.ID)
+    }
+}
+
+func TestFakeServerWebhooksFailWithWrongSecret(t *testing.T) {
+    stripe, _ := newFakeStripe(t, "whsec_other")
+    intent, err := stripe.CreateIntent(context.Background(), IntentRequest{AmountCents: 500})
+    if err != nil {
+        t.Fatalf("CreateIntent: %v", err)
+    }
+    // The endpoint rejects the signature, so the fake reports the failed delivery.
+    if code := settleIntent(t, stripe.baseURL, "/v1/payment_intents/"+intent.ID+"/confirm"); code != http.StatusBadGateway {
+        t.Fatalf("confirm with a rejected webhook returned %d, want %d", code, http.StatusBadGateway)
+    }
+}
+
+func TestFakeServerRequiresAPIKey(t *testing.T) {
+    api := httptest.NewServer(NewFakeServer("", "whsec_fake"))
+    defer api.Close()
+
+    resp, err := http.Post(api.URL+"/v1/payment_intents", "application/x-www-form-urlencoded", nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusUnauthorized {
+        t.Fatalf("unauthenticated request returned %d, want 401", resp.StatusCode)
+    }
+}
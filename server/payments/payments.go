@@ -0,0 +1,146 @@
+package payments
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Status is the lifecycle state of a contribution's payment.
+type Status string
+
+const (
+    StatusPending   Status = "pending"
+    StatusConfirmed Status = "confirmed"
+    StatusFailed    Status = "failed"
+    StatusRefunded  Status = "refunded"
+)
+
+// Intent is a payment the provider has been asked to collect.
+type Intent struct {
+    ID           string `json:"id"`
+    ClientSecret string `json:"clientSecret"`
+    AmountCents  int64  `json:"amountCents"`
+    Currency     string `json:"currency"`
+}
+
+// IntentRequest describes a payment to open with the provider.
+type IntentRequest struct {
+    AmountCents    int64
+    Currency       string
+    Description    string
+    Metadata       map[string]string
+    IdempotencyKey string
+}
+
+// RefundRequest asks the provider to return all or part of a settled intent.
+type RefundRequest struct {
+    IntentID       string
+    AmountCents    int64
+    Reason         string
+    IdempotencyKey string
+}
+
+// Refund is the provider's record of a refund. Completion is reported later
+// through a webhook, just like the original payment.
+type Refund struct {
+    ID       string `json:"id"`
+    IntentID string `json:"intentId"`
+    Status   string `json:"status"`
+}
+
+// Event is a verified webhook notification about an intent.
+type Event struct {
+    ID       string
+    Type     string
+    IntentID string
+    Status   Status
+}
+
+// Provider opens payment intents and authenticates the webhooks that settle them.
+type Provider interface {
+    Name() string
+    CreateIntent(ctx context.Context, req IntentRequest) (Intent, error)
+    Refund(ctx context.Context, req RefundRequest) (Refund, error)
+    ParseWebhook(payload []byte, signatureHeader string) (Event, error)
+}
+
+var (
+    ErrInvalidSignature = errors.New("invalid webhook signature")
+    ErrUnhandledEvent   = errors.New("unhandled webhook event")
+)
+
+// SignatureHeader is the header carrying the webhook signature.
+const SignatureHeader = "Stripe-Signature"
+
+// signatureTolerance bounds how old a signed webhook may be before it is
+// treated as a replay.
+const signatureTolerance = 5 * time.Minute
+
+// Sign produces a Stripe-style signature header value for payload.
+func Sign(payload []byte, secret string, at time.Time) string {
+    ts := strconv.FormatInt(at.Unix(), 10)
+    return fmt.Sprintf("t=%s,v1=%s", ts, computeSignature(ts, payload, secret))
+}
+
+// VerifySignature checks a Stripe-style "t=...,v1=..." header against payload.
+func VerifySignature(payload []byte, header, secret string, now time.Time) error {
+    if secret == "" || header == "" {
+        return ErrInvalidSignature
+    }
+
+    var ts string
+    var signatures []string
+    for _, part := range strings.Split(header, ",") {
+        key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+        if !ok {
+            continue
+        }
+        switch key {
+        case "t":
+            ts = value
+        case "v1":
+            signatures = append(signatures, value)
+        }
+    }
+
+    unix, err := strconv.ParseInt(ts, 10, 64)
+    if err != nil || len(signatures) == 0 {
+        return ErrInvalidSignature
+    }
+
+    age := now.Sub(time.Unix(unix, 0))
+    if age > signatureTolerance || age < -signatureTolerance {
+        return ErrInvalidSignature
+    }
+
+    expected := computeSignature(ts, payload, secret)
+    for _, sig := range signatures {
+        if hmac.Equal([]byte(sig), []byte(expected)) {
+            return nil
+        }
+    }
+    return ErrInvalidSignature
+}
+
+func computeSignature(ts string, payload []byte, secret string) string {
+    mac := hmac.New(sha256.New, []byte(secret))
+    mac.Write([]byte(ts))
+    mac.Write([]byte("."))
+    mac.Write(payload)
+    return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ToCents converts a dollar amount to integer cents.
+func ToCents(amount float64) int64 {
+    if amount < 0 {
+        return -int64(-amount*100 + 0.5)
+    }
+    return int64(amount*100 + 0.5)
+}
@@ -0,0 +1,65 @@
+package payments
+
+import (
+    "strconv"
+    "testing"
+    "time"
+)
+
+func TestVerifySignature(t *testing.T) {
+    const secret = "whsec_test"
+    payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded"}`)
+    now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+    tests := []struct {
+        name    string
+        header  string
+        payload []byte
+        wantErr bool
+    }{
+        {name: "valid", header: Sign(payload, secret, now)},
+        {name: "inside tolerance", header: Sign(payload, secret, now.Add(-4*time.Minute))},
+        {name: "clock skew ahead", header: Sign(payload, secret, now.Add(4*time.Minute))},
+        {name: "rotated secret alongside a valid one", header: Sign(payload, "whsec_old", now) + ",v1=" + computeSignature(strconv.FormatInt(now.Unix(), 10), payload, secret)},
+        {name: "wrong secret", header: Sign(payload, "whsec_other", now), wantErr: true},
+        {name: "tampered payload", header: Sign(payload, secret, now), payload: []byte(`{"id":"evt_2"}`), wantErr: true},
+        {name: "older than tolerance", header: Sign(payload, secret, now.Add(-6*time.Minute)), wantErr: true},
+        {name: "too far in the future", header: Sign(payload, secret, now.Add(6*time.Minute)), wantErr: true},
+        {name: "missing header", header: "", wantErr: true},
+        {name: "no timestamp", header: "v1=" + computeSignature("", payload, secret), wantErr: true},
+        {name: "no signature", header: "t=" + strconv.FormatInt(now.Unix(), 10), wantErr: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            body := payload
+            if tt.payload != nil {
+                body = tt.payload
+            }
+            err := VerifySignature(body, tt.header, secret, now)
+            if tt.wantErr && err != ErrInvalidSignature {
+                t.Fatalf("VerifySignature = %v, want ErrInvalidSignature", err)
+            }
+            if !tt.wantErr && err != nil {
+                t.Fatalf("VerifySignature = %v, want nil", err)
+            }
+        })
+    }
+}
+
+func TestVerifySignatureNeedsSecret(t *testing.T) {
+    payload := []byte(`{}`)
+    now := time.Now()
+    if err := VerifySignature(payload, Sign(payload, "", now), "", now); err != ErrInvalidSignature {
+        t.Fatalf("VerifySignature with no secret = %v, want ErrInvalidSignature", err)
+    }
+}
+
+func TestToCents(t *testing.T) {
+    tests := map[float64]int64{0: 0, 1: 100, 10.01: 1001, 19.99: 1999, 0.005: 1, -2.5: -250}
+    for amount, want := range tests {
+        if got := ToCents(amount); got != want {
+            t.Errorf("ToCents(%v) = %d, want %d", amount, got, want)
+        }
+    }
+}
@@ -0,0 +1,191 @@
+package payments
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// DefaultStripeBaseURL is the production Stripe API endpoint.
+const DefaultStripeBaseURL = "https://api.stripe.com"
+
+// Stripe talks to the Stripe API, or anything that speaks the same protocol
+// such as FakeServer.
+type Stripe struct {
+    secretKey     string
+    webhookSecret string
+    baseURL       string
+    client        *http.Client
+    now           func() time.Time
+}
+
+// NewStripe builds a provider; an empty baseURL means DefaultStripeBaseURL.
+func NewStripe(secretKey, webhookSecret, baseURL string) *Stripe {
+    if baseURL == "" {
+        baseURL = DefaultStripeBaseURL
+    }
+    return &Stripe{
+        secretKey:     secretKey,
+        webhookSecret: webhookSecret,
+        baseURL:       strings.TrimRight(baseURL, "/"),
+        client:        &http.Client{Timeout: 15 * time.Second},
+        now:           time.Now,
+    }
+}
+
+func (s *Stripe) Name() string {
+    return "stripe"
+}
+
+type stripeIntent struct {
+    ID            string            `json:"id"`
+    Object        string            `json:"object"`
+    Amount        int64             `json:"amount"`
+    Currency      string            `json:"currency"`
+    Status        string            `json:"status"`
+    ClientSecret  string            `json:"client_secret"`
+    PaymentIntent string            `json:"payment_intent,omitempty"`
+    Metadata      map[string]string `json:"metadata"`
+}
+
+type stripeError struct {
+    Error struct {
+        Type    string `json:"type"`
+        Message string `json:"message"`
+    } `json:"error"`
+}
+
+func (s *Stripe) CreateIntent(ctx context.Context, req IntentRequest) (Intent, error) {
+    if req.AmountCents <= 0 {
+        return Intent{}, fmt.Errorf("amount must be positive")
+    }
+    currency := strings.ToLower(req.Currency)
+    if currency == "" {
+        currency = "usd"
+    }
+
+    form := url.Values{}
+    form.Set("amount", strconv.FormatInt(req.AmountCents, 10))
+    form.Set("currency", currency)
+    form.Set("automatic_payment_methods[enabled]", "true")
+    if req.Description != "" {
+        form.Set("description", req.Description)
+    }
+    for key, value := range req.Metadata {
+        form.Set("metadata["+key+"]", value)
+    }
+
+    var out stripeIntent
+    if err := s.post(ctx, "/v1/payment_intents", form, req.IdempotencyKey, &out); err != nil {
+        return Intent{}, err
+    }
+
+    return Intent{
+        ID:           out.ID,
+        ClientSecret: out.ClientSecret,
+        AmountCents:  out.Amount,
+        Currency:     out.Currency,
+    }, nil
+}
+
+func (s *Stripe) Refund(ctx context.Context, req RefundRequest) (Refund, error) {
+    if req.IntentID == "" {
+        return Refund{}, fmt.Errorf("payment intent required")
+    }
+
+    form := url.Values{}
+    form.Set("payment_intent", req.IntentID)
+    if req.AmountCents > 0 {
+        form.Set("amount", strconv.FormatInt(req.AmountCents, 10))
+    }
+    if req.Reason != "" {
+        form.Set("reason", "requested_by_customer")
+        form.Set("metadata[reason]", req.Reason)
+    }
+
+    var out struct {
+        ID            string `json:"id"`
+        PaymentIntent string `json:"payment_intent"`
+        Status        string `json:"status"`
+    }
+    if err := s.post(ctx, "/v1/refunds", form, req.IdempotencyKey, &out); err != nil {
+        return Refund{}, err
+    }
+
+    return Refund{ID: out.ID, IntentID: out.PaymentIntent, Status: out.Status}, nil
+}
+
+func (s *Stripe) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out interface{}) error {
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+path, strings.NewReader(form.Encode()))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Authorization", "Bearer "+s.secretKey)
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    if idempotencyKey != "" {
+        req.Header.Set("Idempotency-Key", idempotencyKey)
+    }
+
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return fmt.Errorf("stripe request failed: %w", err)
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+    if err != nil {
+        return err
+    }
+
+    if resp.StatusCode >= 300 {
+        var apiErr stripeError
+        if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+            return fmt.Errorf("stripe %s: %s", apiErr.Error.Type, apiErr.Error.Message)
+        }
+        return fmt.Errorf("stripe returned %s", resp.Status)
+    }
+
+    return json.Unmarshal(body, out)
+}
+
+func (s *Stripe) ParseWebhook(payload []byte, signatureHeader string) (Event, error) {
+    if err := VerifySignature(payload, signatureHeader, s.webhookSecret, s.now()); err != nil {
+        return Event{}, err
+    }
+
+    var evt struct {
+        ID   string `json:"id"`
+        Type string `json:"type"`
+        Data struct {
+            Object stripeIntent `json:"object"`
+        } `json:"data"`
+    }
+    if err := json.Unmarshal(payload, &evt); err != nil {
+        return Event{}, fmt.Errorf("decode webhook: %w", err)
+    }
+
+    out := Event{ID: evt.ID, Type: evt.Type, IntentID: evt.Data.Object.ID}
+    switch evt.Type {
+    case "payment_intent.succeeded":
+        out.Status = StatusConfirmed
+    case "payment_intent.payment_failed", "payment_intent.canceled":
+        out.Status = StatusFailed
+    case "charge.refunded":
+        // Refund events describe the charge; the intent is referenced from it.
+        out.IntentID = evt.Data.Object.PaymentIntent
+        out.Status = StatusRefunded
+    default:
+        return out, ErrUnhandledEvent
+    }
+
+    if out.IntentID == "" {
+        return out, fmt.Errorf("webhook %s missing payment intent", evt.ID)
+    }
+    return out, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "sync"
+    "time"
+)
+
+type payout struct {
+    ID         string    `json:"id"`
+    Amount     float64   `json:"amount"`
+    Recipient  string    `json:"recipient"`
+    Reason     string    `json:"reason"`
+    ApprovedBy []string  `json:"approvedBy"`
+    PaidAt     time.Time `json:"paidAt"`
+    CreatedAt  time.Time `json:"createdAt"`
+}
+
+type payoutStore struct {
+    path    string
+    mu      sync.Mutex
+    payouts []payout
+}
+
+func newPayoutStore(path string) (*payoutStore, error) {
+    store := &payoutStore{path: path}
+    if err := store.load(); err != nil {
+        return nil, err
+    }
+    return store, nil
+}
+
+func (s *payoutStore) load() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+        s.payouts = []payout{}
+        return nil
+    }
+
+    data, err := os.ReadFile(s.path)
+    if err != nil {
+        return err
+    }
+
+    if len(data) == 0 {
+        s.payouts = []payout{}
+        return nil
+    }
+
+    return json.Unmarshal(data, &s.payouts)
+}
+
+func (s *payoutStore) add(entry payout) (payout, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    entry.ID = fmt.Sprintf("payout-%d", time.Now().UnixNano())
+    entry.CreatedAt = time.Now().UTC()
+    if entry.PaidAt.IsZero() {
+        entry.PaidAt = entry.CreatedAt
+    }
+    s.payouts = append([]payout{entry}, s.payouts...)
+
+    data, err := json.MarshalIndent(s.payouts, "", "  ")
+    if err != nil {
+        return payout{}, err
+    }
+
+    if err := writeStoreFile("payouts", s.path, data); err != nil {
+        return payout{}, err
+    }
+
+    return entry, nil
+}
+
+func (s *payoutStore) list() []payout {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    out := make([]payout, len(s.payouts))
+    copy(out, s.payouts)
+    return out
+}
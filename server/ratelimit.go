@@ -0,0 +1,99 @@
+package main
+
+import (
+    "net"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+)
+
+// rateLimiter is a per-client token bucket over the /api/ routes. Payment
+// webhooks are exempt since the provider retries in bursts.
+type rateLimiter struct {
+    perMinute int
+    burst     int
+
+    mu      sync.Mutex
+    clients map[string]*bucket
+}
+
+type bucket struct {
+    tokens float64
+    last   time.Time
+}
+
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+    if burst < 1 {
+        burst = 1
+    }
+    limiter := &rateLimiter{perMinute: perMinute, burst: burst, clients: make(map[string]*bucket)}
+    go limiter.sweep()
+    return limiter
+}
+
+func (l *rateLimiter) allow(client string, now time.Time) bool {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    b, ok := l.clients[client]
+    if !ok {
+        b = &bucket{tokens: float64(l.burst), last: now}
+        l.clients[client] = b
+    }
+
+    b.tokens += now.Sub(b.last).Minutes() * float64(l.perMinute)
+    if b.tokens > float64(l.burst) {
+        b.tokens = float64(l.burst)
+    }
+    b.last = now
+
+    if b.tokens < 1 {
+        return false
+    }
+    b.tokens--
+    return true
+}
+
+// sweep forgets clients whose buckets have refilled.
+func (l *rateLimiter) sweep() {
+    for range time.Tick(time.Minute) {
+        l.mu.Lock()
+        full := time.Duration(float64(l.burst) / float64(l.perMinute) * float64(time.Minute))
+        for client, b := range l.clients {
+            if time.Since(b.last) > full {
+                delete(l.clients, client)
+            }
+        }
+        l.mu.Unlock()
+    }
+}
+
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !strings.HasPrefix(r.URL.Path, "/api/") || strings.HasSuffix(r.URL.Path, "/signal-bank/webhook") {
+            next.ServeHTTP(w, r)
+            return
+        }
+        if !l.allow(clientIP(r), time.Now()) {
+            w.Header().Set("Retry-After", "60")
+            writeError(w, http.StatusTooManyRequests, "rate_limited", "too many requests, try again shortly")
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// clientIP prefers the address our proxy appended to X-Forwarded-For, as
+// on Render every request arrives from the proxy itself.
+func clientIP(r *http.Request) string {
+    if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+        parts := strings.Split(forwarded, ",")
+        return strings.TrimSpace(parts[len(parts)-1])
+    }
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        return r.RemoteAddr
+    }
+    return host
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
+    "time"
+
+    "digital-oracle-server/payments"
+)
+
+type refundStatus string
+
+const (
+    refundRequested  refundStatus = "requested"
+    refundProcessing refundStatus = "processing"
+    refundCompleted  refundStatus = "completed"
+    refundRejected   refundStatus = "rejected"
+)
+
+type refundRequest struct {
+    Status      refundStatus `json:"status"`
+    Reason      string       `json:"reason,omitempty"`
+    RequestedAt time.Time    `json:"requestedAt"`
+    ProcessedAt *time.Time   `json:"processedAt,omitempty"`
+    RefundID    string       `json:"refundId,omitempty"`
+    Note        string       `json:"note,omitempty"`
+}
+
+type ledgerBalance struct {
+    Confirmed float64 `json:"confirmed"`
+    Reserved  float64 `json:"reserved"`
+    Available float64 `json:"available"`
+    Refunded  float64 `json:"refunded"`
+}
+
+// newClaimToken returns a token for the contributor and the hash we keep.
+func newClaimToken() (string, string, error) {
+    buf := make([]byte, 24)
+    if _, err := rand.Read(buf); err != nil {
+        return "", "", err
+    }
+    token := hex.EncodeToString(buf)
+    return token, hashClaimToken(token), nil
+}
+
+func hashClaimToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
+
+// isReserved reports whether the contribution still counts as withdrawable
+// funds: inside its cooling-off period, or with a refund not yet resolved.
+func (c contribution) isReserved(now time.Time) bool {
+    if c.Status != payments.StatusConfirmed {
+        return false
+    }
+    if c.Refund != nil && (c.Refund.Status == refundRequested || c.Refund.Status == refundProcessing) {
+        return true
+    }
+    return c.ReservedUntil != nil && now.Before(*c.ReservedUntil)
+}
+
+func (s *contributionStore) balance(now time.Time) ledgerBalance {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    var out ledgerBalance
+    for _, entry := range s.contributions {
+        switch entry.Status {
+        case payments.StatusConfirmed:
+            out.Confirmed += entry.Amount
+            if entry.isReserved(now) {
+                out.Reserved += entry.Amount
+            }
+        case payments.StatusRefunded:
+            out.Refunded += entry.Amount
+        }
+    }
+    out.Available = out.Confirmed - out.Reserved
+    return out
+}
+
+// requestRefund records a contributor's withdrawal request, authenticated by
+// the claim token handed out when the contribution was made.
+func (s *contributionStore) requestRefund(claimToken, reason string, now time.Time) (contribution, error) {
+    hash := hashClaimToken(claimToken)
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for i := range s.contributions {
+        entry := &s.contributions[i]
+        if entry.ClaimHash == "" || entry.ClaimHash != hash {
+            continue
+        }
+
+        if entry.Status != payments.StatusConfirmed {
+            return contribution{}, ErrNotRefundable
+        }
+        if entry.Refund != nil && entry.Refund.Status != refundRejected {
+            return contribution{}, ErrRefundExists
+        }
+        if entry.ReservedUntil == nil || !now.Before(*entry.ReservedUntil) {
+            return contribution{}, ErrRefundWindowClosed
+        }
+
+        entry.Refund = &refundRequest{
+            Status:      refundRequested,
+            Reason:      reason,
+            RequestedAt: now.UTC(),
+        }
+        entry.UpdatedAt = now.UTC()
+
+        if err := s.saveLocked(); err != nil {
+            return contribution{}, err
+        }
+        return *entry, nil
+    }
+    return contribution{}, ErrInvalidClaimToken
+}
+
+func (s *contributionStore) refundRequests() []contribution {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    out := make([]contribution, 0)
+    for _, entry := range s.contributions {
+        if entry.Refund != nil {
+            out = append(out, entry)
+        }
+    }
+    return out
+}
+
+// resolveRefund moves a requested refund to processing (approve) or rejected.
+func (s *contributionStore) resolveRefund(id string, approve bool, note string) (contribution, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for i := range s.contributions {
+        entry := &s.contributions[i]
+        if entry.ID != id {
+            continue
+        }
+
+        if entry.Refund == nil || entry.Refund.Status != refundRequested {
+            return contribution{}, ErrNoRefundRequested
+        }
+
+        now := time.Now().UTC()
+        entry.Refund.Status = refundRejected
+        if approve {
+            entry.Refund.Status = refundProcessing
+        }
+        entry.Refund.Note = note
+        entry.Refund.ProcessedAt = &now
+        entry.UpdatedAt = now
+
+        if err := s.saveLocked(); err != nil {
+            return contribution{}, err
+        }
+        return *entry, nil
+    }
+    return contribution{}, ErrContributionNotFound
+}
+
+// recordRefund stores the provider's refund id, or puts the request back in
+// the queue when the provider refused it.
+func (s *contributionStore) recordRefund(id string, refund payments.Refund, providerErr error) (contribution, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for i := range s.contributions {
+        entry := &s.contributions[i]
+        if entry.ID != id {
+            continue
+        }
+        if entry.Refund == nil {
+            return contribution{}, ErrNoRefundRequested
+        }
+
+        if providerErr != nil {
+            entry.Refund.Status = refundRequested
+            entry.Refund.ProcessedAt = nil
+            entry.Refund.Note = "provider error: " + providerErr.Error()
+        } else {
+            entry.Refund.RefundID = refund.ID
+        }
+        entry.UpdatedAt = time.Now().UTC()
+
+        if err := s.saveLocked(); err != nil {
+            return contribution{}, err
+        }
+        return *entry, nil
+    }
+    return contribution{}, ErrContributionNotFound
+}
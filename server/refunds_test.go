@@ -0,0 +1,214 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "digital-oracle-server/payments"
+)
+
+// confirmedContribution creates a contribution and settles it through the
+// fake provider, returning it with its claim token.
+func confirmedContribution(t *testing.T, app *api, mux http.Handler, fakeURL string, amount float64) (contribution, string) {
+    t.Helper()
+    entry, token := newPendingContribution(t, mux, amount)
+    settlePayment(t, fakeURL, entry.PaymentID, "confirm")
+    for _, c := range app.bank.listByStatus(payments.StatusConfirmed) {
+        if c.ID == entry.ID {
+            return c, token
+        }
+    }
+    t.Fatalf("contribution %s was not confirmed", entry.ID)
+    return contribution{}, ""
+}
+
+func TestClaimHashStaysOnTheServer(t *testing.T) {
+    app, mux, fakeURL := newTestAPI(t)
+
+    var created map[string]interface{}
+    rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/contributions", map[string]interface{}{"name": "Ada", "amount": 5, "message": "For the finals"}, &created)
+    if rec.Code != http.StatusCreated {
+        t.Fatalf("create contribution returned %d: %s", rec.Code, rec.Body.String())
+    }
+    if _, ok := created["claimHash"]; ok {
+        t.Fatalf("response %s leaks the claim hash", rec.Body.String())
+    }
+    token, _ := created["claimToken"].(string)
+    id, _ := created["id"].(string)
+    paymentID, _ := created["paymentId"].(string)
+    if token == "" {
+        t.Fatalf("response %s has no claim token", rec.Body.String())
+    }
+    settlePayment(t, fakeURL, paymentID, "confirm")
+
+    admin := "?token=" + testAdminToken
+    for _, req := range []struct {
+        method, path string
+        body         interface{}
+    }{
+        {http.MethodPost, "/api/v1/signal-bank/refund-requests", map[string]string{"claimToken": token}},
+        {http.MethodGet, "/api/v1/signal-bank/contributions", nil},
+        {http.MethodGet, "/api/v1/signal-bank/admin/contributions" + admin, nil},
+        {http.MethodGet, "/api/v1/signal-bank/admin/refunds" + admin, nil},
+        {http.MethodPost, "/api/v1/signal-bank/admin/moderation" + admin, map[string]interface{}{"contributionId": id, "hidden": true}},
+        {http.MethodPost, "/api/v1/signal-bank/admin/refunds" + admin, map[string]string{"contributionId": id, "action": "reject"}},
+    } {
+        rec := do(t, mux, req.method, req.path, req.body, nil)
+        if rec.Code >= 300 {
+            t.Fatalf("%s %s returned %d: %s", req.method, req.path, rec.Code, rec.Body.String())
+        }
+        if strings.Contains(rec.Body.String(), "claimHash") || strings.Contains(rec.Body.String(), hashClaimToken(token)) {
+            t.Errorf("%s %s leaks the claim hash: %s", req.method, req.path, rec.Body.String())
+        }
+    }
+
+    // The hash is still written to disk, so the token works after a restart.
+    reopened, err := newContributionStore(app.bank.path, 72*time.Hour)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := reopened.requestRefund(token, "", time.Now()); err != nil {
+        t.Fatalf("requestRefund after reloading the store: %v", err)
+    }
+}
+
+func TestRequestRefund(t *testing.T) {
+    app, mux, fakeURL := newTestAPI(t)
+    _, token := confirmedContribution(t, app, mux, fakeURL, 20)
+    _, pendingToken := newPendingContribution(t, mux, 5)
+
+    tests := []struct {
+        name       string
+        token      string
+        wantStatus int
+        wantCode   string
+    }{
+        {"first request", token, http.StatusAccepted, ""},
+        {"second request", token, http.StatusConflict, "refund_exists"},
+        {"unknown token", "not-a-token", http.StatusNotFound, "invalid_claim_token"},
+        {"unpaid contribution", pendingToken, http.StatusUnprocessableEntity, "not_refundable"},
+    }
+    for _, tt := range tests {
+        rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/refund-requests", map[string]string{"claimToken": tt.token, "reason": "changed my mind"}, nil)
+        if rec.Code != tt.wantStatus {
+            t.Errorf("%s: got %d %s, want %d", tt.name, rec.Code, rec.Body.String(), tt.wantStatus)
+            continue
+        }
+        if tt.wantCode != "" && errorCode(t, rec) != tt.wantCode {
+            t.Errorf("%s: got code %s, want %s", tt.name, errorCode(t, rec), tt.wantCode)
+        }
+    }
+}
+
+func TestRefundWindowAndReservation(t *testing.T) {
+    app, mux, fakeURL := newTestAPI(t)
+    entry, token := confirmedContribution(t, app, mux, fakeURL, 30)
+    _, otherToken := confirmedContribution(t, app, mux, fakeURL, 10)
+
+    inside := entry.ConfirmedAt.Add(time.Hour)
+    after := entry.ReservedUntil.Add(time.Minute)
+
+    if got := app.bank.balance(inside); got.Confirmed != 40 || got.Reserved != 40 || got.Available != 0 {
+        t.Fatalf("balance inside the window = %+v, want all 40 reserved", got)
+    }
+    if got := app.bank.balance(after); got.Reserved != 0 || got.Available != 40 {
+        t.Fatalf("balance after the window = %+v, want all 40 available", got)
+    }
+
+    if _, err := app.bank.requestRefund(otherToken, "", after); err != ErrRefundWindowClosed {
+        t.Fatalf("requestRefund after the window = %v, want ErrRefundWindowClosed", err)
+    }
+    if _, err := app.bank.requestRefund(token, "", inside); err != nil {
+        t.Fatalf("requestRefund inside the window: %v", err)
+    }
+    // An open request holds the money even once the window has passed.
+    if got := app.bank.balance(after); got.Reserved != 30 || got.Available != 10 {
+        t.Fatalf("balance with an open request = %+v, want 30 reserved and 10 available", got)
+    }
+}
+
+func TestResolveRefund(t *testing.T) {
+    app, mux, fakeURL := newTestAPI(t)
+    approved, approvedToken := confirmedContribution(t, app, mux, fakeURL, 25)
+    rejected, rejectedToken := confirmedContribution(t, app, mux, fakeURL, 15)
+
+    for _, token := range []string{approvedToken, rejectedToken} {
+        if rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/refund-requests", map[string]string{"claimToken": token}, nil); rec.Code != http.StatusAccepted {
+            t.Fatalf("refund request returned %d: %s", rec.Code, rec.Body.String())
+        }
+    }
+
+    resolve := func(id, action string) *httptest.ResponseRecorder {
+        return do(t, mux, http.MethodPost, "/api/v1/signal-bank/admin/refunds?token="+testAdminToken, map[string]string{"contributionId": id, "action": action}, nil)
+    }
+
+    if rec := resolve(approved.ID, "approve"); rec.Code != http.StatusOK {
+        t.Fatalf("approve returned %d: %s", rec.Code, rec.Body.String())
+    }
+    // The fake provider delivers charge.refunded before answering.
+    refunded := app.bank.listByStatus(payments.StatusRefunded)
+    if len(refunded) != 1 || refunded[0].ID != approved.ID || refunded[0].Refund.Status != refundCompleted || refunded[0].Refund.RefundID == "" {
+        t.Fatalf("after approval: %+v, want the contribution refunded with the provider's id", refunded)
+    }
+    if rec := resolve(approved.ID, "approve"); rec.Code != http.StatusConflict || errorCode(t, rec) != "no_refund_requested" {
+        t.Fatalf("second approval returned %d %s, want 409 no_refund_requested", rec.Code, rec.Body.String())
+    }
+
+    rec := resolve(rejected.ID, "reject")
+    var entry contribution
+    if err := json.Unmarshal(rec.Body.Bytes(), &entry); err != nil || rec.Code != http.StatusOK || entry.Refund.Status != refundRejected {
+        t.Fatalf("reject returned %d %s", rec.Code, rec.Body.String())
+    }
+    if rec := resolve(rejected.ID, "approve"); rec.Code != http.StatusConflict {
+        t.Fatalf("approving a rejected request returned %d, want 409", rec.Code)
+    }
+    // A rejected request can be made again while the window is open.
+    if rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/refund-requests", map[string]string{"claimToken": rejectedToken}, nil); rec.Code != http.StatusAccepted {
+        t.Fatalf("request after rejection returned %d: %s", rec.Code, rec.Body.String())
+    }
+}
+
+func TestRefundProviderFailure(t *testing.T) {
+    app, mux, fakeURL := newTestAPI(t)
+    entry, token := confirmedContribution(t, app, mux, fakeURL, 25)
+    if rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/refund-requests", map[string]string{"claimToken": token}, nil); rec.Code != http.StatusAccepted {
+        t.Fatalf("refund request returned %d: %s", rec.Code, rec.Body.String())
+    }
+
+    down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusInternalServerError)
+        w.Write([]byte(`{"error": {"type": "api_error", "message": "try again later"}}`))
+    }))
+    defer down.Close()
+    working := app.provider
+    app.provider = payments.NewStripe("sk_test", testWebhookSecret, down.URL)
+
+    approve := func() *httptest.ResponseRecorder {
+        return do(t, mux, http.MethodPost, "/api/v1/signal-bank/admin/refunds?token="+testAdminToken, map[string]string{"contributionId": entry.ID, "action": "approve"}, nil)
+    }
+    if rec := approve(); rec.Code != http.StatusBadGateway || errorCode(t, rec) != "payment_provider_error" {
+        t.Fatalf("approve with the provider down returned %d %s, want 502 payment_provider_error", rec.Code, rec.Body.String())
+    }
+
+    queued := app.bank.refundRequests()
+    if len(queued) != 1 || queued[0].Refund.Status != refundRequested || queued[0].Refund.ProcessedAt != nil || !strings.HasPrefix(queued[0].Refund.Note, "provider error: ") {
+        t.Fatalf("after the provider refused: %+v, want the request back in the queue, unprocessed, with the error noted", queued[0].Refund)
+    }
+    if got := app.bank.balance(time.Now().Add(96 * time.Hour)); got.Reserved != 25 {
+        t.Fatalf("balance after the provider refused = %+v, want the 25 still reserved", got)
+    }
+
+    app.provider = working
+    if rec := approve(); rec.Code != http.StatusOK {
+        t.Fatalf("approve once the provider is back returned %d: %s", rec.Code, rec.Body.String())
+    }
+    refunded := app.bank.listByStatus(payments.StatusRefunded)
+    if len(refunded) != 1 || refunded[0].Refund.ProcessedAt == nil {
+        t.Fatalf("after the retry: %+v, want the contribution refunded with a processed time", refunded)
+    }
+}
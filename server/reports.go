@@ -0,0 +1,509 @@
+package main
+
+import (
+    "bytes"
+    "crypto/ed25519"
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "html/template"
+    "log/slog"
+    "math"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/atomicfile"
+
+    "digital-oracle-server/payments"
+)
+
+const (
+    periodWeekly  = "weekly"
+    periodMonthly = "monthly"
+
+    topContributorCount = 5
+)
+
+type dailyTotal struct {
+    Date  string  `json:"date"`
+    Count int     `json:"count"`
+    Total float64 `json:"total"`
+}
+
+type reportRefund struct {
+    ContributionID string    `json:"contributionId"`
+    Amount         float64   `json:"amount"`
+    RefundedAt     time.Time `json:"refundedAt"`
+}
+
+type reportContributor struct {
+    Rank  int     `json:"rank"`
+    Alias string  `json:"alias"`
+    Count int     `json:"count"`
+    Total float64 `json:"total"`
+}
+
+type transparencyReport struct {
+    Period             string              `json:"period"`
+    Start              time.Time           `json:"start"`
+    End                time.Time           `json:"end"`
+    GeneratedAt        time.Time           `json:"generatedAt"`
+    Currency           string              `json:"currency"`
+    OpeningBalance     float64             `json:"openingBalance"`
+    ContributionsByDay []dailyTotal        `json:"contributionsByDay"`
+    ContributionTotal  float64             `json:"contributionTotal"`
+    Refunds            []reportRefund      `json:"refunds"`
+    RefundTotal        float64             `json:"refundTotal"`
+    Payouts            []payout            `json:"payouts"`
+    PayoutTotal        float64             `json:"payoutTotal"`
+    ClosingBalance     float64             `json:"closingBalance"`
+    TopContributors    []reportContributor `json:"topContributors"`
+    PublicKey          string              `json:"publicKey"`
+}
+
+type publishedReport struct {
+    Name  string            `json:"name"`
+    Files map[string]string `json:"files"`
+}
+
+type reportPublisher struct {
+    dir     string
+    key     ed25519.PrivateKey
+    bank    *contributionStore
+    payouts *payoutStore
+
+    mu     sync.Mutex
+    closed bool
+}
+
+// loadSigningKey returns the report signing key from a base64 seed, falling
+// back to a key file that is created on first use.
+func loadSigningKey(seed, path string) (ed25519.PrivateKey, error) {
+    if seed == "" {
+        data, err := os.ReadFile(path)
+        switch {
+        case err == nil:
+            seed = strings.TrimSpace(string(data))
+        case errors.Is(err, os.ErrNotExist):
+            raw := make([]byte, ed25519.SeedSize)
+            if _, err := rand.Read(raw); err != nil {
+                return nil, err
+            }
+            seed = base64.StdEncoding.EncodeToString(raw)
+            if err := atomicfile.WriteFile(path, []byte(seed+"\n"), 0o600); err != nil {
+                return nil, err
+            }
+        default:
+            return nil, err
+        }
+    }
+
+    raw, err := base64.StdEncoding.DecodeString(seed)
+    if err != nil || len(raw) != ed25519.SeedSize {
+        return nil, fmt.Errorf("signing key must be a base64 encoded %d byte seed", ed25519.SeedSize)
+    }
+    return ed25519.NewKeyFromSeed(raw), nil
+}
+
+func (p *reportPublisher) publicKey() string {
+    return base64.StdEncoding.EncodeToString(p.key.Public().(ed25519.PublicKey))
+}
+
+// periodBounds returns the UTC week (starting Monday) or month containing ref.
+func periodBounds(kind string, ref time.Time) (time.Time, time.Time, error) {
+    ref = ref.UTC()
+    day := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, time.UTC)
+
+    switch kind {
+    case periodWeekly:
+        offset := (int(day.Weekday()) + 6) % 7
+        start := day.AddDate(0, 0, -offset)
+        return start, start.AddDate(0, 0, 7), nil
+    case periodMonthly:
+        start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+        return start, start.AddDate(0, 1, 0), nil
+    }
+    return time.Time{}, time.Time{}, fmt.Errorf("period must be %s or %s", periodWeekly, periodMonthly)
+}
+
+func buildReport(kind string, start, end time.Time, contributions []contribution, payouts []payout) transparencyReport {
+    report := transparencyReport{
+        Period:             kind,
+        Start:              start,
+        End:                end,
+        GeneratedAt:        time.Now().UTC(),
+        Currency:           "USD",
+        ContributionsByDay: []dailyTotal{},
+        Refunds:            []reportRefund{},
+        Payouts:            []payout{},
+        TopContributors:    []reportContributor{},
+    }
+
+    inPeriod := func(t time.Time) bool {
+        return !t.Before(start) && t.Before(end)
+    }
+
+    days := make(map[string]*dailyTotal)
+    type donor struct {
+        alias string
+        count int
+        total float64
+    }
+    donors := make(map[string]*donor)
+
+    for i, entry := range contributions {
+        if entry.ConfirmedAt == nil {
+            continue
+        }
+        confirmedAt := *entry.ConfirmedAt
+
+        if confirmedAt.Before(start) {
+            report.OpeningBalance += entry.Amount
+        } else if inPeriod(confirmedAt) {
+            key := confirmedAt.UTC().Format("2006-01-02")
+            if days[key] == nil {
+                days[key] = &dailyTotal{Date: key}
+            }
+            days[key].Count++
+            days[key].Total += entry.Amount
+            report.ContributionTotal += entry.Amount
+
+            donorKey := strings.ToLower(strings.TrimSpace(entry.Name))
+            if donorKey == "" || entry.Visibility == visibilityAnonymous {
+                donorKey = fmt.Sprintf("anonymous-%d", i)
+            }
+            if donors[donorKey] == nil {
+                alias := anonymizeName(entry.Name)
+                if entry.Visibility == visibilityAnonymous {
+                    alias = "Anonymous"
+                }
+                donors[donorKey] = &donor{alias: alias}
+            }
+            donors[donorKey].count++
+            donors[donorKey].total += entry.Amount
+        }
+
+        if entry.Status != payments.StatusRefunded || entry.Refund == nil || entry.Refund.ProcessedAt == nil {
+            continue
+        }
+        refundedAt := *entry.Refund.ProcessedAt
+        if refundedAt.Before(start) {
+            report.OpeningBalance -= entry.Amount
+        } else if inPeriod(refundedAt) {
+            report.Refunds = append(report.Refunds, reportRefund{
+                ContributionID: entry.ID,
+                Amount:         entry.Amount,
+                RefundedAt:     refundedAt,
+            })
+            report.RefundTotal += entry.Amount
+        }
+    }
+
+    for _, p := range payouts {
+        if p.PaidAt.Before(start) {
+            report.OpeningBalance -= p.Amount
+        } else if inPeriod(p.PaidAt) {
+            report.Payouts = append(report.Payouts, p)
+            report.PayoutTotal += p.Amount
+        }
+    }
+
+    for _, d := range days {
+        d.Total = roundCents(d.Total)
+        report.ContributionsByDay = append(report.ContributionsByDay, *d)
+    }
+    sort.Slice(report.ContributionsByDay, func(i, j int) bool {
+        return report.ContributionsByDay[i].Date < report.ContributionsByDay[j].Date
+    })
+    sort.Slice(report.Refunds, func(i, j int) bool {
+        return report.Refunds[i].RefundedAt.Before(report.Refunds[j].RefundedAt)
+    })
+    sort.Slice(report.Payouts, func(i, j int) bool {
+        return report.Payouts[i].PaidAt.Before(report.Payouts[j].PaidAt)
+    })
+
+    ranked := make([]*donor, 0, len(donors))
+    for _, d := range donors {
+        ranked = append(ranked, d)
+    }
+    sort.Slice(ranked, func(i, j int) bool {
+        if ranked[i].total != ranked[j].total {
+            return ranked[i].total > ranked[j].total
+        }
+        return ranked[i].alias < ranked[j].alias
+    })
+    for i, d := range ranked {
+        if i == topContributorCount {
+            break
+        }
+        report.TopContributors = append(report.TopContributors, reportContributor{
+            Rank:  i + 1,
+            Alias: d.alias,
+            Count: d.count,
+            Total: roundCents(d.total),
+        })
+    }
+
+    report.OpeningBalance = roundCents(report.OpeningBalance)
+    report.ContributionTotal = roundCents(report.ContributionTotal)
+    report.RefundTotal = roundCents(report.RefundTotal)
+    report.PayoutTotal = roundCents(report.PayoutTotal)
+    report.ClosingBalance = roundCents(report.OpeningBalance + report.ContributionTotal - report.RefundTotal - report.PayoutTotal)
+    return report
+}
+
+// anonymizeName reduces a contributor name to initials, e.g. "Ada Lovelace" -> "A.L.".
+func anonymizeName(name string) string {
+    var b strings.Builder
+    for _, word := range strings.Fields(name) {
+        r := []rune(word)
+        b.WriteString(strings.ToUpper(string(r[0])))
+        b.WriteString(".")
+    }
+    if b.Len() == 0 {
+        return "Anonymous"
+    }
+    return b.String()
+}
+
+func roundCents(amount float64) float64 {
+    return math.Round(amount*100) / 100
+}
+
+func renderReportCSV(report transparencyReport) ([]byte, error) {
+    var buf bytes.Buffer
+    w := csv.NewWriter(&buf)
+    money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+    rows := [][]string{
+        {"section", "date", "label", "count", "amount", "detail"},
+        {"opening_balance", report.Start.Format("2006-01-02"), "", "", money(report.OpeningBalance), ""},
+    }
+    for _, d := range report.ContributionsByDay {
+        rows = append(rows, []string{"contributions", d.Date, "", strconv.Itoa(d.Count), money(d.Total), ""})
+    }
+    for _, r := range report.Refunds {
+        rows = append(rows, []string{"refund", r.RefundedAt.Format("2006-01-02"), r.ContributionID, "", money(r.Amount), ""})
+    }
+    for _, p := range report.Payouts {
+        detail := p.Reason + " (approved by " + strings.Join(p.ApprovedBy, ", ") + ")"
+        rows = append(rows, []string{"payout", p.PaidAt.Format("2006-01-02"), p.Recipient, "", money(p.Amount), detail})
+    }
+    rows = append(rows, []string{"closing_balance", report.End.AddDate(0, 0, -1).Format("2006-01-02"), "", "", money(report.ClosingBalance), ""})
+    for _, c := range report.TopContributors {
+        rows = append(rows, []string{"top_contributor", "", c.Alias, strconv.Itoa(c.Count), money(c.Total), "rank " + strconv.Itoa(c.Rank)})
+    }
+
+    if err := w.WriteAll(rows); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+    "money": func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+    "day":   func(t time.Time) string { return t.Format("Jan 2, 2006") },
+    "join":  strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>Signal Bank {{.Report.Period}} report · {{day .Report.Start}}</title>
+    <style>{{.Style}}</style>
+</head>
+<body>
+    <main>
+        <section class="card">
+            <h1>Signal Bank Transparency Report</h1>
+            <p>{{.Report.Period}} period {{day .Report.Start}} – {{day .LastDay}} (UTC). Generated {{.Report.GeneratedAt.Format "2006-01-02 15:04 MST"}}.</p>
+            <div class="ledger-controls"><span>Opening balance</span><strong>{{money .Report.OpeningBalance}}</strong></div>
+
+            <h2>Contributions by day</h2>
+            {{if .Report.ContributionsByDay}}<table>
+                <tr><th>Date</th><th>Count</th><th>Total</th></tr>
+                {{range .Report.ContributionsByDay}}<tr><td>{{.Date}}</td><td>{{.Count}}</td><td>{{money .Total}}</td></tr>
+                {{end}}
+            </table>{{else}}<p>No confirmed contributions this period.</p>{{end}}
+
+            {{if .Report.Refunds}}<h2>Refunds</h2>
+            <table>
+                <tr><th>Date</th><th>Contribution</th><th>Amount</th></tr>
+                {{range .Report.Refunds}}<tr><td>{{day .RefundedAt}}</td><td>{{.ContributionID}}</td><td>{{money .Amount}}</td></tr>
+                {{end}}
+            </table>{{end}}
+
+            <h2>Payouts</h2>
+            {{if .Report.Payouts}}<table>
+                <tr><th>Date</th><th>Recipient</th><th>Reason</th><th>Approved by</th><th>Amount</th></tr>
+                {{range .Report.Payouts}}<tr><td>{{day .PaidAt}}</td><td>{{.Recipient}}</td><td>{{.Reason}}</td><td>{{join .ApprovedBy ", "}}</td><td>{{money .Amount}}</td></tr>
+                {{end}}
+            </table>{{else}}<p>No payouts this period.</p>{{end}}
+
+            <div class="ledger-controls"><span>Closing balance</span><strong>{{money .Report.ClosingBalance}}</strong></div>
+
+            {{if .Report.TopContributors}}<h2>Top contributors</h2>
+            <ol>
+                {{range .Report.TopContributors}}<li>{{.Alias}} · {{.Count}} contribution(s) · {{money .Total}}</li>
+                {{end}}
+            </ol>{{end}}
+
+            <h2>Verify this report</h2>
+            <p class="meta">Each file is signed with Ed25519. Public key: <code>{{.Report.PublicKey}}</code></p>
+            <p class="meta"><a href="{{.Base}}.json">JSON</a> (<a href="{{.Base}}.json.sig">signature</a>) · <a href="{{.Base}}.csv">CSV</a> (<a href="{{.Base}}.csv.sig">signature</a>) · <a href="{{.Base}}.html.sig">signature for this page</a></p>
+        </section>
+    </main>
+</body>
+</html>
+`))
+
+// reportStyle is the site's stylesheets, inlined into each HTML report.
+// Reports are written to dataDir/reports and served from /reports/ for
+// good, while the site's asset URLs carry a content hash that changes
+// with every stylesheet edit, so a link would break. Inlining also puts
+// the styling under the page's signature.
+var reportStyle = func() template.CSS {
+    var css []byte
+    for _, name := range []string{"web/styles.css", "web/signal_bank.css"} {
+        data, err := embeddedWeb.ReadFile(name)
+        if err != nil {
+            panic(err)
+        }
+        css = append(css, data...)
+    }
+    return template.CSS(css)
+}()
+
+func renderReportHTML(report transparencyReport, base string) ([]byte, error) {
+    var buf bytes.Buffer
+    err := reportTemplate.Execute(&buf, struct {
+        Report  transparencyReport
+        Base    string
+        LastDay time.Time
+        Style   template.CSS
+    }{report, base, report.End.AddDate(0, 0, -1), reportStyle})
+    return buf.Bytes(), err
+}
+
+// publish writes the report for the period containing ref as JSON, CSV and
+// HTML, each with a detached base64 Ed25519 signature alongside it.
+func (p *reportPublisher) publish(kind string, ref time.Time) (publishedReport, error) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if p.closed {
+        return publishedReport{}, ErrReportsClosed
+    }
+
+    start, end, err := periodBounds(kind, ref)
+    if err != nil {
+        return publishedReport{}, err
+    }
+
+    report := buildReport(kind, start, end, p.bank.list(), p.payouts.list())
+    report.PublicKey = p.publicKey()
+
+    base := fmt.Sprintf("%s-%s", kind, start.Format("2006-01-02"))
+
+    jsonData, err := json.MarshalIndent(report, "", "  ")
+    if err != nil {
+        return publishedReport{}, err
+    }
+    csvData, err := renderReportCSV(report)
+    if err != nil {
+        return publishedReport{}, err
+    }
+    htmlData, err := renderReportHTML(report, base)
+    if err != nil {
+        return publishedReport{}, err
+    }
+
+    if err := os.MkdirAll(p.dir, 0o755); err != nil {
+        return publishedReport{}, err
+    }
+
+    published := publishedReport{Name: base, Files: make(map[string]string)}
+    for ext, data := range map[string][]byte{"json": jsonData, "csv": csvData, "html": htmlData} {
+        name := base + "." + ext
+        sig := base64.StdEncoding.EncodeToString(ed25519.Sign(p.key, data))
+        if err := atomicfile.WriteFile(filepath.Join(p.dir, name), data, 0o644); err != nil {
+            return publishedReport{}, err
+        }
+        if err := atomicfile.WriteFile(filepath.Join(p.dir, name+".sig"), []byte(sig+"\n"), 0o644); err != nil {
+            return publishedReport{}, err
+        }
+        published.Files[ext] = "/reports/" + name
+    }
+
+    if err := atomicfile.WriteFile(filepath.Join(p.dir, "public-key.txt"), []byte(p.publicKey()+"\n"), 0o644); err != nil {
+        return publishedReport{}, err
+    }
+    return published, nil
+}
+
+func (p *reportPublisher) list() ([]publishedReport, error) {
+    matches, err := filepath.Glob(filepath.Join(p.dir, "*.json"))
+    if err != nil {
+        return nil, err
+    }
+
+    out := make([]publishedReport, 0, len(matches))
+    for _, match := range matches {
+        base := strings.TrimSuffix(filepath.Base(match), ".json")
+        out = append(out, publishedReport{
+            Name: base,
+            Files: map[string]string{
+                "json": "/reports/" + base + ".json",
+                "csv":  "/reports/" + base + ".csv",
+                "html": "/reports/" + base + ".html",
+            },
+        })
+    }
+    sort.Slice(out, func(i, j int) bool { return out[i].Name > out[j].Name })
+    return out, nil
+}
+
+// publishMissing generates reports for the last completed week and month if
+// they have not been written yet.
+func (p *reportPublisher) publishMissing(now time.Time) {
+    for _, kind := range []string{periodWeekly, periodMonthly} {
+        current, _, _ := periodBounds(kind, now)
+        previous := current.Add(-time.Nanosecond)
+        start, _, _ := periodBounds(kind, previous)
+
+        name := fmt.Sprintf("%s-%s.json", kind, start.Format("2006-01-02"))
+        if _, err := os.Stat(filepath.Join(p.dir, name)); err == nil {
+            continue
+        }
+
+        if _, err := p.publish(kind, previous); err != nil {
+            slog.Error("failed to publish report", "period", kind, "err", err)
+            continue
+        }
+        slog.Info("published transparency report", "period", kind, "start", start.Format("2006-01-02"))
+    }
+}
+
+// close waits for a publish in progress so shutdown never leaves a report
+// without its signature; later publishes fail.
+func (p *reportPublisher) close() {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.closed = true
+}
+
+func (p *reportPublisher) run() {
+    p.publishMissing(time.Now())
+
+    ticker := time.NewTicker(time.Hour)
+    defer ticker.Stop()
+    for now := range ticker.C {
+        p.publishMissing(now)
+    }
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+    "bytes"
+    "crypto/ed25519"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "digital-oracle-server/payments"
+)
+
+func TestReportHTMLInlinesStyles(t *testing.T) {
+    start := time.Date(2026, time.October, 5, 0, 0, 0, 0, time.UTC)
+    page, err := renderReportHTML(transparencyReport{Period: periodWeekly, Start: start, End: start.AddDate(0, 0, 7)}, "weekly-2026-10-05")
+    if err != nil {
+        t.Fatal(err)
+    }
+    html := string(page)
+    if strings.Contains(html, "<link") || strings.Contains(html, ".css") {
+        t.Fatalf("report links a stylesheet, which breaks once asset URLs are hashed")
+    }
+    styles, err := embeddedWeb.ReadFile("web/signal_bank.css")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(html, strings.TrimSpace(string(styles))) {
+        t.Fatalf("report does not inline signal_bank.css")
+    }
+}
+
+func TestBuildReport(t *testing.T) {
+    start := time.Date(2026, time.October, 5, 0, 0, 0, 0, time.UTC)
+    end := start.AddDate(0, 0, 7)
+    on := func(month time.Month, day int) *time.Time {
+        at := time.Date(2026, month, day, 12, 0, 0, 0, time.UTC)
+        return &at
+    }
+    confirmed := func(name string, shown visibility, amount float64, at *time.Time) contribution {
+        return contribution{ID: fmt.Sprintf("%s-%v", at.Format("0102"), amount), Name: name, Visibility: shown, Amount: amount, Status: payments.StatusConfirmed, ConfirmedAt: at}
+    }
+    refunded := func(c contribution, at *time.Time) contribution {
+        c.Status = payments.StatusRefunded
+        c.Refund = &refundRequest{Status: refundCompleted, ProcessedAt: at}
+        return c
+    }
+    endOfPeriod := end
+
+    contributions := []contribution{
+        confirmed("Ada Lovelace", visibilityPublic, 100, on(time.September, 30)),
+        confirmed("Ada Lovelace", visibilityPublic, 50, on(time.October, 6)),
+        confirmed(" ada lovelace ", visibilityInitials, 25.5, on(time.October, 6)),
+        confirmed("Alan Turing", visibilityAnonymous, 200, on(time.October, 7)),
+        confirmed("Alan Turing", visibilityAnonymous, 10, on(time.October, 7)),
+        refunded(confirmed("Grace Hopper", visibilityInitials, 80, on(time.October, 1)), on(time.October, 8)),
+        refunded(confirmed("Bob Builder", visibilityPublic, 40, on(time.October, 2)), on(time.October, 3)),
+        refunded(confirmed("Carol", visibilityPublic, 30, on(time.October, 9)), on(time.October, 13)),
+        confirmed("Dan", visibilityPublic, 70, &endOfPeriod),
+        {ID: "pending", Name: "Eve", Amount: 999, Status: payments.StatusPending},
+    }
+    payouts := []payout{
+        {ID: "p1", Amount: 20, PaidAt: *on(time.October, 1)},
+        {ID: "p2", Amount: 60, PaidAt: *on(time.October, 10)},
+        {ID: "p3", Amount: 15, PaidAt: *on(time.October, 12)},
+    }
+
+    report := buildReport(periodWeekly, start, end, contributions, payouts)
+
+    // Before the period: 100 + 80 + 40 confirmed, 40 refunded, 20 paid out.
+    if report.OpeningBalance != 160 {
+        t.Errorf("opening balance %v, want 160", report.OpeningBalance)
+    }
+    if report.ContributionTotal != 315.5 || report.RefundTotal != 80 || report.PayoutTotal != 60 {
+        t.Errorf("contributions %v, refunds %v, payouts %v; want 315.5, 80 and 60", report.ContributionTotal, report.RefundTotal, report.PayoutTotal)
+    }
+    if report.ClosingBalance != 335.5 {
+        t.Errorf("closing balance %v, want 335.5", report.ClosingBalance)
+    }
+
+    wantDays := []dailyTotal{{"2026-10-06", 2, 75.5}, {"2026-10-07", 2, 210}, {"2026-10-09", 1, 30}}
+    if !reflect.DeepEqual(report.ContributionsByDay, wantDays) {
+        t.Errorf("contributions by day %+v, want %+v", report.ContributionsByDay, wantDays)
+    }
+    if len(report.Refunds) != 1 || report.Refunds[0].ContributionID != "1001-80" {
+        t.Errorf("refunds %+v, want only Grace Hopper's, refunded inside the period", report.Refunds)
+    }
+    if len(report.Payouts) != 1 || report.Payouts[0].ID != "p2" {
+        t.Errorf("payouts %+v, want only p2", report.Payouts)
+    }
+
+    // Anonymous contributions are never merged or named; everyone else is
+    // reduced to initials and grouped by name.
+    wantTop := []reportContributor{
+        {Rank: 1, Alias: "Anonymous", Count: 1, Total: 200},
+        {Rank: 2, Alias: "A.L.", Count: 2, Total: 75.5},
+        {Rank: 3, Alias: "C.", Count: 1, Total: 30},
+        {Rank: 4, Alias: "Anonymous", Count: 1, Total: 10},
+    }
+    if !reflect.DeepEqual(report.TopContributors, wantTop) {
+        t.Errorf("top contributors %+v, want %+v", report.TopContributors, wantTop)
+    }
+    data, err := json.Marshal(report)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, name := range []string{"Ada", "Lovelace", "Alan", "Turing", "Grace", "Carol"} {
+        if strings.Contains(string(data), name) {
+            t.Errorf("report names %q: %s", name, data)
+        }
+    }
+}
+
+func TestBuildReportRanksTopContributors(t *testing.T) {
+    start := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+    at := start.Add(time.Hour)
+    var contributions []contribution
+    for i, name := range []string{"Zoe", "Amy", "Bea", "Cy", "Di", "Ed", "Flo"} {
+        amount := float64(10 * (i + 1))
+        if name == "Zoe" {
+            amount = 20 // ties with Amy
+        }
+        contributions = append(contributions, contribution{Name: name, Amount: amount, Status: payments.StatusConfirmed, ConfirmedAt: &at})
+    }
+
+    report := buildReport(periodMonthly, start, start.AddDate(0, 1, 0), contributions, nil)
+    var got []string
+    for _, c := range report.TopContributors {
+        got = append(got, c.Alias)
+    }
+    if want := []string{"F.", "E.", "D.", "C.", "B."}; !reflect.DeepEqual(got, want) {
+        t.Fatalf("top contributors %v, want the %d largest: %v", got, topContributorCount, want)
+    }
+
+    contributions = contributions[:2]
+    report = buildReport(periodMonthly, start, start.AddDate(0, 1, 0), contributions, nil)
+    if len(report.TopContributors) != 2 || report.TopContributors[0].Alias != "A." || report.TopContributors[1].Alias != "Z." {
+        t.Fatalf("tied contributors %+v, want them ordered by alias", report.TopContributors)
+    }
+}
+
+func TestPublishedReportsVerify(t *testing.T) {
+    app, mux, fakeURL := newTestAPI(t)
+    confirmedContribution(t, app, mux, fakeURL, 12.34)
+
+    var published publishedReport
+    rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/admin/reports?token="+testAdminToken, map[string]string{"period": periodMonthly}, &published)
+    if rec.Code != http.StatusCreated {
+        t.Fatalf("publish returned %d: %s", rec.Code, rec.Body.String())
+    }
+    var listing struct {
+        PublicKey string `json:"publicKey"`
+    }
+    do(t, mux, http.MethodGet, "/api/v1/signal-bank/reports", nil, &listing)
+    raw, err := base64.StdEncoding.DecodeString(listing.PublicKey)
+    if err != nil || len(raw) != ed25519.PublicKeySize {
+        t.Fatalf("public key %q is not a base64 Ed25519 key", listing.PublicKey)
+    }
+    publicKey := ed25519.PublicKey(raw)
+
+    for _, ext := range []string{"json", "csv", "html"} {
+        name := strings.TrimPrefix(published.Files[ext], "/reports/")
+        data, err := os.ReadFile(filepath.Join(app.reports.dir, name))
+        if err != nil {
+            t.Fatal(err)
+        }
+        sigText, err := os.ReadFile(filepath.Join(app.reports.dir, name+".sig"))
+        if err != nil {
+            t.Fatal(err)
+        }
+        sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigText)))
+        if err != nil {
+            t.Fatalf("%s.sig is not base64: %v", name, err)
+        }
+        if !ed25519.Verify(publicKey, data, sig) {
+            t.Errorf("%s does not verify against the published key", name)
+        }
+        tampered := bytes.Replace(data, []byte("12.34"), []byte("12.35"), 1)
+        if bytes.Equal(tampered, data) {
+            t.Fatalf("%s does not show the contribution", name)
+        }
+        if ed25519.Verify(publicKey, tampered, sig) {
+            t.Errorf("%s still verifies after the amount is changed", name)
+        }
+
+        if ext == "json" {
+            var report transparencyReport
+            if err := json.Unmarshal(data, &report); err != nil {
+                t.Fatal(err)
+            }
+            if report.PublicKey != listing.PublicKey || report.ContributionTotal != 12.34 {
+                t.Errorf("report carries key %q and total %v, want %q and 12.34", report.PublicKey, report.ContributionTotal, listing.PublicKey)
+            }
+        }
+    }
+}
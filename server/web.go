@@ -0,0 +1,30 @@
+package main
+
+import (
+    "embed"
+    "io/fs"
+    "net/http"
+    "os"
+
+    "github.com/anthonyjioe901-coder/DigitalOracle/platform/assets"
+)
+
+// embeddedWeb is the website compiled into the binary, so the server runs
+// the same from any working directory.
+//
+//go:embed web
+var embeddedWeb embed.FS
+
+// webHandler serves the embedded website, or dir when it is set. A
+// directory is re-read as files change, for working on the frontend
+// without rebuilding.
+func webHandler(dir string) (http.Handler, error) {
+    if dir != "" {
+        return assets.New(os.DirFS(dir), assets.Options{Dev: true})
+    }
+    site, err := fs.Sub(embeddedWeb, "web")
+    if err != nil {
+        return nil, err
+    }
+    return assets.New(site, assets.Options{})
+}
@@ -2,6 +2,17 @@ const formEl = document.getElementById("admin-form");
 const statusEl = document.getElementById("admin-status");
 const resultsEl = document.getElementById("results");
 
+// errorMessage reads the API's {"error": {...}} envelope, falling back to
+// the given text when the body is not one.
+async function errorMessage(response, fallback) {
+    try {
+        const body = await response.json();
+        return body?.error?.message || fallback;
+    } catch {
+        return fallback;
+    }
+}
+
 function formatDate(isoString) {
     const date = new Date(isoString);
     if (Number.isNaN(date.getTime())) {
@@ -79,10 +90,9 @@ formEl.addEventListener("submit", async (event) => {
     }
 
     try {
-        const response = await fetch(`/api/auditions?${params.toString()}`);
+        const response = await fetch(`/api/v1/auditions?${params.toString()}`);
         if (!response.ok) {
-            const text = await response.text();
-            throw new Error(text || `Request failed with ${response.status}`);
+            throw new Error(await errorMessage(response, `Request failed with ${response.status}`));
         }
         const data = await response.json();
         renderSubmissions(data);
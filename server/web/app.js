@@ -1,6 +1,17 @@
 const form = document.getElementById("audition-form");
 const statusBox = document.getElementById("status");
 
+// errorMessage reads the API's {"error": {...}} envelope, falling back to
+// the given text when the body is not one.
+async function errorMessage(response, fallback) {
+    try {
+        const body = await response.json();
+        return body?.error?.message || fallback;
+    } catch {
+        return fallback;
+    }
+}
+
 function setStatus(message, type) {
     statusBox.textContent = message;
     statusBox.className = type || "";
@@ -14,7 +25,7 @@ form.addEventListener("submit", async (event) => {
     const payload = Object.fromEntries(formData.entries());
 
     try {
-        const response = await fetch("/api/auditions", {
+        const response = await fetch("/api/v1/auditions", {
             method: "POST",
             headers: {
                 "Content-Type": "application/json"
@@ -23,8 +34,7 @@ form.addEventListener("submit", async (event) => {
         });
 
         if (!response.ok) {
-            const text = await response.text();
-            throw new Error(text || "Submission failed");
+            throw new Error(await errorMessage(response, "Submission failed"));
         }
 
         const data = await response.json();
@@ -3,6 +3,21 @@ const statusEl = document.getElementById("contribution-status");
 const ledgerEl = document.getElementById("ledger");
 const totalDisplay = document.getElementById("total-display");
 const refreshBtn = document.getElementById("refresh-ledger");
+const campaignsEl = document.getElementById("campaigns");
+const campaignSelect = document.getElementById("campaign-select");
+const refundForm = document.getElementById("refund-form");
+const refundStatusEl = document.getElementById("refund-status");
+
+// errorMessage reads the API's {"error": {...}} envelope, falling back to
+// the given text when the body is not one.
+async function errorMessage(response, fallback) {
+    try {
+        const body = await response.json();
+        return body?.error?.message || fallback;
+    } catch {
+        return fallback;
+    }
+}
 
 function setStatus(message, className = "") {
     statusEl.textContent = message;
@@ -58,6 +73,9 @@ function renderLedger(entries) {
         const timestamp = document.createElement("div");
         timestamp.className = "meta";
         timestamp.textContent = `Logged: ${formatDate(entry.createdAt)}`;
+        if (entry.reservedUntil && new Date(entry.reservedUntil) > new Date()) {
+            timestamp.textContent += ` · Reserved until ${formatDate(entry.reservedUntil)}`;
+        }
         container.appendChild(timestamp);
 
         if (entry.message) {
@@ -73,11 +91,79 @@ function renderLedger(entries) {
     totalDisplay.textContent = `Total: ${formatCurrency(total)}`;
 }
 
+function renderCampaigns(campaigns) {
+    campaignsEl.innerHTML = "";
+    const selected = campaignSelect.value;
+    campaignSelect.innerHTML = '<option value="">General pool</option>';
+
+    campaigns.forEach((campaign) => {
+        if (campaign.state === "open" || (campaign.state === "funded" && campaign.overfunding === "rollover")) {
+            const option = document.createElement("option");
+            option.value = campaign.id;
+            option.textContent = campaign.title;
+            campaignSelect.appendChild(option);
+        }
+
+        if (campaign.state === "cancelled") {
+            return;
+        }
+
+        const container = document.createElement("article");
+        container.className = "campaign";
+
+        const header = document.createElement("div");
+        header.className = "entry-header";
+        const title = document.createElement("strong");
+        title.textContent = campaign.title;
+        header.appendChild(title);
+        const goal = document.createElement("span");
+        goal.textContent = `${formatCurrency(campaign.raised)} of ${formatCurrency(campaign.goal)}`;
+        header.appendChild(goal);
+        container.appendChild(header);
+
+        const bar = document.createElement("div");
+        bar.className = "progress";
+        const fill = document.createElement("div");
+        fill.className = "progress-fill";
+        fill.style.width = `${campaign.percent}%`;
+        bar.appendChild(fill);
+        container.appendChild(bar);
+
+        const meta = document.createElement("div");
+        meta.className = "meta";
+        const parts = [`For ${campaign.beneficiary}`, `${campaign.contributors} contributor(s)`];
+        if (campaign.deadline && !campaign.deadline.startsWith("0001")) {
+            parts.push(`Ends ${formatDate(campaign.deadline)}`);
+        }
+        if (campaign.state !== "open") {
+            parts.push(campaign.state);
+        }
+        meta.textContent = parts.join(" · ");
+        container.appendChild(meta);
+
+        campaignsEl.appendChild(container);
+    });
+
+    campaignSelect.value = selected;
+}
+
+async function loadCampaigns() {
+    try {
+        const response = await fetch("/api/v1/signal-bank/campaigns");
+        if (!response.ok) {
+            throw new Error(`Failed to load campaigns (${response.status})`);
+        }
+        renderCampaigns(await response.json());
+    } catch (error) {
+        console.error(error);
+    }
+}
+
 async function loadLedger() {
     try {
-        const response = await fetch("/api/signal-bank/contributions");
+        const response = await fetch("/api/v1/signal-bank/contributions");
         if (!response.ok) {
-            throw new Error(`Failed to load ledger (${response.status})`);
+            throw new Error(await errorMessage(response, `Failed to load ledger (${response.status})`));
         }
         const data = await response.json();
         renderLedger(data);
@@ -101,24 +187,30 @@ form.addEventListener("submit", async (event) => {
 
     const payload = {
         name: formData.get("name")?.trim() || "",
+        visibility: formData.get("visibility") || "public",
         amount,
         message: formData.get("message")?.trim() || "",
+        campaignId: formData.get("campaignId") || "",
     };
 
     try {
-        const response = await fetch("/api/signal-bank/contributions", {
+        const response = await fetch("/api/v1/signal-bank/contributions", {
             method: "POST",
             headers: { "Content-Type": "application/json" },
             body: JSON.stringify(payload),
         });
 
         if (!response.ok) {
-            const text = await response.text();
-            throw new Error(text || "Failed to record contribution");
+            throw new Error(await errorMessage(response, "Failed to record contribution"));
         }
 
+        const created = await response.json();
         form.reset();
-        setStatus("Contribution logged. Thank you!", "success");
+        setStatus(
+            "Thank you! Your contribution will appear in the ledger once the payment is confirmed. " +
+                `Keep this claim token if you may want a refund within ${created.refundWindow}: ${created.claimToken}`,
+            "success"
+        );
         await loadLedger();
     } catch (error) {
         console.error(error);
@@ -126,9 +218,44 @@ form.addEventListener("submit", async (event) => {
     }
 });
 
+refundForm.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    refundStatusEl.textContent = "Submitting refund request...";
+    refundStatusEl.className = "";
+
+    const formData = new FormData(refundForm);
+    const payload = {
+        claimToken: formData.get("claimToken")?.trim() || "",
+        reason: formData.get("reason")?.trim() || "",
+    };
+
+    try {
+        const response = await fetch("/api/v1/signal-bank/refund-requests", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify(payload),
+        });
+
+        if (!response.ok) {
+            throw new Error(await errorMessage(response, "Failed to request refund"));
+        }
+
+        const result = await response.json();
+        refundForm.reset();
+        refundStatusEl.textContent = result.message;
+        refundStatusEl.className = "success";
+    } catch (error) {
+        console.error(error);
+        refundStatusEl.textContent = error.message || "Failed to request refund.";
+        refundStatusEl.className = "error";
+    }
+});
+
 refreshBtn.addEventListener("click", () => {
     setStatus("Ledger refreshed.", "success");
     loadLedger();
+    loadCampaigns();
 });
 
 loadLedger();
+loadCampaigns();
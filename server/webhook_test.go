@@ -0,0 +1,138 @@
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "digital-oracle-server/payments"
+)
+
+func TestValidPaymentTransition(t *testing.T) {
+    statuses := []payments.Status{payments.StatusPending, payments.StatusConfirmed, payments.StatusFailed, payments.StatusRefunded}
+    allowed := map[[2]payments.Status]bool{
+        {payments.StatusPending, payments.StatusConfirmed}:  true,
+        {payments.StatusPending, payments.StatusFailed}:     true,
+        {payments.StatusFailed, payments.StatusConfirmed}:   true,
+        {payments.StatusConfirmed, payments.StatusRefunded}: true,
+    }
+    for _, from := range statuses {
+        for _, to := range statuses {
+            want := allowed[[2]payments.Status{from, to}]
+            if got := validPaymentTransition(from, to); got != want {
+                t.Errorf("validPaymentTransition(%s, %s) = %v, want %v", from, to, got, want)
+            }
+        }
+    }
+}
+
+// newPendingContribution posts a contribution and returns it with its
+// claim token.
+func newPendingContribution(t *testing.T, mux http.Handler, amount float64) (contribution, string) {
+    t.Helper()
+    var created struct {
+        contribution
+        ClaimToken string `json:"claimToken"`
+    }
+    rec := do(t, mux, http.MethodPost, "/api/v1/signal-bank/contributions", map[string]interface{}{"name": "Ada", "amount": amount}, &created)
+    if rec.Code != http.StatusCreated {
+        t.Fatalf("create contribution returned %d: %s", rec.Code, rec.Body.String())
+    }
+    return created.contribution, created.ClaimToken
+}
+
+// webhook delivers a signed event about intentID to the API.
+func webhook(t *testing.T, mux http.Handler, eventID, eventType, intentID string, signedAt time.Time) *httptest.ResponseRecorder {
+    t.Helper()
+    payload := []byte(fmt.Sprintf(`{"id":%q,"type":%q,"data":{"object":{"id":%q,"object":"payment_intent"}}}`, eventID, eventType, intentID))
+    return sendSigned(t, mux, payload, payments.Sign(payload, testWebhookSecret, signedAt))
+}
+
+// sendSigned posts payload to the webhook with the given signature header.
+func sendSigned(t *testing.T, mux http.Handler, payload []byte, signature string) *httptest.ResponseRecorder {
+    t.Helper()
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/signal-bank/webhook", bytes.NewReader(payload))
+    if signature != "" {
+        req.Header.Set(payments.SignatureHeader, signature)
+    }
+    rec := httptest.NewRecorder()
+    mux.ServeHTTP(rec, req)
+    return rec
+}
+
+func TestPaymentWebhookConfirmsOnce(t *testing.T) {
+    app, mux, _ := newTestAPI(t)
+    entry, _ := newPendingContribution(t, mux, 20)
+
+    if rec := webhook(t, mux, "evt_1", "payment_intent.succeeded", entry.PaymentID, time.Now()); rec.Code != http.StatusOK {
+        t.Fatalf("webhook returned %d: %s", rec.Code, rec.Body.String())
+    }
+    confirmed := app.bank.listByStatus(payments.StatusConfirmed)
+    if len(confirmed) != 1 || confirmed[0].ConfirmedAt == nil {
+        t.Fatalf("after webhook: %+v, want one confirmed contribution", confirmed)
+    }
+    first := *confirmed[0].ConfirmedAt
+
+    // Providers deliver at least once: a duplicate, or a late failure for
+    // the same intent, must not move the entry.
+    for _, event := range []struct{ id, typ string }{
+        {"evt_1", "payment_intent.succeeded"},
+        {"evt_2", "payment_intent.payment_failed"},
+    } {
+        if rec := webhook(t, mux, event.id, event.typ, entry.PaymentID, time.Now()); rec.Code != http.StatusOK {
+            t.Fatalf("replayed %s returned %d, want 200 so the provider stops retrying", event.typ, rec.Code)
+        }
+    }
+    confirmed = app.bank.listByStatus(payments.StatusConfirmed)
+    if len(confirmed) != 1 || !confirmed[0].ConfirmedAt.Equal(first) {
+        t.Fatalf("after replays: %+v, want the original confirmation kept", confirmed)
+    }
+}
+
+func TestPaymentWebhookRetryAfterDecline(t *testing.T) {
+    app, mux, _ := newTestAPI(t)
+    entry, _ := newPendingContribution(t, mux, 15)
+
+    webhook(t, mux, "evt_1", "payment_intent.payment_failed", entry.PaymentID, time.Now())
+    if got := app.bank.listByStatus(payments.StatusFailed); len(got) != 1 {
+        t.Fatalf("after decline: %d failed, want 1", len(got))
+    }
+    webhook(t, mux, "evt_2", "payment_intent.succeeded", entry.PaymentID, time.Now())
+    if got := app.bank.listByStatus(payments.StatusConfirmed); len(got) != 1 {
+        t.Fatalf("after retry: %d confirmed, want 1", len(got))
+    }
+}
+
+func TestPaymentWebhookRejectsBadSignatures(t *testing.T) {
+    app, mux, _ := newTestAPI(t)
+    entry, _ := newPendingContribution(t, mux, 10)
+    payload := []byte(fmt.Sprintf(`{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":%q}}}`, entry.PaymentID))
+
+    tests := []struct {
+        name   string
+        header string
+    }{
+        {"wrong secret", payments.Sign(payload, "whsec_other", time.Now())},
+        {"replayed after the tolerance", payments.Sign(payload, testWebhookSecret, time.Now().Add(-10*time.Minute))},
+        {"missing", ""},
+    }
+    for _, tt := range tests {
+        rec := sendSigned(t, mux, payload, tt.header)
+        if rec.Code != http.StatusBadRequest || errorCode(t, rec) != "invalid_signature" {
+            t.Errorf("%s: got %d %s, want 400 invalid_signature", tt.name, rec.Code, rec.Body.String())
+        }
+    }
+    if got := app.bank.listByStatus(payments.StatusConfirmed); len(got) != 0 {
+        t.Fatalf("unsigned webhooks confirmed %d contributions", len(got))
+    }
+}
+
+func TestPaymentWebhookIgnoresUnknownPayments(t *testing.T) {
+    _, mux, _ := newTestAPI(t)
+    if rec := webhook(t, mux, "evt_1", "payment_intent.succeeded", "pi_elsewhere", time.Now()); rec.Code != http.StatusOK {
+        t.Fatalf("webhook for an unknown intent returned %d, want 200", rec.Code)
+    }
+}
@@ -1,3 +1,7 @@
 module sociovault
 
 go 1.22
+
+require github.com/anthonyjioe901-coder/DigitalOracle v0.0.0
+
+replace github.com/anthonyjioe901-coder/DigitalOracle => ../
@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
+	"embed"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"sync"
 	"time"
+
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/assets"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/cors"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/logging"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/router"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/serve"
 )
 
 // Contributor represents a SocioVault contributor
@@ -48,13 +58,14 @@ var (
 	requests     []Request
 	contributorMu sync.Mutex
 	requestMu    sync.Mutex
+	subscriberMu sync.Mutex
 	dataDir      = "./data"
 )
 
 func init() {
 	// Create data directory if it doesn't exist
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		log.Printf("Failed to create data directory: %v", err)
+		slog.Error("failed to create data directory", "dir", dataDir, "err", err)
 	}
 
 	// Load existing data
@@ -83,12 +94,33 @@ func loadRequests() {
 
 func saveContributors() {
 	data, _ := json.MarshalIndent(contributors, "", "  ")
-	ioutil.WriteFile(fmt.Sprintf("%s/contributors.json", dataDir), data, 0644)
+	if err := writeStoreFile("contributors", fmt.Sprintf("%s/contributors.json", dataDir), data); err != nil {
+		slog.Error("failed to save contributors", "err", err)
+	}
 }
 
 func saveRequests() {
 	data, _ := json.MarshalIndent(requests, "", "  ")
-	ioutil.WriteFile(fmt.Sprintf("%s/requests.json", dataDir), data, 0644)
+	if err := writeStoreFile("requests", fmt.Sprintf("%s/requests.json", dataDir), data); err != nil {
+		slog.Error("failed to save requests", "err", err)
+	}
+}
+
+// flushStores rewrites the data files from memory once requests have
+// drained at shutdown.
+func flushStores(ctx context.Context) error {
+	contributorMu.Lock()
+	saveContributors()
+	contributorMu.Unlock()
+
+	requestMu.Lock()
+	saveRequests()
+	requestMu.Unlock()
+
+	// Wait for a subscription being written.
+	subscriberMu.Lock()
+	subscriberMu.Unlock()
+	return nil
 }
 
 func generateID() string {
@@ -97,92 +129,67 @@ func generateID() string {
 
 // API Handlers
 
-func handleContribute(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// apiHeaders marks every API response as JSON.
+func apiHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(w, r)
+	})
+}
 
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusOK)
+func handleCreateContribution(w http.ResponseWriter, r *http.Request) {
+	var contrib Contributor
+	if !decodeRequest(w, r, contributeBody, &contrib) {
 		return
 	}
 
-	if r.Method == http.MethodPost {
-		var contrib Contributor
-		if err := json.NewDecoder(r.Body).Decode(&contrib); err != nil {
-			http.Error(w, "Invalid request", http.StatusBadRequest)
-			return
-		}
-
-		contrib.ID = generateID()
-		contrib.CreatedAt = time.Now()
-
-		contributorMu.Lock()
-		contributors = append(contributors, contrib)
-		saveContributors()
-		contributorMu.Unlock()
+	contrib.ID = generateID()
+	contrib.CreatedAt = time.Now()
 
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(contrib)
-		return
-	}
+	contributorMu.Lock()
+	contributors = append(contributors, contrib)
+	saveContributors()
+	contributorMu.Unlock()
 
-	if r.Method == http.MethodGet {
-		contributorMu.Lock()
-		defer contributorMu.Unlock()
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(contributors)
-		return
-	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(contrib)
 }
 
-func handleRequest(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+func handleListContributions(w http.ResponseWriter, r *http.Request) {
+	contributorMu.Lock()
+	defer contributorMu.Unlock()
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(contributors)
+}
 
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusOK)
+func handleCreateRequest(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if !decodeRequest(w, r, requestBody, &req) {
 		return
 	}
 
-	if r.Method == http.MethodPost {
-		var req Request
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request", http.StatusBadRequest)
-			return
-		}
-
-		req.ID = generateID()
-		req.CreatedAt = time.Now()
-		req.Verified = false
-		req.Votes = 0
+	req.ID = generateID()
+	req.CreatedAt = time.Now()
+	req.Verified = false
+	req.Votes = 0
 
-		requestMu.Lock()
-		requests = append(requests, req)
-		saveRequests()
-		requestMu.Unlock()
+	requestMu.Lock()
+	requests = append(requests, req)
+	saveRequests()
+	requestMu.Unlock()
 
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(req)
-		return
-	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(req)
+}
 
-	if r.Method == http.MethodGet {
-		requestMu.Lock()
-		defer requestMu.Unlock()
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(requests)
-		return
-	}
+func handleListRequests(w http.ResponseWriter, r *http.Request) {
+	requestMu.Lock()
+	defer requestMu.Unlock()
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(requests)
 }
 
 func handleStats(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
 	contributorMu.Lock()
 	totalContributors := len(contributors)
 	var totalBalance float64
@@ -218,27 +225,11 @@ func handleStats(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleVote(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var voteData struct {
 		RequestID string `json:"requestId"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&voteData); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	if !decodeRequest(w, r, voteBody, &voteData) {
 		return
 	}
 
@@ -259,31 +250,18 @@ func handleVote(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleSubscribe(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var subData struct {
 		Email string `json:"email"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&subData); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	if !decodeRequest(w, r, subscribeBody, &subData) {
 		return
 	}
 
 	// Save subscription
+	subscriberMu.Lock()
+	defer subscriberMu.Unlock()
+
 	subs, _ := ioutil.ReadFile(fmt.Sprintf("%s/subscribers.json", dataDir))
 	var subscribers []map[string]interface{}
 	json.Unmarshal(subs, &subscribers)
@@ -294,7 +272,11 @@ func handleSubscribe(w http.ResponseWriter, r *http.Request) {
 	})
 
 	data, _ := json.MarshalIndent(subscribers, "", "  ")
-	ioutil.WriteFile(fmt.Sprintf("%s/subscribers.json", dataDir), data, 0644)
+	if err := writeStoreFile("subscribers", fmt.Sprintf("%s/subscribers.json", dataDir), data); err != nil {
+		logging.FromContext(r.Context()).Error("failed to save subscribers", "err", err)
+		http.Error(w, "Failed to save subscription", http.StatusInternalServerError)
+		return
+	}
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{
@@ -303,22 +285,72 @@ func handleSubscribe(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+var (
+	legacyAPIDeprecated = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	legacyAPISunset     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+func routesV1(g *router.Group) {
+	g = g.With(apiHeaders)
+	g.Handle("GET /openapi.json", apiDocument().Handler())
+	g.HandleFunc("GET /contribute", handleListContributions)
+	g.HandleFunc("POST /contribute", handleCreateContribution)
+	g.HandleFunc("GET /requests", handleListRequests)
+	g.HandleFunc("POST /requests", handleCreateRequest)
+	g.HandleFunc("GET /stats", handleStats)
+	g.HandleFunc("POST /vote", handleVote)
+	g.HandleFunc("POST /subscribe", handleSubscribe)
+}
+
+// site is the landing page, built into the binary so it can run from any
+// directory without exposing the Go sources next to it.
+//
+//go:embed index.html script.js styles.css
+var site embed.FS
+
 func main() {
-	// API Routes
-	http.HandleFunc("/api/contribute", handleContribute)
-	http.HandleFunc("/api/requests", handleRequest)
-	http.HandleFunc("/api/stats", handleStats)
-	http.HandleFunc("/api/vote", handleVote)
-	http.HandleFunc("/api/subscribe", handleSubscribe)
+	staticDir := flag.String("static-dir", os.Getenv("STATIC_DIR"), "serve the site from this directory instead of the embedded copy")
+	flag.Parse()
 
-	// Static files
-	http.Handle("/", http.FileServer(http.Dir(".")))
+	if _, err := logging.Setup(logging.OptionsFromEnv("sociovault")); err != nil {
+		log.Fatal(err)
+	}
+
+	// API Routes: /api/v1, with the original /api paths kept as
+	// deprecated aliases for pages cached before the move.
+	router.Mount(http.DefaultServeMux, "/api/v1", routesV1, router.Options{
+		Aliases: []router.Alias{{Prefix: "/api", Deprecated: legacyAPIDeprecated, Sunset: legacyAPISunset}},
+	})
+	http.Handle("/metrics", registry.Handler())
+
+	// Static files: embedded, or re-read from disk while editing them
+	static, err := assets.New(site, assets.Options{})
+	if *staticDir != "" {
+		static, err = assets.New(os.DirFS(*staticDir), assets.Options{Dev: true})
+	}
+	if err != nil {
+		logging.Fatal("failed to load static files", "err", err)
+	}
+	http.Handle("/", static)
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8081"
 	}
 
-	fmt.Printf("SocioVault server listening on :%s\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	// Cross-origin callers must be listed; the page itself is same-origin.
+	policy := cors.New(cors.Options{
+		AllowedOrigins:   cors.ParseOrigins(os.Getenv("CORS_ORIGINS")),
+		AllowCredentials: os.Getenv("CORS_ALLOW_CREDENTIALS") == "true",
+	})
+
+	err = serve.Run(serve.Options{
+		Name:    "SocioVault server",
+		Addr:    ":" + port,
+		Handler: logging.Middleware(httpMetrics.Instrument(http.DefaultServeMux, policy.Middleware(http.DefaultServeMux))),
+		Cleanup: flushStores,
+	})
+	if err != nil {
+		logging.Fatal("server exited", "err", err)
+	}
 }
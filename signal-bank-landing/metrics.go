@@ -0,0 +1,52 @@
+package main
+
+import (
+	"time"
+
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/atomicfile"
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/metrics"
+)
+
+var (
+	registry    = metrics.NewRegistry()
+	httpMetrics = registry.NewHTTPMetrics()
+
+	storeWriteSeconds = registry.NewHistogram("sociovault_store_write_duration_seconds", "Time taken to persist a data file, by store.", nil, "store")
+)
+
+func init() {
+	registry.NewGaugeFunc("sociovault_contributions_amount", "Total amount contributed.", nil, func(emit func(float64, ...string)) {
+		contributorMu.Lock()
+		defer contributorMu.Unlock()
+		var total float64
+		for _, c := range contributors {
+			total += c.Amount
+		}
+		emit(total)
+	})
+	registry.NewGaugeFunc("sociovault_contributors", "Contributions recorded.", nil, func(emit func(float64, ...string)) {
+		contributorMu.Lock()
+		defer contributorMu.Unlock()
+		emit(float64(len(contributors)))
+	})
+	registry.NewGaugeFunc("sociovault_requests", "Help requests by verification state.", []string{"verified"}, func(emit func(float64, ...string)) {
+		requestMu.Lock()
+		defer requestMu.Unlock()
+		verified := 0
+		for _, req := range requests {
+			if req.Verified {
+				verified++
+			}
+		}
+		emit(float64(verified), "true")
+		emit(float64(len(requests)-verified), "false")
+	})
+}
+
+// writeStoreFile persists a data file and records how long it took.
+func writeStoreFile(store, path string, data []byte) error {
+	start := time.Now()
+	err := atomicfile.WriteFile(path, data, 0644)
+	storeWriteSeconds.Observe(metrics.Since(start), store)
+	return err
+}
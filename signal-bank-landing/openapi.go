@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/openapi"
+)
+
+// Request bodies, shared by the handlers and /api/v1/openapi.json.
+var (
+	contributeBody = openapi.Object(map[string]*openapi.Schema{
+		"email":   openapi.String().Len(1, 254).WithFormat(openapi.FormatEmail),
+		"amount":  openapi.Number().Above(0).AtMost(1_000_000),
+		"message": openapi.String().Len(0, 500),
+	}, "email", "amount")
+
+	requestBody = openapi.Object(map[string]*openapi.Schema{
+		"name":     openapi.String().Len(1, 120),
+		"email":    openapi.String().Len(1, 254).WithFormat(openapi.FormatEmail),
+		"story":    openapi.String().Len(1, 5000),
+		"videoUrl": openapi.String().Len(0, 500).WithFormat(openapi.FormatURI),
+		"amount":   openapi.Number().AtLeast(0).AtMost(1_000_000).Describe("Amount needed; 0 when unsure."),
+	}, "name", "email", "story")
+
+	voteBody = openapi.Object(map[string]*openapi.Schema{
+		"requestId": openapi.String().Len(1, 100),
+	}, "requestId")
+
+	subscribeBody = openapi.Object(map[string]*openapi.Schema{
+		"email": openapi.String().Len(1, 254).WithFormat(openapi.FormatEmail),
+	}, "email")
+)
+
+// decodeRequest reads a JSON body into dst if it matches schema. On
+// failure it writes the 400 itself, listing each bad field so the form can
+// point at it.
+func decodeRequest(w http.ResponseWriter, r *http.Request, schema *openapi.Schema, dst interface{}) bool {
+	fields, err := openapi.Decode(r.Body, schema, dst)
+	if errors.Is(err, openapi.ErrBodyTooLarge) {
+		http.Error(w, "Request too large", http.StatusRequestEntityTooLarge)
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return false
+	}
+	if len(fields) > 0 {
+		type fieldError struct {
+			Field   string `json:"field"`
+			Message string `json:"message"`
+		}
+		body := struct {
+			Error  string       `json:"error"`
+			Fields []fieldError `json:"fields"`
+		}{Error: "Invalid request"}
+		for _, f := range fields {
+			body.Fields = append(body.Fields, fieldError{f.Field, f.Message})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(body)
+		return false
+	}
+	return true
+}
+
+func apiDocument() *openapi.Document {
+	doc := openapi.New("SocioVault API", "1.0.0",
+		"Contributions, help requests and community votes. String fields are trimmed before validation.")
+	doc.Servers = []openapi.Server{{URL: "/api/v1"}}
+
+	timestamp := openapi.String().WithFormat(openapi.FormatDateTime)
+
+	contributorRef := doc.Schema("Contributor", openapi.Object(map[string]*openapi.Schema{
+		"id":        openapi.String(),
+		"email":     openapi.String(),
+		"amount":    openapi.Number(),
+		"message":   openapi.String(),
+		"createdAt": timestamp,
+	}))
+	requestRef := doc.Schema("Request", openapi.Object(map[string]*openapi.Schema{
+		"id":        openapi.String(),
+		"name":      openapi.String(),
+		"email":     openapi.String(),
+		"story":     openapi.String(),
+		"videoUrl":  openapi.String(),
+		"amount":    openapi.Number(),
+		"verified":  openapi.Boolean(),
+		"votes":     openapi.Integer(),
+		"createdAt": timestamp,
+	}))
+	statsRef := doc.Schema("Stats", openapi.Object(map[string]*openapi.Schema{
+		"totalBalance":       openapi.Number(),
+		"distributedPercent": openapi.Integer(),
+		"storiesFunded":      openapi.Integer(),
+		"totalContributors":  openapi.Integer(),
+		"activeRequests":     openapi.Integer(),
+		"dailyContributions": openapi.Number(),
+	}))
+	invalid := openapi.JSON("The body was not JSON or failed validation.", doc.Schema("ValidationError", openapi.Object(map[string]*openapi.Schema{
+		"error": openapi.String(),
+		"fields": openapi.Array(openapi.Object(map[string]*openapi.Schema{
+			"field":   openapi.String(),
+			"message": openapi.String(),
+		})),
+	})))
+
+	doc.Add(http.MethodGet, "/contribute", &openapi.Operation{
+		Summary:   "List contributions",
+		Responses: map[string]openapi.Response{"200": openapi.JSON("All contributions.", openapi.Array(contributorRef))},
+	})
+	doc.Add(http.MethodPost, "/contribute", &openapi.Operation{
+		Summary:     "Record a contribution",
+		RequestBody: openapi.JSONBody(contributeBody),
+		Responses: map[string]openapi.Response{
+			"201": openapi.JSON("Contribution recorded.", contributorRef),
+			"400": invalid,
+		},
+	})
+	doc.Add(http.MethodGet, "/requests", &openapi.Operation{
+		Summary:   "List help requests",
+		Responses: map[string]openapi.Response{"200": openapi.JSON("All help requests.", openapi.Array(requestRef))},
+	})
+	doc.Add(http.MethodPost, "/requests", &openapi.Operation{
+		Summary:     "Submit a help request",
+		RequestBody: openapi.JSONBody(requestBody),
+		Responses: map[string]openapi.Response{
+			"201": openapi.JSON("Request recorded, unverified.", requestRef),
+			"400": invalid,
+		},
+	})
+	doc.Add(http.MethodGet, "/stats", &openapi.Operation{
+		Summary:   "Headline statistics",
+		Responses: map[string]openapi.Response{"200": openapi.JSON("Current statistics.", statsRef)},
+	})
+	doc.Add(http.MethodPost, "/vote", &openapi.Operation{
+		Summary:     "Vote for a help request",
+		RequestBody: openapi.JSONBody(voteBody),
+		Responses: map[string]openapi.Response{
+			"200": openapi.JSON("Vote counted.", requestRef),
+			"400": invalid,
+			"404": openapi.Status("No request with that ID."),
+		},
+	})
+	doc.Add(http.MethodPost, "/subscribe", &openapi.Operation{
+		Summary:     "Subscribe to updates",
+		RequestBody: openapi.JSONBody(subscribeBody),
+		Responses: map[string]openapi.Response{
+			"201": openapi.JSON("Subscribed.", openapi.Object(map[string]*openapi.Schema{
+				"status": openapi.String(),
+				"email":  openapi.String(),
+			})),
+			"400": invalid,
+			"500": openapi.Status("The subscription could not be saved."),
+		},
+	})
+
+	return doc
+}
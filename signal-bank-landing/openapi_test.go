@@ -0,0 +1,23 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/anthonyjioe901-coder/DigitalOracle/platform/router"
+)
+
+// TestRoutesMatchDocument keeps the published API description and the
+// mounted routes in step. The document does not describe itself.
+func TestRoutesMatchDocument(t *testing.T) {
+	var mounted []string
+	for _, route := range router.Routes(routesV1) {
+		if route != "GET /openapi.json" {
+			mounted = append(mounted, route)
+		}
+	}
+
+	if documented := apiDocument().Routes(); !reflect.DeepEqual(mounted, documented) {
+		t.Fatalf("mounted routes\n  %v\ndo not match the document\n  %v", mounted, documented)
+	}
+}
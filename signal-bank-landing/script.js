@@ -1,9 +1,9 @@
 // Determine API base URL
 let API_BASE;
 if (window.location.hostname === "localhost" || window.location.hostname === "127.0.0.1") {
-  API_BASE = `http://localhost:${window.location.port || 8081}/api`;
+  API_BASE = `http://localhost:${window.location.port || 8081}/api/v1`;
 } else {
-  API_BASE = `${window.location.origin}/api`;
+  API_BASE = `${window.location.origin}/api/v1`;
 }
 
 console.log("API Base URL:", API_BASE);
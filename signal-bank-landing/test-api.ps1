@@ -1,7 +1,7 @@
 #!/usr/bin/env powershell
 # Test script to verify SocioVault API is working
 
-$API_BASE = "http://localhost:8081/api"
+$API_BASE = "http://localhost:8081/api/v1"
 
 Write-Host "🔍 Testing SocioVault API Endpoints" -ForegroundColor Cyan
 Write-Host "====================================" -ForegroundColor Cyan
@@ -150,4 +150,4 @@ Write-Host "✅ ALL TESTS COMPLETED!" -ForegroundColor Green
 Write-Host "====================================" -ForegroundColor Cyan
 Write-Host ""
 Write-Host "🌐 Access the page at: http://localhost:8081" -ForegroundColor Cyan
-Write-Host "📊 API Base: http://localhost:8081/api" -ForegroundColor Cyan
+Write-Host "📊 API Base: http://localhost:8081/api/v1" -ForegroundColor Cyan